@@ -0,0 +1,120 @@
+package dom
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/typelate/dom/spec"
+)
+
+func (e *Element) InsertAdjacentHTML(position spec.InsertPosition, text string) error {
+	return insertAdjacentHTML(e.node, position, text)
+}
+
+func (e *Element) InsertAdjacentElement(position spec.InsertPosition, element spec.Element) (spec.Element, error) {
+	where, err := adjacentPosition(e.node, position)
+	if err != nil {
+		return nil, err
+	}
+	parent, child := adjacentInsertionPoint(e.node, where)
+	if err := ensurePreInsertValidity(element, parent, child); err != nil {
+		return nil, err
+	}
+	insertAdjacent(e.node, where, convertNodes([]spec.Node{element}))
+	return element, nil
+}
+
+func (e *Element) InsertAdjacentText(position spec.InsertPosition, data string) error {
+	where, err := adjacentPosition(e.node, position)
+	if err != nil {
+		return err
+	}
+	text := &html.Node{Type: html.TextNode, Data: data}
+	setNodeDocument(text, ownerDocumentNode(e.node))
+	parent, child := adjacentInsertionPoint(e.node, where)
+	if err := ensurePreInsertValidity(&Text{node: text}, parent, child); err != nil {
+		return err
+	}
+	insertAdjacent(e.node, where, []*html.Node{text})
+	return nil
+}
+
+// adjacentPosition returns the lowercase position. It returns an error if
+// position is not valid or requires a parent that node does not have.
+func adjacentPosition(node *html.Node, position spec.InsertPosition) (spec.InsertPosition, error) {
+	where := spec.InsertPosition(strings.ToLower(string(position)))
+	switch where {
+	case spec.InsertBeforeBegin, spec.InsertAfterEnd:
+		if node.Parent == nil {
+			return "", fmt.Errorf("%w: %q requires a parent node", spec.ErrNoModificationAllowed, position)
+		}
+	case spec.InsertAfterBegin, spec.InsertBeforeEnd:
+	default:
+		return "", fmt.Errorf("%w: %q is not a valid position", spec.ErrSyntax, position)
+	}
+	return where, nil
+}
+
+// insertAdjacent is based on https://dom.spec.whatwg.org/#insert-adjacent
+// where is a position returned by adjacentPosition.
+func insertAdjacent(node *html.Node, where spec.InsertPosition, nodes []*html.Node) {
+	parent, child := adjacentInsertionPoint(node, where)
+	insertHTMLNodes(parent, child, nodes)
+}
+
+// adjacentInsertionPoint returns the parent and the child to insert before
+// for a position returned by adjacentPosition.
+func adjacentInsertionPoint(node *html.Node, where spec.InsertPosition) (parent, child *html.Node) {
+	switch where {
+	case spec.InsertBeforeBegin:
+		return node.Parent, node
+	case spec.InsertAfterBegin:
+		return node, node.FirstChild
+	case spec.InsertAfterEnd:
+		return node.Parent, node.NextSibling
+	default:
+		return node, nil
+	}
+}
+
+// insertAdjacentHTML is based on https://html.spec.whatwg.org/#dom-element-insertadjacenthtml
+func insertAdjacentHTML(node *html.Node, position spec.InsertPosition, text string) error {
+	where, err := adjacentPosition(node, position)
+	if err != nil {
+		return err
+	}
+	context := node
+	if where == spec.InsertBeforeBegin || where == spec.InsertAfterEnd {
+		context = node.Parent
+		if context.Type == html.DocumentNode {
+			return fmt.Errorf("%w: %q can not insert into a document", spec.ErrNoModificationAllowed, position)
+		}
+	}
+	if context.Namespace == "" && context.DataAtom == atom.Html {
+		context = &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	}
+	nodes, err := parseHTMLFragment(context, text)
+	if err != nil {
+		return err
+	}
+	insertAdjacent(node, where, nodes)
+	return nil
+}
+
+// parseHTMLFragment parses text with context as the context element. It is
+// based on https://html.spec.whatwg.org/#html-fragment-parsing-algorithm
+// A context that is not an element, such as a DocumentFragment, is replaced
+// by a body element.
+func parseHTMLFragment(context *html.Node, text string) ([]*html.Node, error) {
+	if context.Type != html.ElementNode {
+		context = &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(text), context)
+	if err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
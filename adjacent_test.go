@@ -0,0 +1,89 @@
+package dom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/typelate/dom/spec"
+)
+
+func TestElement_InsertAdjacentHTML(t *testing.T) {
+	// language=html
+	const textHTML = `<!DOCTYPE html>
+<html lang="us-en">
+<head><title>Adjacent</title></head>
+<body><table><tbody id="rows"><tr id="target"><td>1</td></tr></tbody></table></body>
+</html>`
+
+	for _, tt := range []struct {
+		Name     string
+		Position spec.InsertPosition
+		Expected string
+	}{
+		{Name: "beforebegin", Position: spec.InsertBeforeBegin, Expected: `<tbody id="rows"><tr><td>new</td></tr><tr id="target"><td>1</td></tr></tbody>`},
+		{Name: "afterbegin", Position: spec.InsertAfterBegin, Expected: `<tbody id="rows"><tr id="target"><td>new</td><td>1</td></tr></tbody>`},
+		{Name: "beforeend", Position: spec.InsertBeforeEnd, Expected: `<tbody id="rows"><tr id="target"><td>1</td><td>new</td></tr></tbody>`},
+		{Name: "afterend", Position: "AfterEnd", Expected: `<tbody id="rows"><tr id="target"><td>1</td></tr><tr><td>new</td></tr></tbody>`},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			document, target := parseDocument(t, textHTML, "#target")
+			require.NoError(t, target.InsertAdjacentHTML(tt.Position, `<tr><td>new</td></tr>`))
+			assert.Equal(t, tt.Expected, document.GetElementByID("rows").OuterHTML())
+			assert.Equal(t, 2, document.GetElementsByTagName("td").Length())
+		})
+	}
+	t.Run("context element", func(t *testing.T) {
+		document, target := parseDocument(t, textHTML, "#target")
+		require.NoError(t, target.InsertAdjacentHTML(spec.InsertBeforeEnd, `<td>2</td>`))
+		require.NoError(t, target.InsertAdjacentHTML(spec.InsertBeforeBegin, `<td>3</td>`))
+		assert.Equal(t, `<td>1</td><td>2</td>`, target.InnerHTML())
+		assert.Equal(t, `<tr><td>3</td></tr><tr id="target"><td>1</td><td>2</td></tr>`, document.GetElementByID("rows").InnerHTML(), "the parent is the context")
+		html := document.DocumentElement()
+		require.NoError(t, html.InsertAdjacentHTML(spec.InsertBeforeEnd, `<p>after</p>`))
+		assert.Equal(t, "P", html.LastElementChild().TagName())
+	})
+	t.Run("errors", func(t *testing.T) {
+		document, target := parseDocument(t, textHTML, "#target")
+		assert.ErrorIs(t, target.InsertAdjacentHTML("middle", "<td></td>"), spec.ErrSyntax)
+		detached := document.CreateElement("div")
+		assert.ErrorIs(t, detached.InsertAdjacentHTML(spec.InsertBeforeBegin, "<p></p>"), spec.ErrNoModificationAllowed)
+		assert.ErrorIs(t, document.DocumentElement().InsertAdjacentHTML(spec.InsertAfterEnd, "<p></p>"), spec.ErrNoModificationAllowed)
+		require.NoError(t, detached.InsertAdjacentHTML(spec.InsertAfterBegin, "<p></p>"))
+		assert.Equal(t, "<div><p></p></div>", detached.OuterHTML())
+	})
+}
+
+func TestElement_InsertAdjacentElement(t *testing.T) {
+	// language=html
+	const textHTML = `<!DOCTYPE html>
+<html lang="us-en">
+<head><title>Adjacent</title></head>
+<body><div id="a"></div><div id="b"><span>b</span></div></body></html>`
+	document, a := parseDocument(t, textHTML, "#a")
+	body := document.Body()
+
+	b := document.GetElementByID("b")
+	inserted, err := a.InsertAdjacentElement(spec.InsertBeforeBegin, b)
+	require.NoError(t, err)
+	assert.True(t, inserted.IsSameNode(b))
+	assert.Equal(t, `<div id="b"><span>b</span></div><div id="a"></div>`, body.InnerHTML())
+
+	_, err = a.InsertAdjacentElement(spec.InsertAfterBegin, b.FirstElementChild())
+	require.NoError(t, err)
+	require.NoError(t, a.InsertAdjacentText(spec.InsertBeforeEnd, "!"))
+	require.NoError(t, a.InsertAdjacentText(spec.InsertAfterEnd, "end"))
+	assert.Equal(t, `<div id="b"></div><div id="a"><span>b</span>!</div>end`, body.InnerHTML())
+
+	t.Run("errors", func(t *testing.T) {
+		detached := document.CreateElement("p")
+		_, err := detached.InsertAdjacentElement(spec.InsertAfterEnd, b)
+		assert.ErrorIs(t, err, spec.ErrNoModificationAllowed)
+		assert.True(t, b.IsConnected(), "the element is not moved")
+		_, err = a.InsertAdjacentElement("", b)
+		assert.ErrorIs(t, err, spec.ErrSyntax)
+		assert.ErrorIs(t, detached.InsertAdjacentText(spec.InsertBeforeBegin, "x"), spec.ErrNoModificationAllowed)
+		assert.ErrorIs(t, detached.InsertAdjacentText("inside", "x"), spec.ErrSyntax)
+	})
+}
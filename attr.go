@@ -0,0 +1,340 @@
+package dom
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"weak"
+
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+// Attr is an attribute node. Each attribute of an element has one Attr, which
+// reads and writes the attribute in the element's html.Node attribute list.
+// Once the attribute is removed or replaced, the Attr keeps its last value and
+// has no owner element.
+type Attr struct {
+	owner *html.Node
+	attr  html.Attribute
+
+	// document is the node document while the attribute has no owner.
+	document *html.Node
+}
+
+func (a *Attr) NodeType() spec.NodeType { return spec.NodeTypeAttribute }
+func (a *Attr) Name() string            { return attributeQualifiedName(a.attr) }
+func (a *Attr) LocalName() string       { return a.attr.Key }
+func (a *Attr) Prefix() string          { return a.attr.Namespace }
+func (a *Attr) NamespaceURI() string    { return attributeNamespaceURI(a.ownerNode(), a.attr) }
+func (a *Attr) TextContent() string     { return a.Value() }
+func (a *Attr) String() string          { return a.Value() }
+
+func (a *Attr) Value() string {
+	if i := a.index(); i >= 0 {
+		a.attr.Val = a.owner.Attr[i].Val
+	}
+	return a.attr.Val
+}
+
+func (a *Attr) SetValue(value string) {
+	if i := a.index(); i >= 0 {
+		changeAttribute(a.owner, i, value)
+	}
+	a.attr.Val = value
+}
+
+func (a *Attr) OwnerElement() spec.Element {
+	if a.index() < 0 {
+		return nil
+	}
+	return newElement(a.owner)
+}
+
+func (a *Attr) OwnerDocument() spec.Document {
+	document := a.documentNode()
+	if document == nil {
+		return nil
+	}
+	return &Document{node: document}
+}
+
+func (a *Attr) CloneNode(bool) spec.Node {
+	return &Attr{
+		attr:     html.Attribute{Namespace: a.attr.Namespace, Key: a.attr.Key, Val: a.Value()},
+		document: a.documentNode(),
+	}
+}
+
+func (a *Attr) IsSameNode(other spec.Node) bool {
+	o, ok := other.(*Attr)
+	if !ok || a == nil || o == nil {
+		return false
+	}
+	return a == o
+}
+
+// IsEqualNode compares the namespace, local name and value. See
+// https://dom.spec.whatwg.org/#concept-node-equals
+func (a *Attr) IsEqualNode(other spec.Node) bool {
+	o, ok := other.(*Attr)
+	if !ok || o == nil {
+		return false
+	}
+	return a.NamespaceURI() == o.NamespaceURI() && a.LocalName() == o.LocalName() && a.Value() == o.Value()
+}
+
+// GetRootNode returns the attribute because attributes do not have a parent.
+func (a *Attr) GetRootNode() spec.Node { return a }
+
+func (a *Attr) LookupNamespaceURI(prefix string) string {
+	return lookupNamespaceURI(a.ownerNode(), prefix)
+}
+func (a *Attr) LookupPrefix(namespace string) string { return lookupPrefix(a.ownerNode(), namespace) }
+
+func (a *Attr) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
+	return compareNodeDocumentPosition(a.ownerNode(), a, other)
+}
+
+// index returns the index of the attribute in the owner element's attribute
+// list or -1 when the attribute is not attached.
+func (a *Attr) index() int {
+	if a.owner == nil {
+		return -1
+	}
+	for i, att := range a.owner.Attr {
+		if sameAttribute(att, a.attr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// documentNode returns the node document of the attribute, which is the node
+// document of its owner element while it has one.
+func (a *Attr) documentNode() *html.Node {
+	if owner := a.ownerNode(); owner != nil {
+		return ownerDocumentNode(owner)
+	}
+	return a.document
+}
+
+// ownerNode returns the element the attribute is attached to or nil.
+func (a *Attr) ownerNode() *html.Node {
+	if a.index() < 0 {
+		return nil
+	}
+	return a.owner
+}
+
+// NamedNodeMap is a live view of an element's attribute list. See
+// https://dom.spec.whatwg.org/#interface-namednodemap.
+type NamedNodeMap struct {
+	element *html.Node
+}
+
+func (m *NamedNodeMap) Length() int { return len(m.element.Attr) }
+
+func (m *NamedNodeMap) Item(index int) spec.Attr {
+	if index < 0 || index >= len(m.element.Attr) {
+		return nil
+	}
+	return attrNode(m.element, index)
+}
+
+func (m *NamedNodeMap) GetNamedItem(qualifiedName string) spec.Attr {
+	return getAttributeNode(m.element, qualifiedName)
+}
+
+func (m *NamedNodeMap) SetNamedItem(attr spec.Attr) (spec.Attr, error) {
+	return setAttributeNode(m.element, attr)
+}
+
+func (m *NamedNodeMap) RemoveNamedItem(qualifiedName string) (spec.Attr, error) {
+	i := attributeIndex(m.element, qualifiedName)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: attribute %q", spec.ErrNotFound, qualifiedName)
+	}
+	a := attrNode(m.element, i)
+	removeAttributeIndex(m.element, i)
+	return a, nil
+}
+
+func sameAttribute(a, b html.Attribute) bool {
+	return a.Namespace == b.Namespace && a.Key == b.Key
+}
+
+// attributeQualifiedName returns the prefixed name of the attribute. x/net/html
+// stores the prefix of foreign attributes, like xlink:href, in the Namespace field.
+func attributeQualifiedName(att html.Attribute) string {
+	if att.Namespace == "" {
+		return att.Key
+	}
+	return att.Namespace + ":" + att.Key
+}
+
+// attributeIndex is based on https://dom.spec.whatwg.org/#concept-element-attributes-get-by-name
+func attributeIndex(node *html.Node, qualifiedName string) int {
+	qualifiedName = attributeNameCase(node, qualifiedName)
+	for i, att := range node.Attr {
+		if attributeQualifiedName(att) == qualifiedName {
+			return i
+		}
+	}
+	return -1
+}
+
+// attributeNameCase lowercases name for elements in the HTML namespace.
+func attributeNameCase(node *html.Node, name string) string {
+	if node.Namespace != "" {
+		return name
+	}
+	return strings.ToLower(name)
+}
+
+func getAttributeNames(node *html.Node) []string {
+	names := make([]string, 0, len(node.Attr))
+	for _, att := range node.Attr {
+		names = append(names, attributeQualifiedName(att))
+	}
+	return names
+}
+
+func getAttributeNode(node *html.Node, qualifiedName string) spec.Attr {
+	i := attributeIndex(node, qualifiedName)
+	if i < 0 {
+		return nil
+	}
+	return attrNode(node, i)
+}
+
+// setAttributeNode is based on https://dom.spec.whatwg.org/#concept-element-attributes-set
+func setAttributeNode(node *html.Node, attr spec.Attr) (spec.Attr, error) {
+	a, ok := attr.(*Attr)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not a dom attribute", spec.ErrNotSupported, attr)
+	}
+	switch owner := a.ownerNode(); owner {
+	case nil:
+	case node:
+		return a, nil
+	default:
+		return nil, fmt.Errorf("%w: %s is attached to another element", spec.ErrInUseAttribute, a.Name())
+	}
+	value := a.Value()
+	for i, att := range node.Attr {
+		if sameAttribute(att, a.attr) {
+			old := attrNode(node, i)
+			detachAttrNode(node, att)
+			changeAttribute(node, i, value)
+			attachAttrNode(node, a)
+			return old, nil
+		}
+	}
+	appendAttribute(node, html.Attribute{Namespace: a.attr.Namespace, Key: a.attr.Key, Val: value})
+	attachAttrNode(node, a)
+	return nil, nil
+}
+
+// removeAttributeNode is based on https://dom.spec.whatwg.org/#dom-element-removeattributenode
+func removeAttributeNode(node *html.Node, attr spec.Attr) (spec.Attr, error) {
+	a, ok := attr.(*Attr)
+	if !ok || a.ownerNode() != node {
+		return nil, fmt.Errorf("%w: attribute is not attached to the element", spec.ErrNotFound)
+	}
+	removeAttributeIndex(node, a.index())
+	return a, nil
+}
+
+// attrNodes holds the Attr handed out for each attribute of an element, so an
+// attribute is always the same Attr. The Attrs are weak; one that is no longer
+// referenced is made again when it is next asked for.
+var attrNodes struct {
+	mu sync.Mutex
+	m  map[weak.Pointer[html.Node]][]weak.Pointer[Attr]
+}
+
+func deleteAttrNodes(key weak.Pointer[html.Node]) {
+	attrNodes.mu.Lock()
+	defer attrNodes.mu.Unlock()
+	delete(attrNodes.m, key)
+}
+
+// attrNode returns the Attr of the attribute at index i of node.
+func attrNode(node *html.Node, i int) *Attr {
+	attrNodes.mu.Lock()
+	defer attrNodes.mu.Unlock()
+	key := weak.Make(node)
+	for _, p := range attrNodes.m[key] {
+		if a := p.Value(); a != nil && sameAttribute(a.attr, node.Attr[i]) {
+			return a
+		}
+	}
+	a := &Attr{owner: node, attr: node.Attr[i]}
+	addAttrNode(node, key, a)
+	return a
+}
+
+// attachAttrNode makes a the Attr of its attribute in node.
+func attachAttrNode(node *html.Node, a *Attr) {
+	attrNodes.mu.Lock()
+	defer attrNodes.mu.Unlock()
+	a.owner = node
+	addAttrNode(node, weak.Make(node), a)
+}
+
+func addAttrNode(node *html.Node, key weak.Pointer[html.Node], a *Attr) {
+	if attrNodes.m == nil {
+		attrNodes.m = make(map[weak.Pointer[html.Node]][]weak.Pointer[Attr])
+	}
+	list, ok := attrNodes.m[key]
+	if !ok {
+		runtime.AddCleanup(node, deleteAttrNodes, key)
+	}
+	list = slices.DeleteFunc(list, func(p weak.Pointer[Attr]) bool { return p.Value() == nil })
+	attrNodes.m[key] = append(list, weak.Make(a))
+}
+
+// detachAttrNode clears the owner of the Attr handed out for att, which keeps
+// the value att had and the node document of node.
+func detachAttrNode(node *html.Node, att html.Attribute) {
+	document := ownerDocumentNode(node)
+	attrNodes.mu.Lock()
+	defer attrNodes.mu.Unlock()
+	key := weak.Make(node)
+	list := attrNodes.m[key]
+	for i, p := range list {
+		if a := p.Value(); a != nil && sameAttribute(a.attr, att) {
+			a.owner, a.attr.Val, a.document = nil, att.Val, document
+			attrNodes.m[key] = slices.Delete(list, i, i+1)
+			return
+		}
+	}
+}
+
+// changeAttribute, appendAttribute, and removeAttributeIndex are the only
+// functions that modify an element's attribute list.
+
+func changeAttribute(node *html.Node, i int, value string) {
+	queueAttributeMutationRecord(node, node.Attr[i], node.Attr[i].Val)
+	node.Attr[i].Val = value
+	treeMutated(node)
+}
+
+func appendAttribute(node *html.Node, att html.Attribute) {
+	queueAttributeMutationRecord(node, att, "")
+	node.Attr = append(node.Attr, att)
+	treeMutated(node)
+}
+
+func removeAttributeIndex(node *html.Node, i int) html.Attribute {
+	att := node.Attr[i]
+	queueAttributeMutationRecord(node, att, att.Val)
+	detachAttrNode(node, att)
+	node.Attr = append(node.Attr[:i:i], node.Attr[i+1:]...)
+	treeMutated(node)
+	return att
+}
@@ -0,0 +1,250 @@
+package dom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/typelate/dom/spec"
+)
+
+var (
+	_ spec.Attr         = (*Attr)(nil)
+	_ spec.NamedNodeMap = (*NamedNodeMap)(nil)
+)
+
+func TestElement_Attributes(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang='us-en'>
+<head><title></title></head>
+<body><div id='target' class='a b' hidden><svg><use xlink:href='#icon'></use></svg></div></body>
+</html>`
+	_, target := parseDocument(t, textHTML, "#target")
+
+	t.Run("enumerate", func(t *testing.T) {
+		attributes := target.Attributes()
+		require.Equal(t, 3, attributes.Length())
+		assert.Equal(t, "id", attributes.Item(0).Name())
+		assert.Equal(t, "target", attributes.Item(0).Value())
+		assert.Equal(t, "class", attributes.Item(1).Name())
+		assert.Equal(t, "hidden", attributes.Item(2).Name())
+		assert.Equal(t, "", attributes.Item(2).Value())
+		assert.Nil(t, attributes.Item(3))
+		assert.Nil(t, attributes.Item(-1))
+
+		assert.Equal(t, []string{"id", "class", "hidden"}, target.GetAttributeNames())
+		assert.True(t, target.HasAttributes())
+	})
+	t.Run("foreign attribute", func(t *testing.T) {
+		use := target.QuerySelector("use")
+		assert.Equal(t, []string{"xlink:href"}, use.GetAttributeNames())
+		attr := use.GetAttributeNode("xlink:href")
+		require.NotNil(t, attr)
+		assert.Equal(t, "href", attr.LocalName())
+		assert.Equal(t, "#icon", attr.Value())
+		assert.Nil(t, use.GetAttributeNode("href"))
+	})
+	t.Run("node", func(t *testing.T) {
+		attr := target.Attributes().GetNamedItem("CLASS")
+		require.NotNil(t, attr)
+		assert.Equal(t, spec.NodeTypeAttribute, attr.NodeType())
+		assert.Equal(t, "a b", attr.TextContent())
+		assert.True(t, attr.OwnerElement().IsSameNode(target))
+		assert.True(t, attr.IsSameNode(target.GetAttributeNode("class")))
+		assert.False(t, attr.IsSameNode(target.GetAttributeNode("id")))
+	})
+	t.Run("live", func(t *testing.T) {
+		_, target := parseDocument(t, textHTML, "#target")
+		attributes := target.Attributes()
+		attr := target.GetAttributeNode("class")
+
+		target.SetAttribute("class", "c")
+		assert.Equal(t, "c", attr.Value())
+
+		attr.SetValue("d")
+		assert.Equal(t, "d", target.ClassName())
+
+		target.SetAttribute("lang", "en")
+		assert.Equal(t, 4, attributes.Length())
+
+		target.RemoveAttribute("class")
+		assert.Equal(t, 3, attributes.Length())
+		assert.Nil(t, attr.OwnerElement())
+		assert.Equal(t, "d", attr.Value())
+
+		attr.SetValue("e")
+		assert.False(t, target.HasAttribute("class"))
+	})
+	t.Run("none", func(t *testing.T) {
+		var document *Document
+		el := document.CreateElement("div")
+		assert.False(t, el.HasAttributes())
+		assert.Empty(t, el.GetAttributeNames())
+		assert.Zero(t, el.Attributes().Length())
+	})
+}
+
+func TestElement_SetAttributeNode(t *testing.T) {
+	var document *Document
+
+	t.Run("new", func(t *testing.T) {
+		el := document.CreateElement("div")
+		attr := document.CreateAttribute("Title")
+		attr.SetValue("peach")
+		assert.Nil(t, attr.OwnerElement())
+
+		old, err := el.SetAttributeNode(attr)
+		require.NoError(t, err)
+		assert.Nil(t, old)
+		assert.Equal(t, "peach", el.GetAttribute("title"))
+		assert.True(t, attr.OwnerElement().IsSameNode(el))
+
+		attr.SetValue("pear")
+		assert.Equal(t, `<div title="pear"></div>`, el.OuterHTML())
+	})
+	t.Run("replace", func(t *testing.T) {
+		el := document.CreateElement("div")
+		el.SetAttribute("title", "apple")
+		previous := el.GetAttributeNode("title")
+
+		attr := document.CreateAttribute("title")
+		attr.SetValue("peach")
+		old, err := el.SetAttributeNode(attr)
+		require.NoError(t, err)
+		require.NotNil(t, old)
+		assert.Equal(t, "apple", old.Value())
+		assert.Nil(t, old.OwnerElement())
+		assert.Equal(t, "peach", el.GetAttribute("title"))
+		assert.True(t, old.IsSameNode(previous))
+		assert.Equal(t, "apple", previous.Value())
+		assert.Nil(t, previous.OwnerElement())
+		assert.False(t, previous.IsSameNode(attr))
+		assert.True(t, attr.IsSameNode(el.GetAttributeNode("title")))
+		assert.True(t, attr.OwnerElement().IsSameNode(el))
+
+		previous.SetValue("pear")
+		assert.Equal(t, "peach", el.GetAttribute("title"))
+	})
+	t.Run("removed and added again", func(t *testing.T) {
+		el := document.CreateElement("div")
+		el.SetAttribute("title", "apple")
+		previous := el.GetAttributeNode("title")
+		el.RemoveAttribute("title")
+		el.SetAttribute("title", "peach")
+
+		assert.Equal(t, "apple", previous.Value())
+		assert.Nil(t, previous.OwnerElement())
+		assert.False(t, previous.IsSameNode(el.GetAttributeNode("title")))
+	})
+	t.Run("same", func(t *testing.T) {
+		el := document.CreateElement("div")
+		el.SetAttribute("title", "apple")
+		attr := el.GetAttributeNode("title")
+		old, err := el.SetAttributeNode(attr)
+		require.NoError(t, err)
+		assert.True(t, old.IsSameNode(attr))
+	})
+	t.Run("in use", func(t *testing.T) {
+		a := document.CreateElement("div")
+		a.SetAttribute("title", "apple")
+		b := document.CreateElement("div")
+
+		_, err := b.SetAttributeNode(a.GetAttributeNode("title"))
+		require.ErrorIs(t, err, spec.ErrInUseAttribute)
+		assert.False(t, b.HasAttribute("title"))
+
+		_, err = b.Attributes().SetNamedItem(a.GetAttributeNode("title").CloneNode(false).(spec.Attr))
+		require.NoError(t, err)
+		assert.Equal(t, "apple", b.GetAttribute("title"))
+	})
+}
+
+func TestElement_RemoveAttributeNode(t *testing.T) {
+	var document *Document
+	el := document.CreateElement("div")
+	el.SetAttribute("title", "apple")
+	el.SetAttribute("lang", "en")
+	attr := el.GetAttributeNode("title")
+
+	removed, err := el.RemoveAttributeNode(attr)
+	require.NoError(t, err)
+	assert.True(t, removed.IsSameNode(attr))
+	assert.Nil(t, removed.OwnerElement())
+	assert.Equal(t, "apple", removed.Value())
+	assert.Equal(t, []string{"lang"}, el.GetAttributeNames())
+
+	_, err = el.RemoveAttributeNode(attr)
+	assert.ErrorIs(t, err, spec.ErrNotFound)
+
+	_, err = el.Attributes().RemoveNamedItem("title")
+	assert.ErrorIs(t, err, spec.ErrNotFound)
+
+	removed, err = el.Attributes().RemoveNamedItem("lang")
+	require.NoError(t, err)
+	assert.Equal(t, "en", removed.Value())
+	assert.False(t, el.HasAttributes())
+}
+
+func TestAttr_CompareDocumentPosition(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html><html lang='us-en'><body><div id='a' title='x'><span id='b'></span></div></body></html>`
+	doc, a := parseDocument(t, textHTML, "#a")
+	b := doc.QuerySelector("#b")
+	id := a.GetAttributeNode("id")
+	title := a.GetAttributeNode("title")
+
+	assert.Equal(t, spec.DocumentPosition(0), id.CompareDocumentPosition(a.GetAttributeNode("id")))
+	assert.Equal(t, spec.DocumentPositionContains|spec.DocumentPositionPreceding, id.CompareDocumentPosition(a))
+	assert.Equal(t, spec.DocumentPositionContainedBy|spec.DocumentPositionFollowing, a.CompareDocumentPosition(id))
+	assert.Equal(t, spec.DocumentPositionImplementationSpecific|spec.DocumentPositionPreceding, title.CompareDocumentPosition(id))
+	assert.Equal(t, spec.DocumentPositionImplementationSpecific|spec.DocumentPositionFollowing, id.CompareDocumentPosition(title))
+	assert.Equal(t, spec.DocumentPositionFollowing, id.CompareDocumentPosition(b))
+	assert.Equal(t, spec.DocumentPositionPreceding, b.CompareDocumentPosition(id))
+
+	var document *Document
+	detached := document.CreateAttribute("x")
+	pos := detached.CompareDocumentPosition(id)
+	assert.Equal(t, spec.DocumentPositionDisconnected|spec.DocumentPositionImplementationSpecific, pos)
+}
+
+func TestAttr_IsEqualNode(t *testing.T) {
+	var document *Document
+	a := document.CreateElement("a")
+	a.SetAttribute("href", "/")
+	b := document.CreateElement("b")
+	b.SetAttribute("href", "/")
+	require.NoError(t, b.SetAttributeNS(spec.XLinkNamespace, "xlink:href", "/"))
+
+	attr := a.GetAttributeNode("href")
+	assert.True(t, attr.IsEqualNode(b.GetAttributeNode("href")))
+	assert.False(t, attr.IsEqualNode(b.Attributes().Item(1)), "namespaces differ")
+	assert.True(t, attr.GetRootNode().IsSameNode(attr))
+	assert.True(t, a.IsEqualNode(a.CloneNode(false)))
+	assert.False(t, a.IsEqualNode(b))
+}
+
+func TestAttr_insert(t *testing.T) {
+	var document *Document
+	el := document.CreateElement("div")
+	el.SetAttribute("id", "a")
+	child := document.CreateElement("span")
+	el.AppendChild(child)
+
+	for name, insert := range map[string]func(spec.Attr){
+		"AppendChild":  func(attr spec.Attr) { el.AppendChild(attr) },
+		"InsertBefore": func(attr spec.Attr) { el.InsertBefore(attr, child) },
+		"Append":       func(attr spec.Attr) { el.Append(attr) },
+		"Prepend":      func(attr spec.Attr) { el.Prepend(attr) },
+		"Before":       func(attr spec.Attr) { child.Before(attr) },
+		"After":        func(attr spec.Attr) { child.After(attr) },
+		"ReplaceChild": func(attr spec.Attr) { el.ReplaceChild(attr, child) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			attr := el.GetAttributeNode("id")
+			require.PanicsWithError(t, "HierarchyRequestError: Attribute nodes can not be inserted", func() { insert(attr) })
+			assert.Equal(t, `<div id="a"><span></span></div>`, el.OuterHTML())
+		})
+	}
+}
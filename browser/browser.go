@@ -5,7 +5,13 @@
 package browser
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"iter"
+	"runtime"
+	"strings"
+	"sync"
 	"syscall/js"
 
 	"github.com/typelate/dom/spec"
@@ -15,10 +21,17 @@ type Node struct {
 	value js.Value
 }
 
-func (n *Node) NodeType() spec.NodeType         { return nodeType(n.value) }
-func (n *Node) CloneNode(deep bool) spec.Node   { return cloneNode(n.value, deep) }
-func (n *Node) IsSameNode(other spec.Node) bool { return isSameNode(n.value, other) }
-func (n *Node) TextContent() string             { return textContent(n.value) }
+func (n *Node) NodeType() spec.NodeType          { return nodeType(n.value) }
+func (n *Node) CloneNode(deep bool) spec.Node    { return cloneNode(n.value, deep) }
+func (n *Node) IsSameNode(other spec.Node) bool  { return isSameNode(n.value, other) }
+func (n *Node) IsEqualNode(other spec.Node) bool { return isEqualNode(n.value, other) }
+func (n *Node) GetRootNode() spec.Node           { return getRootNode(n.value) }
+func (n *Node) TextContent() string              { return textContent(n.value) }
+
+func (n *Node) LookupNamespaceURI(prefix string) string {
+	return lookupNamespaceURI(n.value, prefix)
+}
+func (n *Node) LookupPrefix(namespace string) string { return lookupPrefix(n.value, namespace) }
 
 func (n *Node) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
 	return compareDocumentPosition(n.value, other)
@@ -39,10 +52,17 @@ func newDocument(value js.Value) spec.Document {
 	return &Document{value: value}
 }
 
-func (d *Document) NodeType() spec.NodeType         { return nodeType(d.value) }
-func (d *Document) CloneNode(deep bool) spec.Node   { return cloneNode(d.value, deep) }
-func (d *Document) IsSameNode(other spec.Node) bool { return isSameNode(d.value, other) }
-func (d *Document) TextContent() string             { return textContent(d.value) }
+func (d *Document) NodeType() spec.NodeType          { return nodeType(d.value) }
+func (d *Document) CloneNode(deep bool) spec.Node    { return cloneNode(d.value, deep) }
+func (d *Document) IsSameNode(other spec.Node) bool  { return isSameNode(d.value, other) }
+func (d *Document) IsEqualNode(other spec.Node) bool { return isEqualNode(d.value, other) }
+func (d *Document) GetRootNode() spec.Node           { return getRootNode(d.value) }
+func (d *Document) TextContent() string              { return textContent(d.value) }
+
+func (d *Document) LookupNamespaceURI(prefix string) string {
+	return lookupNamespaceURI(d.value, prefix)
+}
+func (d *Document) LookupPrefix(namespace string) string { return lookupPrefix(d.value, namespace) }
 
 func (d *Document) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
 	return compareDocumentPosition(d.value, other)
@@ -51,225 +71,1471 @@ func (d *Document) CompareDocumentPosition(other spec.Node) spec.DocumentPositio
 func (d *Document) Head() spec.Element { return newElement(d.value.Get("head")) }
 func (d *Document) Body() spec.Element { return newElement(d.value.Get("body")) }
 
+func (d *Document) DocumentElement() spec.Element { return newElement(d.value.Get("documentElement")) }
+
+func (d *Document) GetElementByID(elementID string) spec.Element {
+	return newElement(d.value.Call("getElementById", elementID))
+}
+
+func (d *Document) Title() string         { return d.value.Get("title").String() }
+func (d *Document) SetTitle(title string) { d.value.Set("title", title) }
+
+func (d *Document) GetElementsByName(elementName string) spec.NodeList[spec.Element] {
+	return elementList{value: d.value.Call("getElementsByName", elementName)}
+}
+
+func (d *Document) Forms() spec.ElementCollection { return htmlCollection{value: d.value.Get("forms")} }
+func (d *Document) Images() spec.ElementCollection {
+	return htmlCollection{value: d.value.Get("images")}
+}
+func (d *Document) Links() spec.ElementCollection { return htmlCollection{value: d.value.Get("links")} }
+func (d *Document) Scripts() spec.ElementCollection {
+	return htmlCollection{value: d.value.Get("scripts")}
+}
+func (d *Document) Embeds() spec.ElementCollection {
+	return htmlCollection{value: d.value.Get("embeds")}
+}
+
+func (d *Document) Doctype() spec.DocumentType { return newDocumentType(d.value.Get("doctype")) }
+func (d *Document) Implementation() spec.DOMImplementation {
+	return &DOMImplementation{value: d.value.Get("implementation")}
+}
+
+type DOMImplementation struct {
+	value js.Value
+}
+
+func (d *DOMImplementation) CreateDocumentType(name, publicID, systemID string) (spec.DocumentType, error) {
+	var result js.Value
+	if err := catch(func() { result = d.value.Call("createDocumentType", name, publicID, systemID) }); err != nil {
+		return nil, err
+	}
+	return newDocumentType(result), nil
+}
+
 func (d *Document) Contains(other spec.Node) bool { return contains(d.value, other) }
+func (d *Document) Normalize()                    { d.value.Call("normalize") }
+
+func (d *Document) Descendants() iter.Seq[spec.Node]        { return descendants(d.value) }
+func (d *Document) ChildNodesSeq() iter.Seq[spec.ChildNode] { return childNodesSeq(d.value) }
+func (d *Document) ChildrenSeq() iter.Seq[spec.Element]     { return childrenSeq(d.value) }
+
+func (d *Document) AddEventListener(eventType string, listener spec.EventListener, options spec.AddEventListenerOptions) {
+	addEventListener(d.value, eventType, listener, options)
+}
+
+func (d *Document) RemoveEventListener(eventType string, listener spec.EventListener, options spec.EventListenerOptions) {
+	removeEventListener(d.value, eventType, listener, options)
+}
+
+func (d *Document) DispatchEvent(event spec.Event) (bool, error) {
+	return dispatchEvent(d.value, event)
+}
 
 func (d *Document) GetElementsByTagName(name string) spec.ElementCollection {
 	return getElementsByTagName(d.value, name)
 }
 
+func (d *Document) GetElementsByTagNameNS(namespace, localName string) spec.ElementCollection {
+	return htmlCollection{value: d.value.Call("getElementsByTagNameNS", nullableNamespace(namespace), localName)}
+}
+
 func (d *Document) GetElementsByClassName(name string) spec.ElementCollection {
 	return getElementsByClassName(d.value, name)
 }
 
 func (d *Document) QuerySelector(query string) spec.Element {
-	return querySelector(d.value, query)
+	el, _ := querySelector(d.value, query)
+	return el
 }
 
 func (d *Document) QuerySelectorAll(query string) spec.NodeList[spec.Element] {
+	list, _ := querySelectorAll(d.value, query)
+	return list
+}
+
+func (d *Document) QuerySelectorSequence(query string) iter.Seq[spec.Element] {
+	seq, _ := querySelectorEach(d.value, query)
+	return seq
+}
+
+func (d *Document) QuerySelectorErr(query string) (spec.Element, error) {
+	return querySelector(d.value, query)
+}
+
+func (d *Document) QuerySelectorAllErr(query string) (spec.NodeList[spec.Element], error) {
 	return querySelectorAll(d.value, query)
 }
 
-func (d *Document) QuerySelectorSequence(query string) iter.Seq[spec.Element] {
-	return querySelectorEach(d.value, query)
+func (d *Document) QuerySelectorSequenceErr(query string) (iter.Seq[spec.Element], error) {
+	return querySelectorEach(d.value, query)
+}
+
+func (d *Document) CreateElement(localName string) spec.Element {
+	return createElement(d.value, localName)
+}
+
+func (d *Document) CreateElementIs(localName, is string) spec.Element {
+	return createElementIs(d.value, localName, is)
+}
+
+func (d *Document) CreateElementNS(namespace, qualifiedName string) (spec.Element, error) {
+	var result js.Value
+	if err := catch(func() { result = d.value.Call("createElementNS", nullableNamespace(namespace), qualifiedName) }); err != nil {
+		return nil, err
+	}
+	return newElement(result), nil
+}
+
+func (d *Document) ImportNode(node spec.Node, deep bool) (spec.Node, error) {
+	var result js.Value
+	if err := catch(func() { result = d.value.Call("importNode", JSValue(node), deep) }); err != nil {
+		return nil, err
+	}
+	return NewNode(result), nil
+}
+
+func (d *Document) AdoptNode(node spec.Node) (spec.Node, error) {
+	var result js.Value
+	if err := catch(func() { result = d.value.Call("adoptNode", JSValue(node)) }); err != nil {
+		return nil, err
+	}
+	return NewNode(result), nil
+}
+
+func (d *Document) CreateTextNode(text string) spec.Text {
+	return createTextNode(d.value, text)
+}
+
+func (d *Document) CreateAttribute(localName string) spec.Attr {
+	return newAttr(d.value.Call("createAttribute", localName))
+}
+
+func (d *Document) CreateComment(data string) spec.Comment {
+	return newComment(d.value.Call("createComment", data))
+}
+
+func (d *Document) CreateDocumentFragment() spec.DocumentFragment {
+	return &DocumentFragment{value: d.value.Call("createDocumentFragment")}
+}
+
+func (d *Document) CreateTreeWalker(root spec.Node, whatToShow spec.WhatToShow, filter spec.NodeFilter) spec.TreeWalker {
+	callback, release := nodeFilter(filter)
+	w := &TreeWalker{value: d.value.Call("createTreeWalker", JSValue(root), uint32(whatToShow), callback)}
+	if release != nil {
+		runtime.AddCleanup(w, release, struct{}{})
+	}
+	return w
+}
+
+func (d *Document) CreateNodeIterator(root spec.Node, whatToShow spec.WhatToShow, filter spec.NodeFilter) spec.NodeIterator {
+	callback, release := nodeFilter(filter)
+	it := &NodeIterator{value: d.value.Call("createNodeIterator", JSValue(root), uint32(whatToShow), callback)}
+	if release != nil {
+		runtime.AddCleanup(it, release, struct{}{})
+	}
+	return it
+}
+
+// nodeFilter wraps filter in a JavaScript function. The returned release
+// function frees it and is nil when filter is nil.
+func nodeFilter(filter spec.NodeFilter) (js.Value, func(struct{})) {
+	if filter == nil {
+		return js.Null(), nil
+	}
+	fn := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		return int(filter(NewNode(args[0])))
+	})
+	return fn.Value, func(struct{}) { fn.Release() }
+}
+
+func (d *Document) CreateRange() spec.Range { return &Range{value: d.value.Call("createRange")} }
+
+// NewStaticRange is based on https://dom.spec.whatwg.org/#dom-staticrange-staticrange
+func NewStaticRange(init spec.StaticRangeInit) (*StaticRange, error) {
+	options := js.ValueOf(map[string]any{
+		"startContainer": JSValue(init.StartContainer),
+		"startOffset":    init.StartOffset,
+		"endContainer":   JSValue(init.EndContainer),
+		"endOffset":      init.EndOffset,
+	})
+	var value js.Value
+	if err := catch(func() { value = staticRangeClass.New(options) }); err != nil {
+		return nil, err
+	}
+	return &StaticRange{value: value}, nil
+}
+
+type StaticRange struct {
+	value js.Value
+}
+
+func (r *StaticRange) StartContainer() spec.Node { return NewNode(r.value.Get("startContainer")) }
+func (r *StaticRange) StartOffset() int          { return r.value.Get("startOffset").Int() }
+func (r *StaticRange) EndContainer() spec.Node   { return NewNode(r.value.Get("endContainer")) }
+func (r *StaticRange) EndOffset() int            { return r.value.Get("endOffset").Int() }
+func (r *StaticRange) Collapsed() bool           { return r.value.Get("collapsed").Bool() }
+
+type Range struct {
+	value js.Value
+}
+
+func (r *Range) StartContainer() spec.Node { return NewNode(r.value.Get("startContainer")) }
+func (r *Range) StartOffset() int          { return r.value.Get("startOffset").Int() }
+func (r *Range) EndContainer() spec.Node   { return NewNode(r.value.Get("endContainer")) }
+func (r *Range) EndOffset() int            { return r.value.Get("endOffset").Int() }
+func (r *Range) Collapsed() bool           { return r.value.Get("collapsed").Bool() }
+
+func (r *Range) CommonAncestorContainer() spec.Node {
+	return NewNode(r.value.Get("commonAncestorContainer"))
+}
+
+func (r *Range) SetStart(node spec.Node, offset int) error {
+	return catch(func() { r.value.Call("setStart", JSValue(node), offset) })
+}
+
+func (r *Range) SetEnd(node spec.Node, offset int) error {
+	return catch(func() { r.value.Call("setEnd", JSValue(node), offset) })
+}
+
+func (r *Range) SetStartBefore(node spec.Node) error { return r.call("setStartBefore", node) }
+func (r *Range) SetStartAfter(node spec.Node) error  { return r.call("setStartAfter", node) }
+func (r *Range) SetEndBefore(node spec.Node) error   { return r.call("setEndBefore", node) }
+func (r *Range) SetEndAfter(node spec.Node) error    { return r.call("setEndAfter", node) }
+func (r *Range) Collapse(toStart bool)               { r.value.Call("collapse", toStart) }
+func (r *Range) SelectNode(node spec.Node) error     { return r.call("selectNode", node) }
+
+func (r *Range) SelectNodeContents(node spec.Node) error {
+	return r.call("selectNodeContents", node)
+}
+
+// call calls the method name with node, returning a thrown DOMException as
+// an error.
+func (r *Range) call(name string, node spec.Node) error {
+	return catch(func() { r.value.Call(name, JSValue(node)) })
+}
+
+func (r *Range) CompareBoundaryPoints(how spec.HowToCompare, sourceRange spec.Range) (int, error) {
+	var result int
+	err := catch(func() { result = r.value.Call("compareBoundaryPoints", int(how), JSValue(sourceRange)).Int() })
+	return result, err
+}
+
+func (r *Range) DeleteContents() { r.value.Call("deleteContents") }
+
+func (r *Range) ExtractContents() (spec.DocumentFragment, error) {
+	var result js.Value
+	if err := catch(func() { result = r.value.Call("extractContents") }); err != nil {
+		return nil, err
+	}
+	return &DocumentFragment{value: result}, nil
+}
+
+func (r *Range) CloneContents() (spec.DocumentFragment, error) {
+	var result js.Value
+	if err := catch(func() { result = r.value.Call("cloneContents") }); err != nil {
+		return nil, err
+	}
+	return &DocumentFragment{value: result}, nil
+}
+
+func (r *Range) InsertNode(node spec.Node) error { return r.call("insertNode", node) }
+
+func (r *Range) SurroundContents(newParent spec.Node) error {
+	return r.call("surroundContents", newParent)
+}
+
+func (r *Range) CloneRange() spec.Range { return &Range{value: r.value.Call("cloneRange")} }
+func (r *Range) Detach()                { r.value.Call("detach") }
+
+func (r *Range) IsPointInRange(node spec.Node, offset int) (bool, error) {
+	var result bool
+	err := catch(func() { result = r.value.Call("isPointInRange", JSValue(node), offset).Bool() })
+	return result, err
+}
+
+func (r *Range) ComparePoint(node spec.Node, offset int) (int, error) {
+	var result int
+	err := catch(func() { result = r.value.Call("comparePoint", JSValue(node), offset).Int() })
+	return result, err
+}
+
+func (r *Range) IntersectsNode(node spec.Node) bool {
+	return r.value.Call("intersectsNode", JSValue(node)).Bool()
+}
+
+func (r *Range) String() string { return r.value.Call("toString").String() }
+
+type TreeWalker struct {
+	value js.Value
+}
+
+func (w *TreeWalker) Root() spec.Node        { return NewNode(w.value.Get("root")) }
+func (w *TreeWalker) CurrentNode() spec.Node { return NewNode(w.value.Get("currentNode")) }
+
+func (w *TreeWalker) WhatToShow() spec.WhatToShow {
+	return spec.WhatToShow(w.value.Get("whatToShow").Int())
+}
+
+func (w *TreeWalker) SetCurrentNode(node spec.Node) { w.value.Set("currentNode", JSValue(node)) }
+
+func (w *TreeWalker) ParentNode() spec.Node      { return NewNode(w.value.Call("parentNode")) }
+func (w *TreeWalker) FirstChild() spec.Node      { return NewNode(w.value.Call("firstChild")) }
+func (w *TreeWalker) LastChild() spec.Node       { return NewNode(w.value.Call("lastChild")) }
+func (w *TreeWalker) PreviousSibling() spec.Node { return NewNode(w.value.Call("previousSibling")) }
+func (w *TreeWalker) NextSibling() spec.Node     { return NewNode(w.value.Call("nextSibling")) }
+func (w *TreeWalker) PreviousNode() spec.Node    { return NewNode(w.value.Call("previousNode")) }
+func (w *TreeWalker) NextNode() spec.Node        { return NewNode(w.value.Call("nextNode")) }
+
+type NodeIterator struct {
+	value js.Value
+}
+
+func (it *NodeIterator) Root() spec.Node          { return NewNode(it.value.Get("root")) }
+func (it *NodeIterator) ReferenceNode() spec.Node { return NewNode(it.value.Get("referenceNode")) }
+
+func (it *NodeIterator) PointerBeforeReferenceNode() bool {
+	return it.value.Get("pointerBeforeReferenceNode").Bool()
+}
+
+func (it *NodeIterator) WhatToShow() spec.WhatToShow {
+	return spec.WhatToShow(it.value.Get("whatToShow").Int())
+}
+
+func (it *NodeIterator) NextNode() spec.Node     { return NewNode(it.value.Call("nextNode")) }
+func (it *NodeIterator) PreviousNode() spec.Node { return NewNode(it.value.Call("previousNode")) }
+func (it *NodeIterator) Detach()                 { it.value.Call("detach") }
+
+type DocumentFragment struct {
+	value js.Value
+}
+
+func (d *DocumentFragment) NodeType() spec.NodeType          { return nodeType(d.value) }
+func (d *DocumentFragment) CloneNode(deep bool) spec.Node    { return cloneNode(d.value, deep) }
+func (d *DocumentFragment) IsSameNode(other spec.Node) bool  { return isSameNode(d.value, other) }
+func (d *DocumentFragment) IsEqualNode(other spec.Node) bool { return isEqualNode(d.value, other) }
+func (d *DocumentFragment) GetRootNode() spec.Node           { return getRootNode(d.value) }
+func (d *DocumentFragment) TextContent() string              { return textContent(d.value) }
+
+func (d *DocumentFragment) LookupNamespaceURI(prefix string) string {
+	return lookupNamespaceURI(d.value, prefix)
+}
+func (d *DocumentFragment) LookupPrefix(namespace string) string {
+	return lookupPrefix(d.value, namespace)
+}
+
+func (d *DocumentFragment) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
+	return compareDocumentPosition(d.value, other)
+}
+
+func (d *DocumentFragment) Children() spec.ElementCollection { return children(d.value) }
+func (d *DocumentFragment) FirstElementChild() spec.Element  { return firstElementChild(d.value) }
+func (d *DocumentFragment) LastElementChild() spec.Element   { return lastElementChild(d.value) }
+func (d *DocumentFragment) ChildElementCount() int           { return childElementCount(d.value) }
+
+func (d *DocumentFragment) Append(nodes ...spec.Node)          { appendNodes(d.value, nodes) }
+func (d *DocumentFragment) Prepend(nodes ...spec.Node)         { prependNodes(d.value, nodes) }
+func (d *DocumentFragment) ReplaceChildren(nodes ...spec.Node) { replaceChildrenNodes(d.value, nodes) }
+func (d *DocumentFragment) Normalize()                         { d.value.Call("normalize") }
+
+func (d *DocumentFragment) HasChildNodes() bool                  { return hasChildNodes(d.value) }
+func (d *DocumentFragment) ChildNodes() spec.NodeList[spec.Node] { return childNodes(d.value) }
+func (d *DocumentFragment) FirstChild() spec.ChildNode           { return firstChild(d.value) }
+func (d *DocumentFragment) LastChild() spec.ChildNode            { return lastChild(d.value) }
+func (d *DocumentFragment) Contains(other spec.Node) bool        { return contains(d.value, other) }
+
+func (d *DocumentFragment) Descendants() iter.Seq[spec.Node]        { return descendants(d.value) }
+func (d *DocumentFragment) ChildNodesSeq() iter.Seq[spec.ChildNode] { return childNodesSeq(d.value) }
+func (d *DocumentFragment) ChildrenSeq() iter.Seq[spec.Element]     { return childrenSeq(d.value) }
+
+func (d *DocumentFragment) InsertBefore(node spec.Node, child spec.ChildNode) spec.Node {
+	return insertBefore(d.value, node, child)
+}
+
+func (d *DocumentFragment) AppendChild(node spec.Node) spec.Node { return appendChild(d.value, node) }
+
+func (d *DocumentFragment) ReplaceChild(node spec.Node, child spec.ChildNode) spec.ChildNode {
+	return replaceChild(d.value, node, child)
+}
+
+func (d *DocumentFragment) RemoveChild(node spec.ChildNode) spec.ChildNode {
+	return removeChild(d.value, node)
+}
+
+func (d *DocumentFragment) QuerySelector(query string) spec.Element {
+	el, _ := querySelector(d.value, query)
+	return el
+}
+
+func (d *DocumentFragment) QuerySelectorAll(query string) spec.NodeList[spec.Element] {
+	list, _ := querySelectorAll(d.value, query)
+	return list
+}
+
+func (d *DocumentFragment) QuerySelectorSequence(query string) iter.Seq[spec.Element] {
+	seq, _ := querySelectorEach(d.value, query)
+	return seq
+}
+
+func (d *DocumentFragment) QuerySelectorErr(query string) (spec.Element, error) {
+	return querySelector(d.value, query)
+}
+
+func (d *DocumentFragment) QuerySelectorAllErr(query string) (spec.NodeList[spec.Element], error) {
+	return querySelectorAll(d.value, query)
+}
+
+func (d *DocumentFragment) QuerySelectorSequenceErr(query string) (iter.Seq[spec.Element], error) {
+	return querySelectorEach(d.value, query)
+}
+
+type Element struct {
+	value js.Value
+}
+
+func newElement(value js.Value) spec.Element {
+	if value.IsNull() {
+		return nil
+	}
+	switch {
+	case value.InstanceOf(htmlTemplateElementClass):
+		return &HTMLTemplateElement{Element{value: value}}
+	case value.InstanceOf(htmlAnchorElementClass):
+		return &HTMLAnchorElement{Element{value: value}}
+	case value.InstanceOf(htmlAreaElementClass):
+		return &HTMLAreaElement{Element{value: value}}
+	case value.InstanceOf(htmlLinkElementClass):
+		return &HTMLLinkElement{Element{value: value}}
+	}
+	return &Element{value: value}
+}
+
+type HTMLTemplateElement struct {
+	Element
+}
+
+func (t *HTMLTemplateElement) Content() spec.DocumentFragment {
+	return &DocumentFragment{value: t.value.Get("content")}
+}
+
+type HTMLAnchorElement struct {
+	Element
+}
+
+func (a *HTMLAnchorElement) RelList() spec.DOMTokenList { return tokenList(a.value.Get("relList")) }
+
+type HTMLAreaElement struct {
+	Element
+}
+
+func (a *HTMLAreaElement) RelList() spec.DOMTokenList { return tokenList(a.value.Get("relList")) }
+
+type HTMLLinkElement struct {
+	Element
+}
+
+func (l *HTMLLinkElement) RelList() spec.DOMTokenList { return tokenList(l.value.Get("relList")) }
+
+type DOMTokenList struct {
+	value js.Value
+}
+
+func tokenList(value js.Value) spec.DOMTokenList { return &DOMTokenList{value: value} }
+
+func (l *DOMTokenList) Length() int { return l.value.Length() }
+
+func (l *DOMTokenList) Item(index int) string {
+	return nullableString(l.value.Call("item", index))
+}
+
+func (l *DOMTokenList) Contains(token string) bool { return l.value.Call("contains", token).Bool() }
+
+func (l *DOMTokenList) Add(tokens ...string) error {
+	return catch(func() { l.value.Call("add", stringArgs(tokens)...) })
+}
+
+func (l *DOMTokenList) Remove(tokens ...string) error {
+	return catch(func() { l.value.Call("remove", stringArgs(tokens)...) })
+}
+
+func (l *DOMTokenList) Toggle(token string) (bool, error) {
+	var result js.Value
+	err := catch(func() { result = l.value.Call("toggle", token) })
+	if err != nil {
+		return false, err
+	}
+	return result.Bool(), nil
+}
+
+func (l *DOMTokenList) ToggleForce(token string, force bool) (bool, error) {
+	var result js.Value
+	err := catch(func() { result = l.value.Call("toggle", token, force) })
+	if err != nil {
+		return false, err
+	}
+	return result.Bool(), nil
+}
+
+func (l *DOMTokenList) Replace(token, newToken string) (bool, error) {
+	var result js.Value
+	err := catch(func() { result = l.value.Call("replace", token, newToken) })
+	if err != nil {
+		return false, err
+	}
+	return result.Bool(), nil
+}
+
+func (l *DOMTokenList) Value() string         { return l.value.Get("value").String() }
+func (l *DOMTokenList) SetValue(value string) { l.value.Set("value", value) }
+
+func (l *DOMTokenList) All() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for i := 0; i < l.Length(); i++ {
+			if !yield(l.Item(i)) {
+				return
+			}
+		}
+	}
+}
+
+type DOMStringMap struct {
+	value js.Value
+}
+
+func (m *DOMStringMap) Get(name string) string {
+	v := m.value.Get(name)
+	if v.IsUndefined() {
+		return ""
+	}
+	return v.String()
+}
+
+func (m *DOMStringMap) Has(name string) bool {
+	return reflectObject.Call("has", m.value, name).Bool()
+}
+
+func (m *DOMStringMap) Set(name, value string) error { return setProperty(m.value, name, value) }
+
+func (m *DOMStringMap) Delete(name string) { reflectObject.Call("deleteProperty", m.value, name) }
+
+func (m *DOMStringMap) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		keys := objectClass.Call("keys", m.value)
+		for i := 0; i < keys.Length(); i++ {
+			name := keys.Index(i).String()
+			if !yield(name, m.value.Get(name).String()) {
+				return
+			}
+		}
+	}
+}
+
+type CSSStyleDeclaration struct {
+	value js.Value
+}
+
+func (s *CSSStyleDeclaration) Length() int { return s.value.Length() }
+
+func (s *CSSStyleDeclaration) Item(index int) string {
+	return s.value.Call("item", index).String()
+}
+
+func (s *CSSStyleDeclaration) GetPropertyValue(property string) string {
+	return s.value.Call("getPropertyValue", property).String()
+}
+
+func (s *CSSStyleDeclaration) GetPropertyPriority(property string) string {
+	return s.value.Call("getPropertyPriority", property).String()
+}
+
+func (s *CSSStyleDeclaration) SetProperty(property, value, priority string) {
+	s.value.Call("setProperty", property, value, priority)
+}
+
+func (s *CSSStyleDeclaration) RemoveProperty(property string) string {
+	return s.value.Call("removeProperty", property).String()
+}
+
+func (s *CSSStyleDeclaration) CSSText() string        { return s.value.Get("cssText").String() }
+func (s *CSSStyleDeclaration) SetCSSText(text string) { s.value.Set("cssText", text) }
+
+// setProperty sets a property with Reflect.set because js.Value.Set does
+// not recover thrown exceptions.
+func setProperty(receiver js.Value, name string, value any) error {
+	return catch(func() { reflectObject.Call("set", receiver, name, value) })
+}
+
+func stringArgs(values []string) []any {
+	args := make([]any, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+func (e *Element) NodeType() spec.NodeType          { return nodeType(e.value) }
+func (e *Element) CloneNode(deep bool) spec.Node    { return cloneNode(e.value, deep) }
+func (e *Element) IsSameNode(other spec.Node) bool  { return isSameNode(e.value, other) }
+func (e *Element) IsEqualNode(other spec.Node) bool { return isEqualNode(e.value, other) }
+func (e *Element) GetRootNode() spec.Node           { return getRootNode(e.value) }
+func (e *Element) TextContent() string              { return textContent(e.value) }
+func (e *Element) LookupNamespaceURI(prefix string) string {
+	return lookupNamespaceURI(e.value, prefix)
+}
+func (e *Element) LookupPrefix(namespace string) string { return lookupPrefix(e.value, namespace) }
+
+func (e *Element) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
+	return compareDocumentPosition(e.value, other)
+}
+
+func (e *Element) Length() int { return e.Length() }
+
+func (e *Element) IsConnected() bool               { return isConnected(e.value) }
+func (e *Element) OwnerDocument() spec.Document    { return ownerDocument(e.value) }
+func (e *Element) ParentNode() spec.Node           { return parentNode(e.value) }
+func (e *Element) ParentElement() spec.Element     { return parentElement(e.value) }
+func (e *Element) PreviousSibling() spec.ChildNode { return previousSibling(e.value) }
+func (e *Element) NextSibling() spec.ChildNode     { return nextSibling(e.value) }
+
+func (e *Element) Ancestors() iter.Seq[spec.Node]              { return ancestors(e.value) }
+func (e *Element) FollowingSiblings() iter.Seq[spec.ChildNode] { return followingSiblings(e.value) }
+func (e *Element) PrecedingSiblings() iter.Seq[spec.ChildNode] { return precedingSiblings(e.value) }
+
+func (e *Element) AddEventListener(eventType string, listener spec.EventListener, options spec.AddEventListenerOptions) {
+	addEventListener(e.value, eventType, listener, options)
+}
+
+func (e *Element) RemoveEventListener(eventType string, listener spec.EventListener, options spec.EventListenerOptions) {
+	removeEventListener(e.value, eventType, listener, options)
+}
+
+func (e *Element) DispatchEvent(event spec.Event) (bool, error) { return dispatchEvent(e.value, event) }
+
+func (e *Element) Before(nodes ...spec.Node)      { e.value.Call("before", valueArray(nodes)...) }
+func (e *Element) After(nodes ...spec.Node)       { e.value.Call("after", valueArray(nodes)...) }
+func (e *Element) ReplaceWith(nodes ...spec.Node) { e.value.Call("replaceWith", valueArray(nodes)...) }
+func (e *Element) Remove()                        { e.value.Call("remove") }
+
+func (e *Element) Children() spec.ElementCollection { return children(e.value) }
+func (e *Element) FirstElementChild() spec.Element  { return firstElementChild(e.value) }
+func (e *Element) LastElementChild() spec.Element   { return lastElementChild(e.value) }
+func (e *Element) ChildElementCount() int           { return childElementCount(e.value) }
+
+func (e *Element) Prepend(nodes ...spec.Node) { appendNodes(e.value, nodes) }
+
+func (e *Element) Append(nodes ...spec.Node) { prependNodes(e.value, nodes) }
+
+func (e *Element) ReplaceChildren(nodes ...spec.Node) { replaceChildrenNodes(e.value, nodes) }
+
+func (e *Element) Normalize() { e.value.Call("normalize") }
+
+func (e *Element) Contains(other spec.Node) bool { return contains(e.value, other) }
+
+func (e *Element) GetElementsByTagName(name string) spec.ElementCollection {
+	return getElementsByTagName(e.value, name)
+}
+
+func (e *Element) GetElementsByTagNameNS(namespace, localName string) spec.ElementCollection {
+	return htmlCollection{value: e.value.Call("getElementsByTagNameNS", nullableNamespace(namespace), localName)}
+}
+
+func (e *Element) GetElementsByClassName(name string) spec.ElementCollection {
+	return getElementsByClassName(e.value, name)
+}
+
+func (e *Element) QuerySelector(query string) spec.Element {
+	el, _ := querySelector(e.value, query)
+	return el
+}
+
+func (e *Element) QuerySelectorAll(query string) spec.NodeList[spec.Element] {
+	list, _ := querySelectorAll(e.value, query)
+	return list
+}
+
+func (e *Element) QuerySelectorSequence(query string) iter.Seq[spec.Element] {
+	seq, _ := querySelectorEach(e.value, query)
+	return seq
+}
+
+func (e *Element) QuerySelectorErr(query string) (spec.Element, error) {
+	return querySelector(e.value, query)
+}
+
+func (e *Element) QuerySelectorAllErr(query string) (spec.NodeList[spec.Element], error) {
+	return querySelectorAll(e.value, query)
+}
+
+func (e *Element) QuerySelectorSequenceErr(query string) (iter.Seq[spec.Element], error) {
+	return querySelectorEach(e.value, query)
+}
+
+func (e *Element) HasChildNodes() bool                  { return hasChildNodes(e.value) }
+func (e *Element) ChildNodes() spec.NodeList[spec.Node] { return childNodes(e.value) }
+func (e *Element) FirstChild() spec.ChildNode           { return firstChild(e.value) }
+func (e *Element) LastChild() spec.ChildNode            { return lastChild(e.value) }
+
+func (e *Element) Descendants() iter.Seq[spec.Node]        { return descendants(e.value) }
+func (e *Element) ChildNodesSeq() iter.Seq[spec.ChildNode] { return childNodesSeq(e.value) }
+func (e *Element) ChildrenSeq() iter.Seq[spec.Element]     { return childrenSeq(e.value) }
+
+func (e *Element) InsertBefore(node spec.Node, child spec.ChildNode) spec.Node {
+	return insertBefore(e.value, node, child)
+}
+
+func (e *Element) AppendChild(node spec.Node) spec.Node { return appendChild(e.value, node) }
+
+func (e *Element) ReplaceChild(node spec.Node, child spec.ChildNode) spec.ChildNode {
+	return replaceChild(e.value, node, child)
+}
+
+func (e *Element) RemoveChild(node spec.ChildNode) spec.ChildNode { return removeChild(e.value, node) }
+
+func (e *Element) TagName() string      { return e.value.Get("tagName").String() }
+func (e *Element) NamespaceURI() string { return nullableString(e.value.Get("namespaceURI")) }
+func (e *Element) Prefix() string       { return nullableString(e.value.Get("prefix")) }
+func (e *Element) LocalName() string    { return e.value.Get("localName").String() }
+func (e *Element) ID() string           { return e.value.Get("id").String() }
+func (e *Element) ClassName() string    { return e.value.Get("className").String() }
+
+func (e *Element) ClassList() spec.DOMTokenList { return tokenList(e.value.Get("classList")) }
+func (e *Element) Dataset() spec.DOMStringMap   { return &DOMStringMap{value: e.value.Get("dataset")} }
+
+func (e *Element) Style() spec.CSSStyleDeclaration {
+	return &CSSStyleDeclaration{value: e.value.Get("style")}
+}
+
+func (e *Element) GetAttribute(name string) string {
+	return nullableString(e.value.Call("getAttribute", name))
+}
+func (e *Element) SetAttribute(name, value string) { e.value.Call("setAttribute", name, value) }
+
+func (e *Element) RemoveAttribute(name string) { e.value.Call("removeAttribute", name) }
+func (e *Element) ToggleAttribute(name string) bool {
+	return e.value.Call("toggleAttribute", name).Bool()
+}
+func (e *Element) HasAttribute(name string) bool { return e.value.Call("hasAttribute", name).Bool() }
+
+func (e *Element) GetAttributeNS(namespace, localName string) string {
+	return nullableString(e.value.Call("getAttributeNS", nullableNamespace(namespace), localName))
+}
+
+func (e *Element) SetAttributeNS(namespace, qualifiedName, value string) error {
+	return catch(func() { e.value.Call("setAttributeNS", nullableNamespace(namespace), qualifiedName, value) })
+}
+
+func (e *Element) RemoveAttributeNS(namespace, localName string) {
+	e.value.Call("removeAttributeNS", nullableNamespace(namespace), localName)
+}
+
+func (e *Element) HasAttributeNS(namespace, localName string) bool {
+	return e.value.Call("hasAttributeNS", nullableNamespace(namespace), localName).Bool()
+}
+
+func (e *Element) Attributes() spec.NamedNodeMap {
+	return namedNodeMap{value: e.value.Get("attributes")}
+}
+func (e *Element) HasAttributes() bool { return e.value.Call("hasAttributes").Bool() }
+
+func (e *Element) GetAttributeNames() []string {
+	names := e.value.Call("getAttributeNames")
+	result := make([]string, names.Length())
+	for i := range result {
+		result[i] = names.Index(i).String()
+	}
+	return result
+}
+
+func (e *Element) GetAttributeNode(name string) spec.Attr {
+	return newAttr(e.value.Call("getAttributeNode", name))
+}
+
+func (e *Element) SetAttributeNode(attr spec.Attr) (spec.Attr, error) {
+	return callAttr(e.value, "setAttributeNode", JSValue(attr))
+}
+
+func (e *Element) RemoveAttributeNode(attr spec.Attr) (spec.Attr, error) {
+	return callAttr(e.value, "removeAttributeNode", JSValue(attr))
+}
+func (e *Element) Closest(selector string) spec.Element {
+	el, _ := closest(e.value, selector)
+	return el
+}
+
+func (e *Element) Matches(selector string) bool {
+	ok, _ := matches(e.value, selector)
+	return ok
+}
+
+func (e *Element) ClosestErr(selector string) (spec.Element, error) {
+	return closest(e.value, selector)
+}
+func (e *Element) MatchesErr(selector string) (bool, error) { return matches(e.value, selector) }
+
+func (e *Element) InsertAdjacentHTML(position spec.InsertPosition, text string) error {
+	return catch(func() { e.value.Call("insertAdjacentHTML", string(position), text) })
+}
+
+// InsertAdjacentElement returns an error where the browser returns null
+// because the element has no parent.
+func (e *Element) InsertAdjacentElement(position spec.InsertPosition, element spec.Element) (spec.Element, error) {
+	var result js.Value
+	if err := catch(func() { result = e.value.Call("insertAdjacentElement", string(position), JSValue(element)) }); err != nil {
+		return nil, err
+	}
+	if result.IsNull() {
+		return nil, fmt.Errorf("%w: %q requires a parent node", spec.ErrNoModificationAllowed, position)
+	}
+	return element, nil
+}
+
+// InsertAdjacentText returns an error where the browser does nothing
+// because the element has no parent.
+func (e *Element) InsertAdjacentText(position spec.InsertPosition, data string) error {
+	switch strings.ToLower(string(position)) {
+	case string(spec.InsertBeforeBegin), string(spec.InsertAfterEnd):
+		if e.value.Get("parentNode").IsNull() {
+			return fmt.Errorf("%w: %q requires a parent node", spec.ErrNoModificationAllowed, position)
+		}
+	}
+	return catch(func() { e.value.Call("insertAdjacentText", string(position), data) })
+}
+
+func (e *Element) SetInnerHTML(s string) error { return setProperty(e.value, "innerHTML", s) }
+func (e *Element) InnerHTML() string           { return e.value.Get("innerHTML").String() }
+func (e *Element) SetOuterHTML(s string) error { return setProperty(e.value, "outerHTML", s) }
+
+func (e *Element) SetHTMLUnsafe(s string) error {
+	return catch(func() { e.value.Call("setHTMLUnsafe", s) })
+}
+
+func (e *Element) GetHTML(options spec.GetHTMLOptions) string {
+	return e.value.Call("getHTML", map[string]any{
+		"serializableShadowRoots": options.SerializableShadowRoots,
+	}).String()
+}
+
+func (e *Element) OuterHTML() string { return e.value.Get("outerHTML").String() }
+
+type Text struct {
+	value js.Value
+}
+
+func newTextNode(v js.Value) spec.Text {
+	if v.IsNull() {
+		return nil
+	}
+	return &Text{value: v}
+}
+
+func (t *Text) NodeType() spec.NodeType          { return nodeType(t.value) }
+func (t *Text) CloneNode(deep bool) spec.Node    { return cloneNode(t.value, deep) }
+func (t *Text) IsSameNode(other spec.Node) bool  { return isSameNode(t.value, other) }
+func (t *Text) IsEqualNode(other spec.Node) bool { return isEqualNode(t.value, other) }
+func (t *Text) GetRootNode() spec.Node           { return getRootNode(t.value) }
+func (t *Text) TextContent() string              { return textContent(t.value) }
+func (t *Text) Length() int                      { return t.value.Length() }
+
+func (t *Text) LookupNamespaceURI(prefix string) string {
+	return lookupNamespaceURI(t.value, prefix)
+}
+func (t *Text) LookupPrefix(namespace string) string { return lookupPrefix(t.value, namespace) }
+
+func (t *Text) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
+	return compareDocumentPosition(t.value, other)
+}
+
+func (t *Text) IsConnected() bool               { return isConnected(t.value) }
+func (t *Text) OwnerDocument() spec.Document    { return ownerDocument(t.value) }
+func (t *Text) ParentNode() spec.Node           { return parentNode(t.value) }
+func (t *Text) ParentElement() spec.Element     { return parentElement(t.value) }
+func (t *Text) PreviousSibling() spec.ChildNode { return previousSibling(t.value) }
+func (t *Text) NextSibling() spec.ChildNode     { return nextSibling(t.value) }
+
+func (t *Text) Ancestors() iter.Seq[spec.Node]              { return ancestors(t.value) }
+func (t *Text) FollowingSiblings() iter.Seq[spec.ChildNode] { return followingSiblings(t.value) }
+func (t *Text) PrecedingSiblings() iter.Seq[spec.ChildNode] { return precedingSiblings(t.value) }
+
+func (t *Text) AddEventListener(eventType string, listener spec.EventListener, options spec.AddEventListenerOptions) {
+	addEventListener(t.value, eventType, listener, options)
+}
+
+func (t *Text) RemoveEventListener(eventType string, listener spec.EventListener, options spec.EventListenerOptions) {
+	removeEventListener(t.value, eventType, listener, options)
+}
+
+func (t *Text) DispatchEvent(event spec.Event) (bool, error) { return dispatchEvent(t.value, event) }
+
+func (t *Text) Before(nodes ...spec.Node)      { t.value.Call("before", valueArray(nodes)...) }
+func (t *Text) After(nodes ...spec.Node)       { t.value.Call("after", valueArray(nodes)...) }
+func (t *Text) ReplaceWith(nodes ...spec.Node) { t.value.Call("replaceWith", valueArray(nodes)...) }
+func (t *Text) Remove()                        { t.value.Call("remove") }
+
+func (t *Text) SplitText(offset int) (spec.Text, error) {
+	var result js.Value
+	if err := catch(func() { result = t.value.Call("splitText", offset) }); err != nil {
+		return nil, err
+	}
+	return newTextNode(result), nil
+}
+
+func (t *Text) WholeText() string { return t.value.Get("wholeText").String() }
+
+func (t *Text) Data() string     { return t.value.Get("data").String() }
+func (t *Text) SetData(s string) { t.value.Set("data", s) }
+
+func (t *Text) SubstringData(offset, count int) (string, error) {
+	return substringData(t.value, offset, count)
+}
+
+func (t *Text) AppendData(data string) { t.value.Call("appendData", data) }
+
+func (t *Text) InsertData(offset int, data string) error {
+	return catch(func() { t.value.Call("insertData", offset, data) })
+}
+
+func (t *Text) DeleteData(offset, count int) error {
+	return catch(func() { t.value.Call("deleteData", offset, count) })
+}
+
+func (t *Text) ReplaceData(offset, count int, data string) error {
+	return catch(func() { t.value.Call("replaceData", offset, count, data) })
+}
+
+type DocumentType struct {
+	value js.Value
+}
+
+func newDocumentType(v js.Value) spec.DocumentType {
+	if v.IsNull() {
+		return nil
+	}
+	return &DocumentType{value: v}
+}
+
+func (d *DocumentType) NodeType() spec.NodeType          { return nodeType(d.value) }
+func (d *DocumentType) CloneNode(deep bool) spec.Node    { return cloneNode(d.value, deep) }
+func (d *DocumentType) IsSameNode(other spec.Node) bool  { return isSameNode(d.value, other) }
+func (d *DocumentType) IsEqualNode(other spec.Node) bool { return isEqualNode(d.value, other) }
+func (d *DocumentType) GetRootNode() spec.Node           { return getRootNode(d.value) }
+func (d *DocumentType) TextContent() string              { return "" }
+func (d *DocumentType) Length() int                      { return 0 }
+
+func (d *DocumentType) LookupNamespaceURI(prefix string) string {
+	return lookupNamespaceURI(d.value, prefix)
+}
+func (d *DocumentType) LookupPrefix(namespace string) string { return lookupPrefix(d.value, namespace) }
+
+func (d *DocumentType) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
+	return compareDocumentPosition(d.value, other)
+}
+
+func (d *DocumentType) IsConnected() bool               { return isConnected(d.value) }
+func (d *DocumentType) OwnerDocument() spec.Document    { return ownerDocument(d.value) }
+func (d *DocumentType) ParentNode() spec.Node           { return parentNode(d.value) }
+func (d *DocumentType) ParentElement() spec.Element     { return parentElement(d.value) }
+func (d *DocumentType) PreviousSibling() spec.ChildNode { return previousSibling(d.value) }
+func (d *DocumentType) NextSibling() spec.ChildNode     { return nextSibling(d.value) }
+
+func (d *DocumentType) Ancestors() iter.Seq[spec.Node] { return ancestors(d.value) }
+func (d *DocumentType) FollowingSiblings() iter.Seq[spec.ChildNode] {
+	return followingSiblings(d.value)
+}
+func (d *DocumentType) PrecedingSiblings() iter.Seq[spec.ChildNode] {
+	return precedingSiblings(d.value)
+}
+
+func (d *DocumentType) Before(nodes ...spec.Node) { d.value.Call("before", valueArray(nodes)...) }
+func (d *DocumentType) After(nodes ...spec.Node)  { d.value.Call("after", valueArray(nodes)...) }
+func (d *DocumentType) ReplaceWith(nodes ...spec.Node) {
+	d.value.Call("replaceWith", valueArray(nodes)...)
 }
+func (d *DocumentType) Remove() { d.value.Call("remove") }
 
-func (d *Document) CreateElement(localName string) spec.Element {
-	return createElement(d.value, localName)
+func (d *DocumentType) Name() string     { return d.value.Get("name").String() }
+func (d *DocumentType) PublicID() string { return d.value.Get("publicId").String() }
+func (d *DocumentType) SystemID() string { return d.value.Get("systemId").String() }
+
+type Attr struct {
+	value js.Value
 }
 
-func (d *Document) CreateElementIs(localName, is string) spec.Element {
-	return createElementIs(d.value, localName, is)
+func newAttr(v js.Value) spec.Attr {
+	if v.IsNull() {
+		return nil
+	}
+	return &Attr{value: v}
 }
 
-func (d *Document) CreateTextNode(text string) spec.Text {
-	return createTextNode(d.value, text)
+func (a *Attr) NodeType() spec.NodeType          { return nodeType(a.value) }
+func (a *Attr) CloneNode(deep bool) spec.Node    { return cloneNode(a.value, deep) }
+func (a *Attr) IsSameNode(other spec.Node) bool  { return isSameNode(a.value, other) }
+func (a *Attr) IsEqualNode(other spec.Node) bool { return isEqualNode(a.value, other) }
+func (a *Attr) GetRootNode() spec.Node           { return getRootNode(a.value) }
+func (a *Attr) TextContent() string              { return textContent(a.value) }
+
+func (a *Attr) LookupNamespaceURI(prefix string) string {
+	return lookupNamespaceURI(a.value, prefix)
 }
+func (a *Attr) LookupPrefix(namespace string) string { return lookupPrefix(a.value, namespace) }
 
-type DocumentFragment struct {
-	value js.Value
+func (a *Attr) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
+	return compareDocumentPosition(a.value, other)
 }
 
-func (d *DocumentFragment) NodeType() spec.NodeType         { return nodeType(d.value) }
-func (d *DocumentFragment) CloneNode(deep bool) spec.Node   { return cloneNode(d.value, deep) }
-func (d *DocumentFragment) IsSameNode(other spec.Node) bool { return isSameNode(d.value, other) }
-func (d *DocumentFragment) TextContent() string             { return textContent(d.value) }
+func (a *Attr) Name() string                 { return a.value.Get("name").String() }
+func (a *Attr) LocalName() string            { return a.value.Get("localName").String() }
+func (a *Attr) NamespaceURI() string         { return nullableString(a.value.Get("namespaceURI")) }
+func (a *Attr) Prefix() string               { return nullableString(a.value.Get("prefix")) }
+func (a *Attr) Value() string                { return a.value.Get("value").String() }
+func (a *Attr) SetValue(value string)        { a.value.Set("value", value) }
+func (a *Attr) OwnerElement() spec.Element   { return newElement(a.value.Get("ownerElement")) }
+func (a *Attr) OwnerDocument() spec.Document { return ownerDocument(a.value) }
 
-func (d *DocumentFragment) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
-	return compareDocumentPosition(d.value, other)
+type namedNodeMap struct {
+	value js.Value
 }
 
-func (d *DocumentFragment) Children() spec.ElementCollection { return children(d.value) }
-func (d *DocumentFragment) FirstElementChild() spec.Element  { return firstElementChild(d.value) }
-func (d *DocumentFragment) LastElementChild() spec.Element   { return lastElementChild(d.value) }
-func (d *DocumentFragment) ChildElementCount() int           { return childElementCount(d.value) }
+func (m namedNodeMap) Length() int { return m.value.Length() }
 
-func (d *DocumentFragment) Append(nodes ...spec.Node)          { appendNodes(d.value, nodes) }
-func (d *DocumentFragment) Prepend(nodes ...spec.Node)         { prependNodes(d.value, nodes) }
-func (d *DocumentFragment) ReplaceChildren(nodes ...spec.Node) { replaceChildrenNodes(d.value, nodes) }
+func (m namedNodeMap) Item(index int) spec.Attr { return newAttr(m.value.Call("item", index)) }
 
-func (d *DocumentFragment) QuerySelector(query string) spec.Element {
-	return querySelector(d.value, query)
+func (m namedNodeMap) GetNamedItem(qualifiedName string) spec.Attr {
+	return newAttr(m.value.Call("getNamedItem", qualifiedName))
 }
 
-func (d *DocumentFragment) QuerySelectorAll(query string) spec.NodeList[spec.Element] {
-	return querySelectorAll(d.value, query)
+func (m namedNodeMap) SetNamedItem(attr spec.Attr) (spec.Attr, error) {
+	return callAttr(m.value, "setNamedItem", JSValue(attr))
 }
 
-func (d *DocumentFragment) QuerySelectorSequence(query string) iter.Seq[spec.Element] {
-	return querySelectorEach(d.value, query)
+func (m namedNodeMap) RemoveNamedItem(qualifiedName string) (spec.Attr, error) {
+	return callAttr(m.value, "removeNamedItem", qualifiedName)
 }
 
-type Element struct {
+func callAttr(receiver js.Value, method string, args ...any) (spec.Attr, error) {
+	var result js.Value
+	if err := catch(func() { result = receiver.Call(method, args...) }); err != nil {
+		return nil, err
+	}
+	return newAttr(result), nil
+}
+
+type Comment struct {
 	value js.Value
 }
 
-func newElement(value js.Value) spec.Element {
-	if value.IsNull() {
+func newComment(v js.Value) spec.Comment {
+	if v.IsNull() {
 		return nil
 	}
-	return &Element{value: value}
+	return &Comment{value: v}
 }
 
-func (e *Element) NodeType() spec.NodeType         { return nodeType(e.value) }
-func (e *Element) CloneNode(deep bool) spec.Node   { return cloneNode(e.value, deep) }
-func (e *Element) IsSameNode(other spec.Node) bool { return isSameNode(e.value, other) }
-func (e *Element) TextContent() string             { return textContent(e.value) }
-func (e *Element) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
-	return compareDocumentPosition(e.value, other)
+func (c *Comment) NodeType() spec.NodeType          { return nodeType(c.value) }
+func (c *Comment) CloneNode(deep bool) spec.Node    { return cloneNode(c.value, deep) }
+func (c *Comment) IsSameNode(other spec.Node) bool  { return isSameNode(c.value, other) }
+func (c *Comment) IsEqualNode(other spec.Node) bool { return isEqualNode(c.value, other) }
+func (c *Comment) GetRootNode() spec.Node           { return getRootNode(c.value) }
+func (c *Comment) TextContent() string              { return textContent(c.value) }
+func (c *Comment) Length() int                      { return c.value.Length() }
+
+func (c *Comment) LookupNamespaceURI(prefix string) string {
+	return lookupNamespaceURI(c.value, prefix)
 }
+func (c *Comment) LookupPrefix(namespace string) string { return lookupPrefix(c.value, namespace) }
 
-func (e *Element) Length() int { return e.Length() }
+func (c *Comment) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
+	return compareDocumentPosition(c.value, other)
+}
 
-func (e *Element) IsConnected() bool               { return isConnected(e.value) }
-func (e *Element) OwnerDocument() spec.Document    { return ownerDocument(e.value) }
-func (e *Element) ParentNode() spec.Node           { return parentNode(e.value) }
-func (e *Element) ParentElement() spec.Element     { return parentElement(e.value) }
-func (e *Element) PreviousSibling() spec.ChildNode { return previousSibling(e.value) }
-func (e *Element) NextSibling() spec.ChildNode     { return nextSibling(e.value) }
+func (c *Comment) IsConnected() bool               { return isConnected(c.value) }
+func (c *Comment) OwnerDocument() spec.Document    { return ownerDocument(c.value) }
+func (c *Comment) ParentNode() spec.Node           { return parentNode(c.value) }
+func (c *Comment) ParentElement() spec.Element     { return parentElement(c.value) }
+func (c *Comment) PreviousSibling() spec.ChildNode { return previousSibling(c.value) }
+func (c *Comment) NextSibling() spec.ChildNode     { return nextSibling(c.value) }
 
-func (e *Element) Children() spec.ElementCollection { return children(e.value) }
-func (e *Element) FirstElementChild() spec.Element  { return firstElementChild(e.value) }
-func (e *Element) LastElementChild() spec.Element   { return lastElementChild(e.value) }
-func (e *Element) ChildElementCount() int           { return childElementCount(e.value) }
+func (c *Comment) Ancestors() iter.Seq[spec.Node]              { return ancestors(c.value) }
+func (c *Comment) FollowingSiblings() iter.Seq[spec.ChildNode] { return followingSiblings(c.value) }
+func (c *Comment) PrecedingSiblings() iter.Seq[spec.ChildNode] { return precedingSiblings(c.value) }
 
-func (e *Element) Prepend(nodes ...spec.Node) { appendNodes(e.value, nodes) }
+func (c *Comment) Before(nodes ...spec.Node)      { c.value.Call("before", valueArray(nodes)...) }
+func (c *Comment) After(nodes ...spec.Node)       { c.value.Call("after", valueArray(nodes)...) }
+func (c *Comment) ReplaceWith(nodes ...spec.Node) { c.value.Call("replaceWith", valueArray(nodes)...) }
+func (c *Comment) Remove()                        { c.value.Call("remove") }
 
-func (e *Element) Append(nodes ...spec.Node) { prependNodes(e.value, nodes) }
+func (c *Comment) Data() string     { return c.value.Get("data").String() }
+func (c *Comment) SetData(s string) { c.value.Set("data", s) }
 
-func (e *Element) ReplaceChildren(nodes ...spec.Node) { replaceChildrenNodes(e.value, nodes) }
+func (c *Comment) SubstringData(offset, count int) (string, error) {
+	return substringData(c.value, offset, count)
+}
 
-func (e *Element) Contains(other spec.Node) bool { return contains(e.value, other) }
+func (c *Comment) AppendData(data string) { c.value.Call("appendData", data) }
 
-func (e *Element) GetElementsByTagName(name string) spec.ElementCollection {
-	return getElementsByTagName(e.value, name)
+func (c *Comment) InsertData(offset int, data string) error {
+	return catch(func() { c.value.Call("insertData", offset, data) })
 }
 
-func (e *Element) GetElementsByClassName(name string) spec.ElementCollection {
-	return getElementsByClassName(e.value, name)
+func (c *Comment) DeleteData(offset, count int) error {
+	return catch(func() { c.value.Call("deleteData", offset, count) })
 }
 
-func (e *Element) QuerySelector(query string) spec.Element {
-	return querySelector(e.value, query)
+func (c *Comment) ReplaceData(offset, count int, data string) error {
+	return catch(func() { c.value.Call("replaceData", offset, count, data) })
 }
 
-func (e *Element) QuerySelectorAll(query string) spec.NodeList[spec.Element] {
-	return querySelectorAll(e.value, query)
+// Event wraps a JavaScript Event.
+type Event struct {
+	value js.Value
 }
 
-func (e *Element) QuerySelectorSequence(query string) iter.Seq[spec.Element] {
-	return querySelectorEach(e.value, query)
+// NewEvent is based on https://dom.spec.whatwg.org/#dom-event-event
+func NewEvent(eventType string, init spec.EventInit) *Event {
+	return &Event{value: eventClass.New(eventType, js.ValueOf(eventInit(init)))}
 }
 
-func (e *Element) HasChildNodes() bool { return e.value.Bool() }
+func eventInit(init spec.EventInit) map[string]any {
+	return map[string]any{
+		"bubbles":    init.Bubbles,
+		"cancelable": init.Cancelable,
+		"composed":   init.Composed,
+	}
+}
 
-func (e *Element) ChildNodes() spec.NodeList[spec.Node] {
-	nodes := e.value.Get("childNodes")
-	if nodes.IsNull() {
-		return nil
+func (e *Event) Type() string                    { return e.value.Get("type").String() }
+func (e *Event) Target() spec.EventTarget        { return eventTarget(e.value.Get("target")) }
+func (e *Event) CurrentTarget() spec.EventTarget { return eventTarget(e.value.Get("currentTarget")) }
+func (e *Event) EventPhase() spec.EventPhase     { return spec.EventPhase(e.value.Get("eventPhase").Int()) }
+func (e *Event) Bubbles() bool                   { return e.value.Get("bubbles").Bool() }
+func (e *Event) Cancelable() bool                { return e.value.Get("cancelable").Bool() }
+func (e *Event) Composed() bool                  { return e.value.Get("composed").Bool() }
+func (e *Event) IsTrusted() bool                 { return e.value.Get("isTrusted").Bool() }
+func (e *Event) StopPropagation()                { e.value.Call("stopPropagation") }
+func (e *Event) StopImmediatePropagation()       { e.value.Call("stopImmediatePropagation") }
+func (e *Event) PreventDefault()                 { e.value.Call("preventDefault") }
+func (e *Event) DefaultPrevented() bool          { return e.value.Get("defaultPrevented").Bool() }
+
+// CustomEvent wraps a JavaScript CustomEvent.
+type CustomEvent struct {
+	Event
+	detail any
+}
+
+// NewCustomEvent is based on https://dom.spec.whatwg.org/#dom-customevent-customevent.
+// The JavaScript detail is only set when init.Detail is a js.Value, but
+// listeners called by DispatchEvent get init.Detail from Detail.
+func NewCustomEvent(eventType string, init spec.CustomEventInit) *CustomEvent {
+	options := eventInit(init.EventInit)
+	if detail, ok := init.Detail.(js.Value); ok {
+		options["detail"] = detail
+	}
+	return &CustomEvent{
+		Event:  Event{value: customEventClass.New(eventType, js.ValueOf(options))},
+		detail: init.Detail,
 	}
-	return nodeList{value: nodes}
 }
 
-func (e *Element) FirstChild() spec.ChildNode { return newChildNode(e.value.Get("firstChild")) }
-func (e *Element) LastChild() spec.ChildNode  { return newChildNode(e.value.Get("lastChild")) }
+// Detail returns the Go value given to NewCustomEvent, or the JavaScript
+// detail as a js.Value for events created elsewhere.
+func (e *CustomEvent) Detail() any { return e.detail }
+
+// dispatchedCustomEvents holds the CustomEvents being dispatched by
+// DispatchEvent, so listeners can get their Go detail values.
+var dispatchedCustomEvents struct {
+	mu     sync.Mutex
+	events []*CustomEvent
+}
 
-func (e *Element) InsertBefore(node, child spec.ChildNode) spec.ChildNode {
-	return newChildNode(e.value.Call("insertBefore", node, child))
+func newEvent(value js.Value) spec.Event {
+	if !value.InstanceOf(customEventClass) {
+		return &Event{value: value}
+	}
+	dispatchedCustomEvents.mu.Lock()
+	defer dispatchedCustomEvents.mu.Unlock()
+	for i := len(dispatchedCustomEvents.events) - 1; i >= 0; i-- {
+		if e := dispatchedCustomEvents.events[i]; e.value.Equal(value) {
+			return e
+		}
+	}
+	return &CustomEvent{Event: Event{value: value}, detail: value.Get("detail")}
 }
 
-func (e *Element) AppendChild(node spec.ChildNode) spec.ChildNode {
-	return newChildNode(e.value.Call("appendChild", JSValue(node)))
+func eventTarget(value js.Value) spec.EventTarget {
+	if value.IsNull() {
+		return nil
+	}
+	target, _ := NewNode(value).(spec.EventTarget)
+	return target
 }
 
-func (e *Element) ReplaceChild(node, child spec.ChildNode) spec.ChildNode {
-	return newChildNode(e.value.Call("replaceChild", JSValue(node), JSValue(child)))
+// eventListeners holds the JavaScript function of each added listener by id,
+// so it can be found by RemoveEventListener and released. The ids of a
+// target's listeners are kept in a Set that listenerIDs maps the target to.
+// It is a WeakMap and listenerFinalizer releases the functions once the
+// target is garbage collected, so the listeners live as long as the target.
+var eventListeners struct {
+	mu     sync.Mutex
+	nextID int
+	m      map[int]*eventListener
 }
 
-func (e *Element) RemoveChild(node spec.ChildNode) spec.ChildNode {
-	return newChildNode(e.value.Call("removeChild", JSValue(node)))
+type eventListener struct {
+	eventType string
+	listener  spec.EventListener
+	capture   bool
+	fn        js.Func
+	stop      func() bool
 }
 
-func (e *Element) TagName() string   { return e.value.Get("tagName").String() }
-func (e *Element) ID() string        { return e.value.Get("id").String() }
-func (e *Element) ClassName() string { return e.value.Get("className").String() }
+var (
+	listenerIDs       = js.Global().Get("WeakMap").New()
+	listenerFinalizer = js.Global().Get("FinalizationRegistry").New(js.FuncOf(func(_ js.Value, args []js.Value) any {
+		for _, id := range eventListenerIDs(args[0]) {
+			releaseEventListener(args[0], id)
+		}
+		return nil
+	}))
+)
 
-func (e *Element) GetAttribute(name string) string {
-	return e.value.Call("getAttribute", name).String()
+// targetListenerIDs returns the Set of listener ids of target. It is
+// undefined when create is false and no listener was added to target.
+func targetListenerIDs(target js.Value, create bool) js.Value {
+	ids := listenerIDs.Call("get", target)
+	if ids.IsUndefined() && create {
+		ids = js.Global().Get("Set").New()
+		listenerIDs.Call("set", target, ids)
+		listenerFinalizer.Call("register", target, ids)
+	}
+	return ids
 }
-func (e *Element) SetAttribute(name, value string) { e.value.Call("setAttribute", name, value) }
 
-func (e *Element) RemoveAttribute(name string) { e.value.Call("removeAttribute", name) }
-func (e *Element) ToggleAttribute(name string) bool {
-	return e.value.Call("toggleAttribute", name).Bool()
+func eventListenerIDs(ids js.Value) []int {
+	array := js.Global().Get("Array").Call("from", ids)
+	result := make([]int, array.Length())
+	for i := range result {
+		result[i] = array.Index(i).Int()
+	}
+	return result
 }
-func (e *Element) HasAttribute(name string) bool { return e.value.Call("hasAttribute", name).Bool() }
-func (e *Element) Closest(selector string) spec.Element {
-	return newElement(e.value.Call("closest", selector))
+
+// findEventListener returns the id of the listener in ids that matches or 0.
+// eventListeners.mu must be held.
+func findEventListener(ids js.Value, eventType string, listener spec.EventListener, capture bool) int {
+	for _, id := range eventListenerIDs(ids) {
+		l := eventListeners.m[id]
+		if l != nil && l.eventType == eventType && l.listener == listener && l.capture == capture {
+			return id
+		}
+	}
+	return 0
 }
-func (e *Element) Matches(selector string) bool { return e.value.Call("matches", selector).Bool() }
 
-func (e *Element) SetInnerHTML(s string) { e.value.Set("innerHTML", s) }
-func (e *Element) InnerHTML() string     { return e.value.Get("innerHTML").String() }
-func (e *Element) SetOuterHTML(s string) { e.value.Set("innerHTML", s) }
-func (e *Element) OuterHTML() string     { return e.value.Get("outerHTML").String() }
+// releaseEventListener forgets the listener with id and releases its
+// function. It does nothing if the listener was already released.
+func releaseEventListener(ids js.Value, id int) {
+	eventListeners.mu.Lock()
+	l, ok := eventListeners.m[id]
+	delete(eventListeners.m, id)
+	eventListeners.mu.Unlock()
+	if !ok {
+		return
+	}
+	ids.Call("delete", id)
+	if l.stop != nil {
+		l.stop()
+	}
+	l.fn.Release()
+}
 
-type Text struct {
+// addEventListener passes options.Signal to the browser as an AbortSignal.
+// It is aborted from another goroutine, so the listener is removed shortly
+// after the context is done rather than immediately.
+func addEventListener(receiver js.Value, eventType string, listener spec.EventListener, options spec.AddEventListenerOptions) {
+	if listener == nil || (options.Signal != nil && options.Signal.Err() != nil) {
+		return
+	}
+	ids := targetListenerIDs(receiver, true)
+	eventListeners.mu.Lock()
+	if findEventListener(ids, eventType, listener, options.Capture) != 0 {
+		eventListeners.mu.Unlock()
+		return
+	}
+	eventListeners.nextID++
+	id := eventListeners.nextID
+	l := &eventListener{eventType: eventType, listener: listener, capture: options.Capture}
+	l.fn = js.FuncOf(func(_ js.Value, args []js.Value) any {
+		if options.Once {
+			defer releaseEventListener(ids, id)
+		}
+		listener.HandleEvent(newEvent(args[0]))
+		return nil
+	})
+	if eventListeners.m == nil {
+		eventListeners.m = make(map[int]*eventListener)
+	}
+	eventListeners.m[id] = l
+	ids.Call("add", id)
+	init := map[string]any{
+		"capture": options.Capture,
+		"once":    options.Once,
+		"passive": options.Passive,
+	}
+	if options.Signal != nil {
+		controller := abortControllerClass.New()
+		init["signal"] = controller.Get("signal")
+		l.stop = context.AfterFunc(options.Signal, func() {
+			controller.Call("abort")
+			releaseEventListener(ids, id)
+		})
+	}
+	eventListeners.mu.Unlock()
+	receiver.Call("addEventListener", eventType, l.fn, js.ValueOf(init))
+}
+
+func removeEventListener(receiver js.Value, eventType string, listener spec.EventListener, options spec.EventListenerOptions) {
+	ids := targetListenerIDs(receiver, false)
+	if ids.IsUndefined() {
+		return
+	}
+	eventListeners.mu.Lock()
+	id := findEventListener(ids, eventType, listener, options.Capture)
+	l := eventListeners.m[id]
+	eventListeners.mu.Unlock()
+	if l == nil {
+		return
+	}
+	receiver.Call("removeEventListener", eventType, l.fn, js.ValueOf(map[string]any{"capture": options.Capture}))
+	releaseEventListener(ids, id)
+}
+
+func dispatchEvent(receiver js.Value, event spec.Event) (bool, error) {
+	switch e := event.(type) {
+	case *Event:
+	case *CustomEvent:
+		dispatchedCustomEvents.mu.Lock()
+		dispatchedCustomEvents.events = append(dispatchedCustomEvents.events, e)
+		dispatchedCustomEvents.mu.Unlock()
+		defer func() {
+			dispatchedCustomEvents.mu.Lock()
+			defer dispatchedCustomEvents.mu.Unlock()
+			events := dispatchedCustomEvents.events
+			for i := len(events) - 1; i >= 0; i-- {
+				if events[i] == e {
+					dispatchedCustomEvents.events = append(events[:i], events[i+1:]...)
+					break
+				}
+			}
+		}()
+	default:
+		return false, fmt.Errorf("%w: event %T was not created by this package", spec.ErrNotSupported, event)
+	}
+	var ok bool
+	err := catch(func() { ok = receiver.Call("dispatchEvent", JSValue(event)).Bool() })
+	return ok, err
+}
+
+// MutationObserver wraps a JavaScript MutationObserver.
+type MutationObserver struct {
 	value js.Value
 }
 
-func newTextNode(v js.Value) spec.Text {
-	if v.IsNull() {
+// NewMutationObserver is based on https://dom.spec.whatwg.org/#dom-mutationobserver-mutationobserver
+// callback may be nil. The browser may call it for as long as the observed
+// nodes exist, so its JavaScript function is never released.
+func NewMutationObserver(callback spec.MutationCallback) *MutationObserver {
+	observer := new(MutationObserver)
+	fn := js.FuncOf(func(_ js.Value, args []js.Value) any {
+		if callback != nil {
+			callback(mutationRecords(args[0]), observer)
+		}
 		return nil
+	})
+	observer.value = mutationObserverClass.New(fn)
+	return observer
+}
+
+func (o *MutationObserver) Observe(target spec.Node, options spec.MutationObserverInit) error {
+	init := make(map[string]any)
+	for name, set := range map[string]bool{
+		"childList":             options.ChildList,
+		"attributes":            options.Attributes,
+		"characterData":         options.CharacterData,
+		"subtree":               options.Subtree,
+		"attributeOldValue":     options.AttributeOldValue,
+		"characterDataOldValue": options.CharacterDataOldValue,
+	} {
+		if set {
+			init[name] = true
+		}
 	}
-	return &Text{value: v}
+	if options.AttributeFilter != nil {
+		filter := make([]any, len(options.AttributeFilter))
+		for i, name := range options.AttributeFilter {
+			filter[i] = name
+		}
+		init["attributeFilter"] = filter
+	}
+	return catch(func() { o.value.Call("observe", JSValue(target), js.ValueOf(init)) })
 }
 
-func (t *Text) NodeType() spec.NodeType         { return nodeType(t.value) }
-func (t *Text) CloneNode(deep bool) spec.Node   { return cloneNode(t.value, deep) }
-func (t *Text) IsSameNode(other spec.Node) bool { return isSameNode(t.value, other) }
-func (t *Text) TextContent() string             { return textContent(t.value) }
-func (t *Text) Length() int                     { return t.value.Length() }
+func (o *MutationObserver) Disconnect() { o.value.Call("disconnect") }
 
-func (t *Text) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
-	return compareDocumentPosition(t.value, other)
+func (o *MutationObserver) TakeRecords() []spec.MutationRecord {
+	return mutationRecords(o.value.Call("takeRecords"))
 }
 
-func (t *Text) IsConnected() bool               { return isConnected(t.value) }
-func (t *Text) OwnerDocument() spec.Document    { return ownerDocument(t.value) }
-func (t *Text) ParentNode() spec.Node           { return parentNode(t.value) }
-func (t *Text) ParentElement() spec.Element     { return parentElement(t.value) }
-func (t *Text) PreviousSibling() spec.ChildNode { return previousSibling(t.value) }
-func (t *Text) NextSibling() spec.ChildNode     { return nextSibling(t.value) }
+func mutationRecords(array js.Value) []spec.MutationRecord {
+	records := make([]spec.MutationRecord, array.Length())
+	for i := range records {
+		r := array.Index(i)
+		records[i] = spec.MutationRecord{
+			Type:               spec.MutationRecordType(r.Get("type").String()),
+			Target:             NewNode(r.Get("target")),
+			AddedNodes:         nodeSlice(r.Get("addedNodes")),
+			RemovedNodes:       nodeSlice(r.Get("removedNodes")),
+			PreviousSibling:    NewNode(r.Get("previousSibling")),
+			NextSibling:        NewNode(r.Get("nextSibling")),
+			AttributeName:      nullableString(r.Get("attributeName")),
+			AttributeNamespace: nullableString(r.Get("attributeNamespace")),
+			OldValue:           nullableString(r.Get("oldValue")),
+		}
+	}
+	return records
+}
 
-func (t *Text) Data() string     { return t.value.Get("data").String() }
-func (t *Text) SetData(s string) { t.value.Set("data", s) }
+func nodeSlice(list js.Value) []spec.Node {
+	if list.Length() == 0 {
+		return nil
+	}
+	nodes := make([]spec.Node, list.Length())
+	for i := range nodes {
+		nodes[i] = NewNode(list.Index(i))
+	}
+	return nodes
+}
 
 var (
 	nodeClass             = js.Global().Get("Node")
 	textClass             = js.Global().Get("Text")
+	commentClass          = js.Global().Get("Comment")
+	documentTypeClass     = js.Global().Get("DocumentType")
+	attrClass             = js.Global().Get("Attr")
 	documentClass         = js.Global().Get("Document")
 	documentFragmentClass = js.Global().Get("DocumentFragment")
 	elementClass          = js.Global().Get("Element")
+	objectClass           = js.Global().Get("Object")
+	reflectObject         = js.Global().Get("Reflect")
+	staticRangeClass      = js.Global().Get("StaticRange")
+	eventClass            = js.Global().Get("Event")
+	customEventClass      = js.Global().Get("CustomEvent")
+	abortControllerClass  = js.Global().Get("AbortController")
+	mutationObserverClass = js.Global().Get("MutationObserver")
+
+	htmlTemplateElementClass = js.Global().Get("HTMLTemplateElement")
+	htmlAnchorElementClass   = js.Global().Get("HTMLAnchorElement")
+	htmlAreaElementClass     = js.Global().Get("HTMLAreaElement")
+	htmlLinkElementClass     = js.Global().Get("HTMLLinkElement")
 )
 
 func NewNode(value js.Value) spec.Node {
@@ -279,6 +1545,15 @@ func NewNode(value js.Value) spec.Node {
 	if value.InstanceOf(textClass) {
 		return newTextNode(value)
 	}
+	if value.InstanceOf(commentClass) {
+		return newComment(value)
+	}
+	if value.InstanceOf(documentTypeClass) {
+		return newDocumentType(value)
+	}
+	if value.InstanceOf(attrClass) {
+		return newAttr(value)
+	}
 	if value.InstanceOf(documentClass) {
 		return newDocument(value)
 	}
@@ -303,6 +1578,14 @@ func JSValue(node any) js.Value {
 	switch n := node.(type) {
 	case *Element:
 		return n.value
+	case *HTMLTemplateElement:
+		return n.value
+	case *HTMLAnchorElement:
+		return n.value
+	case *HTMLAreaElement:
+		return n.value
+	case *HTMLLinkElement:
+		return n.value
 	case *Node:
 		return n.value
 	case *Document:
@@ -311,6 +1594,20 @@ func JSValue(node any) js.Value {
 		return n.value
 	case *Text:
 		return n.value
+	case *Comment:
+		return n.value
+	case *DocumentType:
+		return n.value
+	case *Attr:
+		return n.value
+	case *Range:
+		return n.value
+	case *StaticRange:
+		return n.value
+	case *Event:
+		return n.value
+	case *CustomEvent:
+		return n.value
 	case js.Value:
 		return n
 	default:
@@ -318,6 +1615,79 @@ func JSValue(node any) js.Value {
 	}
 }
 
+// domExceptions maps DOMException names to the corresponding spec errors.
+var domExceptions = map[string]error{
+	spec.ErrHierarchyRequest.Error():      spec.ErrHierarchyRequest,
+	spec.ErrIndexSize.Error():             spec.ErrIndexSize,
+	spec.ErrInUseAttribute.Error():        spec.ErrInUseAttribute,
+	spec.ErrInvalidCharacter.Error():      spec.ErrInvalidCharacter,
+	spec.ErrInvalidNodeType.Error():       spec.ErrInvalidNodeType,
+	spec.ErrInvalidState.Error():          spec.ErrInvalidState,
+	spec.ErrNamespace.Error():             spec.ErrNamespace,
+	spec.ErrNoModificationAllowed.Error(): spec.ErrNoModificationAllowed,
+	spec.ErrNotFound.Error():              spec.ErrNotFound,
+	spec.ErrNotSupported.Error():          spec.ErrNotSupported,
+	spec.ErrSyntax.Error():                spec.ErrSyntax,
+	spec.ErrWrongDocument.Error():         spec.ErrWrongDocument,
+	spec.ErrType.Error():                  spec.ErrType,
+}
+
+// catch calls fn and converts a thrown DOMException or TypeError into an
+// error wrapping the matching spec error. Other JavaScript exceptions are
+// returned as js.Error and Go panics are re-raised.
+func catch(fn func()) (err error) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		jsErr, ok := r.(js.Error)
+		if !ok {
+			panic(r)
+		}
+		if e, ok := domExceptions[jsErr.Value.Get("name").String()]; ok {
+			err = fmt.Errorf("%w: %s", e, jsErr.Value.Get("message").String())
+			return
+		}
+		err = jsErr
+	}()
+	fn()
+	return nil
+}
+
+func substringData(receiver js.Value, offset, count int) (string, error) {
+	var result js.Value
+	if err := catch(func() { result = receiver.Call("substringData", offset, count) }); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// nullableString returns the empty string for a JavaScript null.
+func nullableString(v js.Value) string {
+	if v.IsNull() || v.IsUndefined() {
+		return ""
+	}
+	return v.String()
+}
+
+// nullableNamespace converts the empty string to null for namespace and
+// prefix arguments.
+func nullableNamespace(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func lookupNamespaceURI(receiver js.Value, prefix string) string {
+	return nullableString(receiver.Call("lookupNamespaceURI", nullableNamespace(prefix)))
+}
+
+func lookupPrefix(receiver js.Value, namespace string) string {
+	return nullableString(receiver.Call("lookupPrefix", nullableNamespace(namespace)))
+}
+
 func valueArray(in []spec.Node) []any {
 	out := make([]any, 0, len(in))
 	for _, n := range in {
@@ -338,6 +1708,14 @@ func isSameNode(receiver js.Value, other spec.Node) bool {
 	return receiver.Call("isSameNode", JSValue(other)).Bool()
 }
 
+func isEqualNode(receiver js.Value, other spec.Node) bool {
+	return receiver.Call("isEqualNode", JSValue(other)).Bool()
+}
+
+func getRootNode(receiver js.Value) spec.Node {
+	return NewNode(receiver.Call("getRootNode"))
+}
+
 func textContent(receiver js.Value) string {
 	return receiver.Get("textContent").String()
 }
@@ -372,33 +1750,85 @@ func getElementsByClassName(receiver js.Value, name string) spec.ElementCollecti
 	return htmlCollection{value: receiver.Call("getElementsByClassName", name)}
 }
 
-func querySelector(receiver js.Value, query string) spec.Element {
-	return newElement(receiver.Call("querySelector", query))
+func querySelector(receiver js.Value, query string) (spec.Element, error) {
+	var result js.Value
+	if err := catch(func() { result = receiver.Call("querySelector", query) }); err != nil {
+		return nil, selectorError(query, err)
+	}
+	return newElement(result), nil
 }
 
+// elementList wraps a NodeList of elements. The zero value is an empty list.
 type elementList struct {
 	value js.Value
 }
 
-func (e elementList) Length() int { return e.value.Length() }
+func (e elementList) Length() int {
+	if e.value.IsUndefined() {
+		return 0
+	}
+	return e.value.Length()
+}
 
 func (e elementList) Item(index int) spec.Element {
+	if e.value.IsUndefined() {
+		return nil
+	}
 	return item(e.value, index)
 }
 
-func querySelectorAll(receiver js.Value, query string) elementList {
-	return elementList{value: receiver.Call("querySelectorAll", query)}
+func (e elementList) All() iter.Seq2[int, spec.Element] {
+	return func(yield func(int, spec.Element) bool) {
+		for i := 0; i < e.Length(); i++ {
+			if !yield(i, e.Item(i)) {
+				return
+			}
+		}
+	}
+}
+
+func querySelectorAll(receiver js.Value, query string) (elementList, error) {
+	var result js.Value
+	if err := catch(func() { result = receiver.Call("querySelectorAll", query) }); err != nil {
+		return elementList{}, selectorError(query, err)
+	}
+	return elementList{value: result}, nil
 }
 
-func querySelectorEach(receiver js.Value, query string) iter.Seq[spec.Element] {
+func querySelectorEach(receiver js.Value, query string) (iter.Seq[spec.Element], error) {
+	list, err := querySelectorAll(receiver, query)
 	return func(f func(spec.Element) bool) {
-		list := querySelectorAll(receiver, query)
 		for i := 0; i < list.Length(); i++ {
 			if !f(list.Item(i)) {
 				return
 			}
 		}
+	}, err
+}
+
+func closest(receiver js.Value, selector string) (spec.Element, error) {
+	var result js.Value
+	if err := catch(func() { result = receiver.Call("closest", selector) }); err != nil {
+		return nil, selectorError(selector, err)
+	}
+	return newElement(result), nil
+}
+
+func matches(receiver js.Value, selector string) (bool, error) {
+	var result js.Value
+	if err := catch(func() { result = receiver.Call("matches", selector) }); err != nil {
+		return false, selectorError(selector, err)
+	}
+	return result.Bool(), nil
+}
+
+// selectorError wraps a SyntaxError thrown for selector in a
+// *spec.SelectorError.
+func selectorError(selector string, err error) error {
+	if errors.Is(err, spec.ErrSyntax) {
+		return &spec.SelectorError{Selector: selector, Err: err}
 	}
+	return err
 }
 
 func createElement(receiver js.Value, tagName string) spec.Element {
@@ -426,7 +1856,7 @@ func ownerDocument(receiver js.Value) spec.Document {
 func parentNode(receiver js.Value) spec.Node       { return NewNode(receiver.Get("parentNode")) }
 func parentElement(receiver js.Value) spec.Element { return newElement(receiver.Get("parentElement")) }
 func children(receiver js.Value) htmlCollection {
-	return htmlCollection{value: receiver.Call("children")}
+	return htmlCollection{value: receiver.Get("children")}
 }
 
 func compareDocumentPosition(receiver js.Value, other spec.Node) spec.DocumentPosition {
@@ -441,6 +1871,86 @@ func nextSibling(receiver js.Value) spec.ChildNode {
 	return newChildNode(receiver.Get("nextSibling"))
 }
 
+// ancestors yields the parentNode chain of receiver.
+func ancestors(receiver js.Value) iter.Seq[spec.Node] {
+	return func(yield func(spec.Node) bool) {
+		for p := receiver.Get("parentNode"); !p.IsNull(); p = p.Get("parentNode") {
+			if !yield(NewNode(p)) {
+				return
+			}
+		}
+	}
+}
+
+// siblings yields the nodes reached by following the property from receiver.
+// The next node is read before the current one is yielded.
+func siblings(receiver js.Value, property string) iter.Seq[spec.ChildNode] {
+	return func(yield func(spec.ChildNode) bool) {
+		for c := receiver.Get(property); !c.IsNull(); {
+			next := c.Get(property)
+			if !yield(newChildNode(c)) {
+				return
+			}
+			c = next
+		}
+	}
+}
+
+func followingSiblings(receiver js.Value) iter.Seq[spec.ChildNode] {
+	return siblings(receiver, "nextSibling")
+}
+
+func precedingSiblings(receiver js.Value) iter.Seq[spec.ChildNode] {
+	return siblings(receiver, "previousSibling")
+}
+
+func childNodesSeq(receiver js.Value) iter.Seq[spec.ChildNode] {
+	return func(yield func(spec.ChildNode) bool) {
+		for c := receiver.Get("firstChild"); !c.IsNull(); {
+			next := c.Get("nextSibling")
+			if !yield(newChildNode(c)) {
+				return
+			}
+			c = next
+		}
+	}
+}
+
+func childrenSeq(receiver js.Value) iter.Seq[spec.Element] {
+	return func(yield func(spec.Element) bool) {
+		for c := receiver.Get("firstElementChild"); !c.IsNull(); {
+			next := c.Get("nextElementSibling")
+			if !yield(newElement(c)) {
+				return
+			}
+			c = next
+		}
+	}
+}
+
+// descendants yields the descendants of receiver in tree order.
+func descendants(receiver js.Value) iter.Seq[spec.Node] {
+	return func(yield func(spec.Node) bool) {
+		n := receiver.Get("firstChild")
+		for !n.IsNull() {
+			if !yield(NewNode(n)) {
+				return
+			}
+			if c := n.Get("firstChild"); !c.IsNull() {
+				n = c
+				continue
+			}
+			for n.Get("nextSibling").IsNull() {
+				n = n.Get("parentNode")
+				if n.IsNull() || n.Equal(receiver) {
+					return
+				}
+			}
+			n = n.Get("nextSibling")
+		}
+	}
+}
+
 func firstElementChild(receiver js.Value) spec.Element {
 	return newElement(receiver.Get("firstElementChild"))
 }
@@ -450,6 +1960,31 @@ func lastElementChild(receiver js.Value) spec.Element {
 }
 func childElementCount(receiver js.Value) int { return receiver.Get("childElementCount").Int() }
 
+func hasChildNodes(receiver js.Value) bool { return receiver.Call("hasChildNodes").Bool() }
+
+func childNodes(receiver js.Value) spec.NodeList[spec.Node] {
+	return nodeList{value: receiver.Get("childNodes")}
+}
+
+func firstChild(receiver js.Value) spec.ChildNode { return newChildNode(receiver.Get("firstChild")) }
+func lastChild(receiver js.Value) spec.ChildNode  { return newChildNode(receiver.Get("lastChild")) }
+
+func insertBefore(receiver js.Value, node spec.Node, child spec.ChildNode) spec.Node {
+	return NewNode(receiver.Call("insertBefore", JSValue(node), JSValue(child)))
+}
+
+func appendChild(receiver js.Value, node spec.Node) spec.Node {
+	return NewNode(receiver.Call("appendChild", JSValue(node)))
+}
+
+func replaceChild(receiver js.Value, node spec.Node, child spec.ChildNode) spec.ChildNode {
+	return newChildNode(receiver.Call("replaceChild", JSValue(node), JSValue(child)))
+}
+
+func removeChild(receiver js.Value, node spec.ChildNode) spec.ChildNode {
+	return newChildNode(receiver.Call("removeChild", JSValue(node)))
+}
+
 func appendNodes(receiver js.Value, in []spec.Node) {
 	receiver.Call("append", valueArray(in)...)
 }
@@ -468,3 +2003,13 @@ type nodeList struct {
 
 func (n nodeList) Length() int          { return n.value.Length() }
 func (n nodeList) Item(i int) spec.Node { return NewNode(n.value.Call("item", i)) }
+
+func (n nodeList) All() iter.Seq2[int, spec.Node] {
+	return func(yield func(int, spec.Node) bool) {
+		for i := 0; i < n.Length(); i++ {
+			if !yield(i, n.Item(i)) {
+				return
+			}
+		}
+	}
+}
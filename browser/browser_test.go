@@ -3,6 +3,9 @@
 package browser_test
 
 import (
+	"context"
+	"runtime"
+	"strings"
 	"sync"
 	"testing"
 
@@ -156,3 +159,415 @@ func TestElement_CompareDocumentPosition(t *testing.T) {
 		assert.True(t, spec.DocumentPositionImplementationSpecific&pos != 0)
 	})
 }
+
+func TestDocument_CreateComment(t *testing.T) {
+	document := browser.OpenDocument()
+	div := document.CreateElement("div")
+
+	comment := document.CreateComment("peach")
+	require.NotNil(t, comment)
+	assert.Equal(t, spec.NodeTypeComment, comment.NodeType())
+	assert.Equal(t, "peach", comment.Data())
+
+	div.Append(comment)
+	assert.Equal(t, "<!--peach-->", div.InnerHTML())
+	assert.True(t, div.FirstChild().IsSameNode(comment))
+}
+
+func TestDocument_Doctype(t *testing.T) {
+	document := browser.OpenDocument()
+
+	doctype, err := document.Implementation().CreateDocumentType("html", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, spec.NodeTypeDocumentType, doctype.NodeType())
+	assert.Equal(t, "html", doctype.Name())
+
+	_, err = document.Implementation().CreateDocumentType("a>", "", "")
+	assert.ErrorIs(t, err, spec.ErrInvalidCharacter)
+}
+
+func TestText_CharacterData(t *testing.T) {
+	document := browser.OpenDocument()
+	text := document.CreateTextNode("a🍑bcd")
+	assert.Equal(t, 6, text.Length())
+
+	got, err := text.SubstringData(1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "🍑", got)
+
+	require.NoError(t, text.ReplaceData(1, 2, "🍎"))
+	assert.Equal(t, "a🍎bcd", text.Data())
+
+	assert.ErrorIs(t, text.InsertData(100, "x"), spec.ErrIndexSize)
+}
+
+func TestElement_Attributes(t *testing.T) {
+	document := browser.OpenDocument()
+	div := document.CreateElement("div")
+	div.SetAttribute("id", "x")
+	div.SetAttribute("title", "peach")
+
+	assert.Equal(t, []string{"id", "title"}, div.GetAttributeNames())
+	assert.Equal(t, 2, div.Attributes().Length())
+	attr := div.GetAttributeNode("title")
+	require.NotNil(t, attr)
+	assert.Equal(t, "peach", attr.Value())
+	assert.True(t, attr.OwnerElement().IsSameNode(div))
+
+	other := document.CreateElement("div")
+	_, err := other.SetAttributeNode(attr)
+	assert.ErrorIs(t, err, spec.ErrInUseAttribute)
+}
+
+func TestDocument_CreateElementNS(t *testing.T) {
+	document := browser.OpenDocument()
+
+	gradient, err := document.CreateElementNS(spec.SVGNamespace, "linearGradient")
+	require.NoError(t, err)
+	assert.Equal(t, "linearGradient", gradient.TagName())
+	assert.Equal(t, spec.SVGNamespace, gradient.NamespaceURI())
+	assert.Equal(t, "", gradient.Prefix())
+
+	require.NoError(t, gradient.SetAttributeNS(spec.XLinkNamespace, "xlink:href", "#g"))
+	assert.Equal(t, "#g", gradient.GetAttributeNS(spec.XLinkNamespace, "href"))
+	assert.Equal(t, "", gradient.GetAttribute("href"))
+
+	_, err = document.CreateElementNS(spec.SVGNamespace, "xml:a")
+	assert.ErrorIs(t, err, spec.ErrNamespace)
+}
+
+func TestDocument_Title(t *testing.T) {
+	document := browser.OpenDocument()
+	document.SetTitle("  peach  ")
+	assert.Equal(t, "peach", document.Title())
+	assert.Equal(t, "HTML", document.DocumentElement().TagName())
+
+	div := document.CreateElement("div")
+	div.SetAttribute("id", "title-test")
+	document.Body().Append(div)
+	defer div.Remove()
+	assert.True(t, document.GetElementByID("title-test").IsSameNode(div))
+	assert.Nil(t, document.GetElementByID("missing"))
+}
+
+func TestElement_IsEqualNode(t *testing.T) {
+	document := browser.OpenDocument()
+	a := document.CreateElement("p")
+	a.SetAttribute("class", "x")
+	a.SetAttribute("id", "y")
+	b := document.CreateElement("p")
+	b.SetAttribute("id", "y")
+	b.SetAttribute("class", "x")
+
+	assert.True(t, a.IsEqualNode(b))
+	b.Append(document.CreateTextNode("peach"))
+	assert.False(t, a.IsEqualNode(b))
+
+	assert.True(t, b.FirstChild().GetRootNode().IsSameNode(b))
+	assert.True(t, document.Body().GetRootNode().IsSameNode(document))
+}
+
+func TestDocument_ImportNode(t *testing.T) {
+	document := browser.OpenDocument()
+	div := document.CreateElement("div")
+	div.Append(document.CreateTextNode("peach"))
+
+	imported, err := document.ImportNode(div, true)
+	require.NoError(t, err)
+	assert.True(t, imported.IsEqualNode(div))
+	assert.False(t, imported.IsSameNode(div))
+	assert.True(t, imported.(spec.Element).OwnerDocument().IsSameNode(document))
+
+	_, err = document.ImportNode(document, false)
+	assert.ErrorIs(t, err, spec.ErrNotSupported)
+
+	adopted, err := document.AdoptNode(div)
+	require.NoError(t, err)
+	assert.True(t, adopted.IsSameNode(div))
+}
+
+func TestDocument_CreateDocumentFragment(t *testing.T) {
+	document := browser.OpenDocument()
+	fragment := document.CreateDocumentFragment()
+	p := document.CreateElement("p")
+	fragment.Append(p, document.CreateTextNode("peach"))
+	assert.True(t, p.ParentNode().IsSameNode(fragment))
+
+	div := document.CreateElement("div")
+	assert.True(t, div.AppendChild(fragment).IsSameNode(fragment))
+	assert.Equal(t, "<p></p>peach", div.InnerHTML())
+	assert.False(t, fragment.HasChildNodes())
+}
+
+func TestHTMLTemplateElement_Content(t *testing.T) {
+	document := browser.OpenDocument()
+	template, ok := document.CreateElement("template").(spec.HTMLTemplateElement)
+	require.True(t, ok)
+	template.Content().Append(document.CreateElement("p"))
+	document.Body().Append(template)
+	defer template.Remove()
+
+	assert.Equal(t, 1, template.Content().ChildElementCount())
+	assert.Nil(t, document.Body().QuerySelector("template p"))
+}
+
+func TestDocument_QuerySelectorErr(t *testing.T) {
+	document := browser.OpenDocument()
+	_, err := document.QuerySelectorErr("p[")
+	require.ErrorIs(t, err, spec.ErrSyntax)
+	var selectorErr *spec.SelectorError
+	require.ErrorAs(t, err, &selectorErr)
+	assert.Equal(t, "p[", selectorErr.Selector)
+
+	assert.Nil(t, document.QuerySelector("p["))
+	assert.Equal(t, 0, document.QuerySelectorAll("p[").Length())
+	assert.False(t, document.Body().Matches("p["))
+}
+
+func TestElement_ClassList(t *testing.T) {
+	document := browser.OpenDocument()
+	div := document.CreateElement("div")
+	div.SetAttribute("class", " a b a ")
+	list := div.ClassList()
+	assert.Equal(t, 2, list.Length())
+	require.NoError(t, list.Add("c"))
+	assert.Equal(t, "a b c", div.ClassName())
+	require.ErrorIs(t, list.Add("d e"), spec.ErrInvalidCharacter)
+	_, err := list.Toggle("")
+	require.ErrorIs(t, err, spec.ErrSyntax)
+
+	a, ok := document.CreateElement("a").(spec.HTMLAnchorElement)
+	require.True(t, ok)
+	require.NoError(t, a.RelList().Add("noopener"))
+	assert.Equal(t, "noopener", a.GetAttribute("rel"))
+}
+
+func TestElement_Dataset(t *testing.T) {
+	document := browser.OpenDocument()
+	div := document.CreateElement("div")
+	dataset := div.Dataset()
+	require.NoError(t, dataset.Set("userId", "42"))
+	assert.Equal(t, "42", div.GetAttribute("data-user-id"))
+	assert.True(t, dataset.Has("userId"))
+	assert.Equal(t, "42", dataset.Get("userId"))
+	require.ErrorIs(t, dataset.Set("user-id", "1"), spec.ErrSyntax)
+	dataset.Delete("userId")
+	assert.False(t, div.HasAttribute("data-user-id"))
+}
+
+func TestElement_Style(t *testing.T) {
+	document := browser.OpenDocument()
+	div := document.CreateElement("div")
+	div.SetAttribute("style", "color: red; width: 10px !important")
+	style := div.Style()
+	assert.Equal(t, 2, style.Length())
+	assert.Equal(t, "red", style.GetPropertyValue("color"))
+	assert.Equal(t, "important", style.GetPropertyPriority("width"))
+	style.SetProperty("display", "none", "")
+	assert.Equal(t, "red", style.RemoveProperty("color"))
+	assert.Equal(t, "width: 10px !important; display: none;", div.GetAttribute("style"))
+}
+
+func TestElement_InsertAdjacentHTML(t *testing.T) {
+	document := browser.OpenDocument()
+	div := document.CreateElement("div")
+	require.NoError(t, div.InsertAdjacentHTML(spec.InsertBeforeEnd, "<p>a</p>"))
+	require.NoError(t, div.InsertAdjacentText(spec.InsertAfterBegin, "b"))
+	assert.Equal(t, "b<p>a</p>", div.InnerHTML())
+	require.ErrorIs(t, div.InsertAdjacentHTML("middle", "<p></p>"), spec.ErrSyntax)
+	require.ErrorIs(t, div.InsertAdjacentHTML(spec.InsertBeforeBegin, "<p></p>"), spec.ErrNoModificationAllowed)
+	_, err := div.InsertAdjacentElement(spec.InsertAfterEnd, document.CreateElement("span"))
+	require.ErrorIs(t, err, spec.ErrNoModificationAllowed)
+}
+
+func TestElement_SetOuterHTML(t *testing.T) {
+	document := browser.OpenDocument()
+	div := document.CreateElement("div")
+	require.NoError(t, div.SetInnerHTML("<p>a</p>"))
+	p := div.FirstElementChild()
+	require.NoError(t, p.SetOuterHTML("<span>b</span>"))
+	assert.Equal(t, "<span>b</span>", div.GetHTML(spec.GetHTMLOptions{}))
+	require.ErrorIs(t, document.DocumentElement().SetOuterHTML("<html></html>"), spec.ErrNoModificationAllowed)
+}
+
+func TestDocument_CreateTreeWalker(t *testing.T) {
+	document := browser.OpenDocument()
+	div := document.CreateElement("div")
+	require.NoError(t, div.SetInnerHTML("a<script>b</script><p>c</p>"))
+	walker := document.CreateTreeWalker(div, spec.ShowElement|spec.ShowText, func(node spec.Node) spec.FilterResult {
+		if el, ok := node.(spec.Element); ok && el.TagName() == "SCRIPT" {
+			return spec.FilterReject
+		}
+		return spec.FilterAccept
+	})
+	var sb strings.Builder
+	for n := walker.NextNode(); n != nil; n = walker.NextNode() {
+		if n.NodeType() == spec.NodeTypeText {
+			sb.WriteString(n.TextContent())
+		}
+	}
+	assert.Equal(t, "ac", sb.String())
+
+	iterator := document.CreateNodeIterator(div, spec.ShowElement, nil)
+	assert.True(t, iterator.NextNode().IsSameNode(div))
+	p := div.LastElementChild()
+	assert.Equal(t, "SCRIPT", iterator.NextNode().(spec.Element).TagName())
+	assert.True(t, iterator.NextNode().IsSameNode(p))
+	p.Remove()
+	assert.Equal(t, "b", iterator.ReferenceNode().TextContent())
+}
+
+func TestDocument_CreateRange(t *testing.T) {
+	document := browser.OpenDocument()
+	p := document.CreateElement("p")
+	require.NoError(t, p.SetInnerHTML("Hello, <em>wor</em>ld!"))
+	r := document.CreateRange()
+	require.NoError(t, r.SetStart(p.FirstChild(), 3))
+	require.NoError(t, r.SetEnd(p.QuerySelector("em").FirstChild(), 2))
+	assert.Equal(t, "lo, wo", r.String())
+	require.ErrorIs(t, r.SurroundContents(document.CreateElement("mark")), spec.ErrInvalidState)
+
+	fragment, err := r.ExtractContents()
+	require.NoError(t, err)
+	mark := document.CreateElement("mark")
+	mark.Append(fragment)
+	require.NoError(t, r.InsertNode(mark))
+	assert.Equal(t, "Hel<mark>lo, <em>wo</em></mark><em>r</em>ld!", p.InnerHTML())
+	require.ErrorIs(t, r.SetStart(p, 9), spec.ErrIndexSize)
+
+	static, err := browser.NewStaticRange(spec.StaticRangeInit{StartContainer: p, EndContainer: p, EndOffset: 1})
+	require.NoError(t, err)
+	assert.False(t, static.Collapsed())
+}
+
+func TestElement_Descendants(t *testing.T) {
+	document := browser.OpenDocument()
+	div := document.CreateElement("div")
+	require.NoError(t, div.SetInnerHTML("<h1>a</h1><p>b<em>c</em></p>d"))
+
+	var sb strings.Builder
+	for n := range div.Descendants() {
+		if n.NodeType() == spec.NodeTypeText {
+			sb.WriteString(n.TextContent())
+		}
+	}
+	assert.Equal(t, "abcd", sb.String())
+
+	em := div.QuerySelector("em")
+	var ancestors int
+	for n := range em.Ancestors() {
+		ancestors++
+		if n.IsSameNode(div) {
+			break
+		}
+	}
+	assert.Equal(t, 2, ancestors)
+
+	h1 := div.FirstElementChild()
+	var siblings []spec.ChildNode
+	for n := range h1.FollowingSiblings() {
+		siblings = append(siblings, n)
+	}
+	require.Len(t, siblings, 2)
+	assert.Equal(t, "d", siblings[1].TextContent())
+
+	for i, el := range div.QuerySelectorAll("*").All() {
+		assert.Equal(t, "H1", el.TagName())
+		assert.Equal(t, 0, i)
+		break
+	}
+}
+
+func TestElement_DispatchEvent(t *testing.T) {
+	document := browser.OpenDocument()
+	div := document.CreateElement("div")
+	require.NoError(t, div.SetInnerHTML("<button>Save</button>"))
+	button := div.FirstElementChild()
+
+	var calls []spec.EventPhase
+	var detail any
+	div.AddEventListener("saved", spec.NewEventListener(func(event spec.Event) {
+		calls = append(calls, event.EventPhase())
+	}), spec.AddEventListenerOptions{EventListenerOptions: spec.EventListenerOptions{Capture: true}})
+	div.AddEventListener("saved", spec.NewEventListener(func(event spec.Event) {
+		calls = append(calls, event.EventPhase())
+		detail = event.(spec.CustomEvent).Detail()
+		event.PreventDefault()
+	}), spec.AddEventListenerOptions{})
+	once := spec.NewEventListener(func(event spec.Event) {
+		calls = append(calls, event.EventPhase())
+	})
+	button.AddEventListener("saved", once, spec.AddEventListenerOptions{Once: true})
+
+	type saved struct{ id int }
+	ok, err := button.DispatchEvent(browser.NewCustomEvent("saved", spec.CustomEventInit{
+		EventInit: spec.EventInit{Bubbles: true, Cancelable: true},
+		Detail:    saved{id: 7},
+	}))
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Equal(t, []spec.EventPhase{spec.EventPhaseCapturing, spec.EventPhaseAtTarget, spec.EventPhaseBubbling}, calls)
+	assert.Equal(t, saved{id: 7}, detail)
+
+	calls = nil
+	_, err = button.DispatchEvent(browser.NewEvent("saved", spec.EventInit{}))
+	require.NoError(t, err)
+	assert.Equal(t, []spec.EventPhase{spec.EventPhaseCapturing}, calls)
+}
+
+func TestElement_RemoveEventListener(t *testing.T) {
+	document := browser.OpenDocument()
+	div := document.CreateElement("div")
+
+	calls := 0
+	listener := spec.NewEventListener(func(spec.Event) { calls++ })
+	div.AddEventListener("saved", listener, spec.AddEventListenerOptions{})
+	div.AddEventListener("saved", listener, spec.AddEventListenerOptions{})
+	_, err := div.DispatchEvent(browser.NewEvent("saved", spec.EventInit{}))
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	div.RemoveEventListener("saved", listener, spec.EventListenerOptions{})
+	_, err = div.DispatchEvent(browser.NewEvent("saved", spec.EventInit{}))
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	div.AddEventListener("saved", listener, spec.AddEventListenerOptions{})
+	_, err = div.DispatchEvent(browser.NewEvent("saved", spec.EventInit{}))
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls, "a removed listener can be added again")
+
+	t.Run("signal", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		aborted := 0
+		div.AddEventListener("closed", spec.NewEventListener(func(spec.Event) { aborted++ }), spec.AddEventListenerOptions{Signal: ctx})
+		cancel()
+		runtime.Gosched()
+		_, err := div.DispatchEvent(browser.NewEvent("closed", spec.EventInit{}))
+		require.NoError(t, err)
+		assert.Zero(t, aborted)
+	})
+}
+
+func TestMutationObserver(t *testing.T) {
+	document := browser.OpenDocument()
+	div := document.CreateElement("div")
+	require.NoError(t, div.SetInnerHTML("<p>a</p>"))
+	observer := browser.NewMutationObserver(nil)
+	require.NoError(t, observer.Observe(div, spec.MutationObserverInit{ChildList: true, AttributeOldValue: true, Subtree: true}))
+	defer observer.Disconnect()
+
+	p := div.FirstElementChild()
+	p.SetAttribute("class", "note")
+	p.SetAttribute("class", "other")
+	div.AppendChild(document.CreateElement("span"))
+
+	records := observer.TakeRecords()
+	require.Len(t, records, 3)
+	assert.Equal(t, spec.MutationAttributes, records[0].Type)
+	assert.Equal(t, "class", records[0].AttributeName)
+	assert.Equal(t, "note", records[1].OldValue)
+	assert.Equal(t, spec.MutationChildList, records[2].Type)
+	assert.True(t, records[2].PreviousSibling.IsSameNode(p))
+	require.ErrorIs(t, observer.Observe(div, spec.MutationObserverInit{Subtree: true}), spec.ErrType)
+}
@@ -0,0 +1,126 @@
+package dom
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+// utf16Length returns the length of s in UTF-16 code units.
+func utf16Length(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// substringData is based on https://dom.spec.whatwg.org/#concept-cd-substring
+//
+// Splitting a surrogate pair yields U+FFFD since Go strings can not hold lone surrogates.
+func substringData(node *html.Node, offset, count int) (string, error) {
+	units := utf16.Encode([]rune(node.Data))
+	offset, count, err := clampCharacterDataRange(len(units), offset, count)
+	if err != nil {
+		return "", err
+	}
+	return string(utf16.Decode(units[offset : offset+count])), nil
+}
+
+// replaceData is based on https://dom.spec.whatwg.org/#concept-cd-replace
+//
+// Splitting a surrogate pair yields U+FFFD since Go strings can not hold lone surrogates.
+func replaceData(node *html.Node, offset, count int, data string) error {
+	units := utf16.Encode([]rune(node.Data))
+	offset, count, err := clampCharacterDataRange(len(units), offset, count)
+	if err != nil {
+		return err
+	}
+	queueMutationRecord(mutation{kind: spec.MutationCharacterData, target: node, oldValue: node.Data})
+	node.Data = string(utf16.Decode(units[:offset])) + data + string(utf16.Decode(units[offset+count:]))
+	dataReplaced(node, offset, count, utf16Length(data))
+	return nil
+}
+
+// clampCharacterDataRange checks offset against length and clamps count so that
+// offset+count does not exceed length. A negative count behaves like the
+// wrapped unsigned long a browser would see and extends to the end of the data.
+func clampCharacterDataRange(length, offset, count int) (int, int, error) {
+	if offset < 0 || offset > length {
+		return 0, 0, fmt.Errorf("%w: offset %d is not in the range [0, %d]", spec.ErrIndexSize, offset, length)
+	}
+	if count < 0 || count > length-offset {
+		count = length - offset
+	}
+	return offset, count, nil
+}
+
+// splitText is based on https://dom.spec.whatwg.org/#concept-text-split
+func splitText(node *html.Node, offset int) (*html.Node, error) {
+	length := utf16Length(node.Data)
+	data, err := substringData(node, offset, length-offset)
+	if err != nil {
+		return nil, err
+	}
+	newNode := &html.Node{
+		Type: html.TextNode,
+		Data: data,
+	}
+	setNodeDocument(newNode, ownerDocumentNode(node))
+	if parent := node.Parent; parent != nil {
+		insertHTMLNodes(parent, node.NextSibling, []*html.Node{newNode})
+		textSplit(node, newNode, offset)
+	}
+	if err := replaceData(node, offset, length-offset, ""); err != nil {
+		return nil, err
+	}
+	return newNode, nil
+}
+
+// wholeText is based on https://dom.spec.whatwg.org/#dom-text-wholetext
+func wholeText(node *html.Node) string {
+	start := node
+	for start.PrevSibling != nil && start.PrevSibling.Type == html.TextNode {
+		start = start.PrevSibling
+	}
+	var sb strings.Builder
+	for c := start; c != nil && c.Type == html.TextNode; c = c.NextSibling {
+		sb.WriteString(c.Data)
+	}
+	return sb.String()
+}
+
+// normalize is based on https://dom.spec.whatwg.org/#dom-node-normalize
+func normalize(node *html.Node) {
+	c := node.FirstChild
+	for c != nil {
+		next := c.NextSibling
+		switch {
+		case c.Type != html.TextNode:
+			if !isTemplate(c) {
+				normalize(c)
+			}
+		case c.Data == "":
+			detachHTMLNode(c)
+		default:
+			length := utf16Length(c.Data)
+			var sb strings.Builder
+			for n := next; n != nil && n.Type == html.TextNode; n = n.NextSibling {
+				sb.WriteString(n.Data)
+			}
+			_ = replaceData(c, length, 0, sb.String())
+			for next != nil && next.Type == html.TextNode {
+				textMerged(c, next, length)
+				length += utf16Length(next.Data)
+				following := next.NextSibling
+				detachHTMLNode(next)
+				next = following
+			}
+		}
+		c = next
+	}
+}
@@ -0,0 +1,106 @@
+package dom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/typelate/dom/spec"
+)
+
+func Test_utf16Length(t *testing.T) {
+	for _, tt := range []struct {
+		In  string
+		Exp int
+	}{
+		{"", 0},
+		{"Hello!", 6},
+		{"héllo", 5},
+		{"日本", 2},
+		{"🍑", 2},
+		{"a🍑b", 4},
+	} {
+		assert.Equalf(t, tt.Exp, utf16Length(tt.In), "%q", tt.In)
+	}
+}
+
+func TestCharacterData(t *testing.T) {
+	var document *Document
+	for _, tt := range []struct {
+		Name   string
+		Create func(data string) spec.CharacterData
+	}{
+		{Name: "Text", Create: func(data string) spec.CharacterData { return document.CreateTextNode(data) }},
+		{Name: "Comment", Create: func(data string) spec.CharacterData { return document.CreateComment(data) }},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			t.Run("Length", func(t *testing.T) {
+				assert.Equal(t, 6, tt.Create("a🍑bcd").Length())
+			})
+			t.Run("SubstringData", func(t *testing.T) {
+				cd := tt.Create("a🍑bcd")
+
+				got, err := cd.SubstringData(1, 2)
+				require.NoError(t, err)
+				assert.Equal(t, "🍑", got)
+
+				got, err = cd.SubstringData(3, 100)
+				require.NoError(t, err)
+				assert.Equal(t, "bcd", got)
+
+				got, err = cd.SubstringData(6, 1)
+				require.NoError(t, err)
+				assert.Equal(t, "", got)
+
+				got, err = cd.SubstringData(3, -1)
+				require.NoError(t, err)
+				assert.Equal(t, "bcd", got)
+
+				_, err = cd.SubstringData(7, 1)
+				assert.ErrorIs(t, err, spec.ErrIndexSize)
+
+				_, err = cd.SubstringData(-1, 1)
+				assert.ErrorIs(t, err, spec.ErrIndexSize)
+			})
+			t.Run("AppendData", func(t *testing.T) {
+				cd := tt.Create("peach")
+				cd.AppendData(" 🍑")
+				assert.Equal(t, "peach 🍑", cd.Data())
+			})
+			t.Run("InsertData", func(t *testing.T) {
+				cd := tt.Create("🍑pie")
+				require.NoError(t, cd.InsertData(2, " "))
+				assert.Equal(t, "🍑 pie", cd.Data())
+				require.NoError(t, cd.InsertData(0, ">"))
+				assert.Equal(t, ">🍑 pie", cd.Data())
+				require.NoError(t, cd.InsertData(cd.Length(), "!"))
+				assert.Equal(t, ">🍑 pie!", cd.Data())
+
+				assert.ErrorIs(t, cd.InsertData(cd.Length()+1, "x"), spec.ErrIndexSize)
+				assert.Equal(t, ">🍑 pie!", cd.Data())
+			})
+			t.Run("DeleteData", func(t *testing.T) {
+				cd := tt.Create("héllo 🍑 world")
+				require.NoError(t, cd.DeleteData(5, 3))
+				assert.Equal(t, "héllo world", cd.Data())
+				require.NoError(t, cd.DeleteData(5, 100))
+				assert.Equal(t, "héllo", cd.Data())
+
+				assert.ErrorIs(t, cd.DeleteData(6, 1), spec.ErrIndexSize)
+			})
+			t.Run("ReplaceData", func(t *testing.T) {
+				cd := tt.Create("I like 🍎.")
+				require.NoError(t, cd.ReplaceData(7, 2, "🍑"))
+				assert.Equal(t, "I like 🍑.", cd.Data())
+
+				assert.ErrorIs(t, cd.ReplaceData(100, 0, "x"), spec.ErrIndexSize)
+			})
+			t.Run("split surrogate pair", func(t *testing.T) {
+				cd := tt.Create("🍑")
+				require.NoError(t, cd.InsertData(1, "x"))
+				assert.Equal(t, "�x�", cd.Data())
+			})
+		})
+	}
+}
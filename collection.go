@@ -0,0 +1,105 @@
+package dom
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+	"weak"
+
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+// treeVersion is incremented by every change to a tree or an element's
+// attributes made through this package. The root of the changed tree records
+// the version of its last change in treeVersions. Live collections use it to
+// tell when their elements need to be collected again, so changes to other
+// trees do not invalidate them.
+//
+// Changes made directly to html.Node values are not counted.
+var (
+	treeVersion  atomic.Uint64
+	treeVersions weakMap[uint64]
+)
+
+// treeMutated must be called after a change to the tree of node or to an
+// attribute of node.
+func treeMutated(node *html.Node) {
+	treeVersions.store(rootHTMLNode(node), treeVersion.Add(1))
+}
+
+// liveElements is a live collection of elements. It is based on
+// https://dom.spec.whatwg.org/#interface-htmlcollection
+//
+// The elements are collected when the collection is first read and again
+// after the next mutation.
+type liveElements struct {
+	root    *html.Node
+	collect func(root *html.Node) []*html.Node
+
+	mu      sync.Mutex
+	valid   bool
+	tree    weak.Pointer[html.Node]
+	version uint64
+	nodes   []*html.Node
+}
+
+func newLiveElements(root *html.Node, collect func(root *html.Node) []*html.Node) *liveElements {
+	return &liveElements{root: root, collect: collect}
+}
+
+func (list *liveElements) elements() []*html.Node {
+	root := rootHTMLNode(list.root)
+	version, _ := treeVersions.load(root)
+	tree := weak.Make(root)
+	list.mu.Lock()
+	defer list.mu.Unlock()
+	if !list.valid || list.tree != tree || list.version != version {
+		list.nodes = list.collect(list.root)
+		list.tree, list.version = tree, version
+		list.valid = true
+	}
+	return list.nodes
+}
+
+func (list *liveElements) Length() int { return len(list.elements()) }
+
+func (list *liveElements) Item(index int) spec.Element {
+	nodes := list.elements()
+	if index < 0 || index >= len(nodes) {
+		return nil
+	}
+	return newElement(nodes[index])
+}
+
+// All yields the elements collected when the iteration starts.
+func (list *liveElements) All() iter.Seq2[int, spec.Element] {
+	return func(yield func(int, spec.Element) bool) {
+		for i, el := range list.elements() {
+			if !yield(i, newElement(el)) {
+				return
+			}
+		}
+	}
+}
+
+func (list *liveElements) NamedItem(name string) spec.Element {
+	for _, el := range list.elements() {
+		if isNamed(el, name) {
+			return newElement(el)
+		}
+	}
+	return nil
+}
+
+// childElements returns the element children of parent.
+func childElements(parent *html.Node) []*html.Node {
+	var nodes []*html.Node
+	for c := parent.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
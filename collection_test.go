@@ -0,0 +1,157 @@
+package dom
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+func TestElementCollection_live(t *testing.T) {
+	// language=html
+	const textHTML = `<!DOCTYPE html>
+<html lang="us-en">
+<head><title>Live</title></head>
+<body><ul id="list"><li id="a" class="item">A</li><li id="b" class="item">B</li></ul></body>
+</html>`
+
+	t.Run("GetElementsByTagName", func(t *testing.T) {
+		document, ul := parseDocument(t, textHTML, "#list")
+		items := document.GetElementsByTagName("li")
+		require.Equal(t, 2, items.Length())
+
+		li := document.CreateElement("li")
+		li.SetAttribute("name", "c")
+		ul.Append(li)
+		assert.Equal(t, 3, items.Length())
+		assert.True(t, items.Item(2).IsSameNode(li))
+		assert.True(t, items.NamedItem("c").IsSameNode(li))
+
+		items.Item(0).Remove()
+		assert.Equal(t, 2, items.Length())
+		assert.Equal(t, "b", items.Item(0).ID())
+		assert.Nil(t, items.NamedItem("a"))
+		assert.Nil(t, items.Item(2))
+	})
+	t.Run("GetElementsByClassName", func(t *testing.T) {
+		document, _ := parseDocument(t, textHTML, "")
+		items := document.GetElementsByClassName("item")
+		require.Equal(t, 2, items.Length())
+
+		// Removing the class while looping, as ported browser code does,
+		// shrinks the collection.
+		for items.Length() > 0 {
+			items.Item(0).RemoveAttribute("class")
+		}
+		assert.Equal(t, 0, items.Length())
+
+		document.GetElementByID("b").SetAttribute("class", "item")
+		require.Equal(t, 1, items.Length())
+		assert.Equal(t, "b", items.Item(0).ID())
+	})
+	t.Run("Children", func(t *testing.T) {
+		_, ul := parseDocument(t, textHTML, "#list")
+		children := ul.Children()
+		require.Equal(t, 2, children.Length())
+
+		first := children.Item(0)
+		ul.Prepend(ul.OwnerDocument().CreateElement("li"))
+		assert.Equal(t, 3, children.Length())
+		assert.True(t, children.Item(1).IsSameNode(first))
+
+		ul.ReplaceChildren()
+		assert.Equal(t, 0, children.Length())
+		assert.Nil(t, children.NamedItem("a"))
+	})
+	t.Run("SetInnerHTML", func(t *testing.T) {
+		document, ul := parseDocument(t, textHTML, "#list")
+		items := document.GetElementsByTagName("li")
+		require.Equal(t, 2, items.Length())
+		require.NoError(t, ul.SetInnerHTML(`<li id="x"></li>`))
+		require.Equal(t, 1, items.Length())
+		assert.Equal(t, "x", items.Item(0).ID())
+	})
+}
+
+func TestElementCollection_trees(t *testing.T) {
+	// language=html
+	const textHTML = `<!DOCTYPE html><html><head></head><body><ul id="list"><li>A</li></ul></body></html>`
+
+	t.Run("moved between trees", func(t *testing.T) {
+		document, ul := parseDocument(t, textHTML, "#list")
+		div := document.CreateElement("div")
+		items := div.GetElementsByTagName("li")
+		require.Equal(t, 0, items.Length())
+		div.Append(ul)
+		require.Equal(t, 1, items.Length())
+		document.Body().Append(div)
+		ul.Append(document.CreateElement("li"))
+		assert.Equal(t, 2, items.Length())
+		div.Remove()
+		ul.Append(document.CreateElement("li"))
+		assert.Equal(t, 3, items.Length())
+	})
+	t.Run("other trees", func(t *testing.T) {
+		_, a := parseDocument(t, textHTML, "#list")
+		b, other := parseDocument(t, textHTML, "#list")
+		collected := 0
+		items := newLiveElements(a.node, func(root *html.Node) []*html.Node {
+			collected++
+			return childElements(root)
+		})
+		require.Equal(t, 1, items.Length())
+		other.Append(b.CreateElement("li"))
+		other.SetAttribute("class", "changed")
+		require.Equal(t, 1, items.Length())
+		assert.Equal(t, 1, collected, "changes to other trees do not invalidate the collection")
+		a.Append(b.CreateElement("li"))
+		assert.Equal(t, 2, items.Length())
+		assert.Equal(t, 2, collected)
+	})
+}
+
+// BenchmarkTreeMutation measures appending and removing a child while live
+// collections, NodeIterators, and Ranges exist in other documents.
+func BenchmarkTreeMutation(b *testing.B) {
+	// language=html
+	const textHTML = `<!DOCTYPE html><html><head></head><body><ul id="list"><li>A</li><li>B</li></ul></body></html>`
+	mutate := func(b *testing.B) {
+		document, ul := parseDocument(b, textHTML, "#list")
+		li := document.CreateElement("li")
+		b.ResetTimer()
+		for b.Loop() {
+			ul.AppendChild(li)
+			ul.RemoveChild(li)
+		}
+	}
+	b.Run("alone", mutate)
+	b.Run("other documents", func(b *testing.B) {
+		var keep []any
+		for range 100 {
+			document, ul := parseDocument(b, textHTML, "#list")
+			items := document.GetElementsByTagName("li")
+			items.Length()
+			r := document.CreateRange()
+			require.NoError(b, r.SelectNodeContents(ul))
+			keep = append(keep, items, r, document.CreateNodeIterator(ul, spec.ShowAll, nil))
+		}
+		mutate(b)
+		runtime.KeepAlive(keep)
+	})
+	b.Run("collection read", func(b *testing.B) {
+		document, _ := parseDocument(b, textHTML, "#list")
+		_, other := parseDocument(b, textHTML, "#list")
+		items := document.GetElementsByTagName("li")
+		li := document.CreateElement("li")
+		b.ResetTimer()
+		for b.Loop() {
+			other.AppendChild(li)
+			other.RemoveChild(li)
+			items.Length()
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package dom
+
+import (
+	"iter"
+
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+type Comment struct {
+	node *html.Node
+}
+
+func (c *Comment) Data() string     { return c.node.Data }
+func (c *Comment) SetData(d string) { _ = replaceData(c.node, 0, -1, d) }
+
+func (c *Comment) SubstringData(offset, count int) (string, error) {
+	return substringData(c.node, offset, count)
+}
+
+func (c *Comment) AppendData(data string) { _ = replaceData(c.node, utf16Length(c.node.Data), 0, data) }
+
+func (c *Comment) InsertData(offset int, data string) error {
+	return replaceData(c.node, offset, 0, data)
+}
+
+func (c *Comment) DeleteData(offset, count int) error { return replaceData(c.node, offset, count, "") }
+
+func (c *Comment) ReplaceData(offset, count int, data string) error {
+	return replaceData(c.node, offset, count, data)
+}
+
+func (c *Comment) NodeType() spec.NodeType         { return nodeType(c.node.Type) }
+func (c *Comment) IsConnected() bool               { return isConnected(c.node) }
+func (c *Comment) OwnerDocument() spec.Document    { return ownerDocument(c.node) }
+func (c *Comment) Length() int                     { return utf16Length(c.node.Data) }
+func (c *Comment) ParentNode() spec.Node           { return parentNode(c.node) }
+func (c *Comment) ParentElement() spec.Element     { return parentElement(c.node) }
+func (c *Comment) PreviousSibling() spec.ChildNode { return previousSibling(c.node) }
+func (c *Comment) NextSibling() spec.ChildNode     { return nextSibling(c.node) }
+func (c *Comment) Before(nodes ...spec.Node)       { before(c.node, nodes) }
+func (c *Comment) After(nodes ...spec.Node)        { after(c.node, nodes) }
+func (c *Comment) ReplaceWith(nodes ...spec.Node)  { replaceWith(c.node, nodes) }
+func (c *Comment) Remove()                         { remove(c.node) }
+func (c *Comment) TextContent() string             { return c.node.Data }
+func (c *Comment) CloneNode(_ bool) spec.Node      { return &Comment{node: cloneNode(c.node, false)} }
+
+func (c *Comment) Ancestors() iter.Seq[spec.Node]              { return ancestors(c.node) }
+func (c *Comment) FollowingSiblings() iter.Seq[spec.ChildNode] { return followingSiblings(c.node) }
+func (c *Comment) PrecedingSiblings() iter.Seq[spec.ChildNode] { return precedingSiblings(c.node) }
+
+func (c *Comment) LookupNamespaceURI(prefix string) string { return lookupNamespaceURI(c.node, prefix) }
+func (c *Comment) LookupPrefix(namespace string) string    { return lookupPrefix(c.node, namespace) }
+
+func (c *Comment) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
+	return compareDocumentPosition(c.node, other)
+}
+
+func (c *Comment) IsSameNode(other spec.Node) bool  { return isSameNode(c.node, other) }
+func (c *Comment) IsEqualNode(other spec.Node) bool { return isEqualNode(c.node, other) }
+func (c *Comment) GetRootNode() spec.Node           { return getRootNode(c.node) }
+
+func (c *Comment) String() string { return outerHTML(c.node) }
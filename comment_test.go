@@ -0,0 +1,132 @@
+package dom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+var _ spec.Comment = (*Comment)(nil)
+
+func TestComment_Data(t *testing.T) {
+	// language=html
+	commentHTML := `<!DOCTYPE html>
+<html lang="us-en">
+<head><title></title></head>
+<body><span><!-- Hello! --></span></body>
+</html>`
+	_, span := parseDocument(t, commentHTML, "body span")
+	comment := &Comment{
+		node: span.node.FirstChild,
+	}
+
+	require.Equal(t, " Hello! ", comment.Data())
+	comment.SetData("Greetings!")
+	require.Equal(t, "Greetings!", comment.Data())
+	require.Equal(t, "<span><!--Greetings!--></span>", span.OuterHTML())
+}
+
+func TestComment_NodeType(t *testing.T) {
+	comment := &Comment{
+		node: &html.Node{Type: html.CommentNode},
+	}
+	assert.Equal(t, spec.NodeTypeComment, comment.NodeType())
+}
+
+func TestComment_navigation(t *testing.T) {
+	// language=html
+	commentHTML := `<!DOCTYPE html>
+<html lang="us-en">
+<head><title></title></head>
+<body><div id="target"><span id="before"></span><!-- note --><span id="after"></span></div></body>
+</html>`
+	document, target := parseDocument(t, commentHTML, "#target")
+
+	children := target.ChildNodes()
+	require.Equal(t, 3, children.Length())
+	comment, ok := children.Item(1).(*Comment)
+	require.Truef(t, ok, "wrong type %T", children.Item(1))
+
+	assert.True(t, comment.IsConnected())
+	assert.True(t, comment.OwnerDocument().IsSameNode(document))
+	assert.True(t, comment.ParentNode().IsSameNode(target))
+	assert.True(t, comment.ParentElement().IsSameNode(target))
+	assert.Equal(t, "before", comment.PreviousSibling().(spec.Element).ID())
+	assert.Equal(t, "after", comment.NextSibling().(spec.Element).ID())
+
+	before := target.FirstElementChild()
+	next, ok := before.NextSibling().(*Comment)
+	require.True(t, ok)
+	assert.True(t, next.IsSameNode(comment))
+
+	assert.Equal(t, "", target.TextContent(), "comments do not contribute to text content")
+	assert.Equal(t, " note ", comment.TextContent())
+}
+
+func TestComment_CloneNode(t *testing.T) {
+	// language=html
+	commentHTML := `<!DOCTYPE html>
+<html lang="us-en">
+<head><title></title></head>
+<body><div id="target"><!-- a --><p><!-- b --></p></div></body>
+</html>`
+	_, target := parseDocument(t, commentHTML, "#target")
+	comment := target.FirstChild().(*Comment)
+
+	cloned := comment.CloneNode(true).(*Comment)
+	require.Equal(t, " a ", cloned.node.Data)
+	require.Nil(t, cloned.node.Parent)
+	require.False(t, cloned.IsSameNode(comment))
+
+	clonedTarget := target.CloneNode(true).(*Element)
+	assert.Equal(t, target.OuterHTML(), clonedTarget.OuterHTML())
+}
+
+func TestDocument_CreateComment(t *testing.T) {
+	var document *Document
+	comment := document.CreateComment("peach")
+	require.Equal(t, spec.NodeTypeComment, comment.NodeType())
+	require.Equal(t, "peach", comment.Data())
+	require.Equal(t, "<!--peach-->", comment.(*Comment).String())
+
+	el := document.CreateElement("div")
+	el.Append(comment)
+	assert.Equal(t, "<div><!--peach--></div>", el.OuterHTML())
+	assert.True(t, el.FirstChild().IsSameNode(comment))
+}
+
+func TestComment_ReplaceWith(t *testing.T) {
+	// language=html
+	commentHTML := `<!DOCTYPE html>
+<html lang="us-en">
+<head><title></title></head>
+<body><ul id="target"><li>first</li><!-- placeholder --><li>last</li></ul></body>
+</html>`
+	document, target := parseDocument(t, commentHTML, "#target")
+	placeholder := target.FirstChild().NextSibling()
+
+	var items []spec.Node
+	for _, text := range []string{"a", "b"} {
+		li := document.CreateElement("li")
+		li.Append(document.CreateTextNode(text))
+		items = append(items, li)
+	}
+	placeholder.ReplaceWith(items...)
+
+	assert.Equal(t, `<li>first</li><li>a</li><li>b</li><li>last</li>`, target.InnerHTML())
+	assert.Nil(t, placeholder.ParentNode())
+}
+
+func TestComment_IsEqualNode(t *testing.T) {
+	var document *Document
+	comment := document.CreateComment("peach")
+
+	assert.True(t, comment.IsEqualNode(document.CreateComment("peach")))
+	assert.False(t, comment.IsEqualNode(document.CreateComment("pear")))
+	assert.False(t, comment.IsEqualNode(document.CreateTextNode("peach")))
+	assert.True(t, comment.GetRootNode().IsSameNode(comment))
+}
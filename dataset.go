@@ -0,0 +1,107 @@
+package dom
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+const dataAttributePrefix = "data-"
+
+// DOMStringMap is based on https://html.spec.whatwg.org/#domstringmap
+type DOMStringMap struct {
+	node *html.Node
+}
+
+func (m *DOMStringMap) Get(name string) string {
+	if i := m.index(name); i >= 0 {
+		return m.node.Attr[i].Val
+	}
+	return ""
+}
+
+func (m *DOMStringMap) Has(name string) bool { return m.index(name) >= 0 }
+
+// Set is based on https://html.spec.whatwg.org/#dom-domstringmap-setitem
+func (m *DOMStringMap) Set(name, value string) error {
+	for i := 0; i+1 < len(name); i++ {
+		if name[i] == '-' && isASCIILower(name[i+1]) {
+			return fmt.Errorf("%w: %q contains a hyphen followed by a lowercase letter", spec.ErrSyntax, name)
+		}
+	}
+	attribute := dataAttributeName(name)
+	if strings.ContainsAny(attribute, "\t\n\f\r />=\x00") {
+		return fmt.Errorf("%w: %q is not a valid attribute name", spec.ErrInvalidCharacter, attribute)
+	}
+	setAttribute(m.node, attribute, value)
+	return nil
+}
+
+// Delete is based on https://html.spec.whatwg.org/#dom-domstringmap-removeitem
+func (m *DOMStringMap) Delete(name string) {
+	if i := attributeIndex(m.node, dataAttributeName(name)); i >= 0 {
+		removeAttributeIndex(m.node, i)
+	}
+}
+
+func (m *DOMStringMap) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, att := range m.node.Attr {
+			name, ok := datasetName(att)
+			if ok && !yield(name, att.Val) {
+				return
+			}
+		}
+	}
+}
+
+func (m *DOMStringMap) index(name string) int {
+	for i, att := range m.node.Attr {
+		if n, ok := datasetName(att); ok && n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// datasetName returns the name of the data-* attribute att. It is based on
+// https://html.spec.whatwg.org/#concept-domstringmap-pairs
+func datasetName(att html.Attribute) (string, bool) {
+	rest, ok := strings.CutPrefix(att.Key, dataAttributePrefix)
+	if att.Namespace != "" || !ok || strings.ContainsFunc(att.Key, isASCIIUpperRune) {
+		return "", false
+	}
+	var sb strings.Builder
+	for i := 0; i < len(rest); i++ {
+		if rest[i] == '-' && i+1 < len(rest) && isASCIILower(rest[i+1]) {
+			i++
+			sb.WriteByte(rest[i] - 'a' + 'A')
+			continue
+		}
+		sb.WriteByte(rest[i])
+	}
+	return sb.String(), true
+}
+
+// dataAttributeName converts a dataset name to its attribute name.
+func dataAttributeName(name string) string {
+	var sb strings.Builder
+	sb.WriteString(dataAttributePrefix)
+	for i := 0; i < len(name); i++ {
+		if c := name[i]; c >= 'A' && c <= 'Z' {
+			sb.WriteByte('-')
+			sb.WriteByte(c - 'A' + 'a')
+			continue
+		}
+		sb.WriteByte(name[i])
+	}
+	return sb.String()
+}
+
+func isASCIILower(c byte) bool { return c >= 'a' && c <= 'z' }
+
+func isASCIIUpperRune(r rune) bool { return r >= 'A' && r <= 'Z' }
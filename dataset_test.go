@@ -0,0 +1,74 @@
+package dom
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/typelate/dom/spec"
+)
+
+func TestElement_Dataset(t *testing.T) {
+	// language=html
+	const textHTML = `<!DOCTYPE html>
+<html lang="us-en">
+<head><title>Dataset</title></head>
+<body><div id="user" data-user-id="42" data-x="1" data--y="2" data-first-name="Peach" title="t"></div></body>
+</html>`
+
+	t.Run("Get", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#user")
+		dataset := div.Dataset()
+		assert.Equal(t, "42", dataset.Get("userId"))
+		assert.True(t, dataset.Has("firstName"))
+		assert.Equal(t, "2", dataset.Get("Y"))
+		assert.False(t, dataset.Has("user-id"))
+		assert.False(t, dataset.Has("title"))
+		assert.Equal(t, "", dataset.Get("missing"))
+	})
+	t.Run("All", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#user")
+		assert.Equal(t, map[string]string{
+			"userId":    "42",
+			"x":         "1",
+			"Y":         "2",
+			"firstName": "Peach",
+		}, maps.Collect(div.Dataset().All()))
+	})
+	t.Run("Set", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#user")
+		dataset := div.Dataset()
+		require.NoError(t, dataset.Set("userId", "7"))
+		assert.Equal(t, "7", div.GetAttribute("data-user-id"))
+		require.NoError(t, dataset.Set("sessionToken", "abc"))
+		assert.Equal(t, "abc", div.GetAttribute("data-session-token"))
+		require.NoError(t, dataset.Set("-Z", "z"))
+		assert.Equal(t, "z", div.GetAttribute("data---z"))
+		assert.Equal(t, "z", dataset.Get("-Z"))
+	})
+	t.Run("Set errors", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#user")
+		dataset := div.Dataset()
+		assert.ErrorIs(t, dataset.Set("user-id", "1"), spec.ErrSyntax)
+		assert.ErrorIs(t, dataset.Set("a b", "1"), spec.ErrInvalidCharacter)
+		assert.False(t, div.HasAttribute("data-a b"))
+	})
+	t.Run("Delete", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#user")
+		dataset := div.Dataset()
+		dataset.Delete("firstName")
+		assert.False(t, div.HasAttribute("data-first-name"))
+		dataset.Delete("missing")
+		assert.True(t, div.HasAttribute("data-user-id"))
+	})
+	t.Run("uppercase attribute names are skipped", func(t *testing.T) {
+		document, _ := parseDocument(t, textHTML, "")
+		el, err := document.CreateElementNS(spec.SVGNamespace, "rect")
+		require.NoError(t, err)
+		el.SetAttribute("data-Camel", "1")
+		el.SetAttribute("data-ok", "2")
+		assert.Equal(t, map[string]string{"ok": "2"}, maps.Collect(el.Dataset().All()))
+	})
+}
@@ -0,0 +1,96 @@
+package dom
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+type DocumentType struct {
+	node *html.Node
+}
+
+func (d *DocumentType) Name() string     { return d.node.Data }
+func (d *DocumentType) PublicID() string { return doctypeIdentifier(d.node, "public") }
+func (d *DocumentType) SystemID() string { return doctypeIdentifier(d.node, "system") }
+
+func (d *DocumentType) NodeType() spec.NodeType          { return nodeType(d.node.Type) }
+func (d *DocumentType) IsConnected() bool                { return isConnected(d.node) }
+func (d *DocumentType) OwnerDocument() spec.Document     { return ownerDocument(d.node) }
+func (d *DocumentType) ParentNode() spec.Node            { return parentNode(d.node) }
+func (d *DocumentType) ParentElement() spec.Element      { return parentElement(d.node) }
+func (d *DocumentType) PreviousSibling() spec.ChildNode  { return previousSibling(d.node) }
+func (d *DocumentType) NextSibling() spec.ChildNode      { return nextSibling(d.node) }
+func (d *DocumentType) Before(nodes ...spec.Node)        { before(d.node, nodes) }
+func (d *DocumentType) After(nodes ...spec.Node)         { after(d.node, nodes) }
+func (d *DocumentType) ReplaceWith(nodes ...spec.Node)   { replaceWith(d.node, nodes) }
+func (d *DocumentType) Remove()                          { remove(d.node) }
+func (d *DocumentType) CloneNode(deep bool) spec.Node    { return NewNode(cloneNode(d.node, deep)) }
+func (d *DocumentType) IsSameNode(other spec.Node) bool  { return isSameNode(d.node, other) }
+func (d *DocumentType) IsEqualNode(other spec.Node) bool { return isEqualNode(d.node, other) }
+func (d *DocumentType) GetRootNode() spec.Node           { return getRootNode(d.node) }
+
+func (d *DocumentType) Ancestors() iter.Seq[spec.Node]              { return ancestors(d.node) }
+func (d *DocumentType) FollowingSiblings() iter.Seq[spec.ChildNode] { return followingSiblings(d.node) }
+func (d *DocumentType) PrecedingSiblings() iter.Seq[spec.ChildNode] { return precedingSiblings(d.node) }
+
+// Length returns zero. See https://dom.spec.whatwg.org/#concept-node-length
+func (d *DocumentType) Length() int { return 0 }
+
+// TextContent returns an empty string.
+// The spec says it should return null
+// https://developer.mozilla.org/en-US/docs/Web/API/Node/textContent
+func (d *DocumentType) TextContent() string { return "" }
+
+func (d *DocumentType) LookupNamespaceURI(prefix string) string {
+	return lookupNamespaceURI(d.node, prefix)
+}
+func (d *DocumentType) LookupPrefix(namespace string) string { return lookupPrefix(d.node, namespace) }
+
+func (d *DocumentType) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
+	return compareDocumentPosition(d.node, other)
+}
+
+func (d *DocumentType) String() string { return outerHTML(d.node) }
+
+// doctypeIdentifier reads the public or system identifier x/net/html stores in
+// the attributes of a html.DoctypeNode.
+func doctypeIdentifier(node *html.Node, key string) string {
+	for _, att := range node.Attr {
+		if att.Key == key {
+			return att.Val
+		}
+	}
+	return ""
+}
+
+func doctype(document *html.Node) spec.DocumentType {
+	for c := document.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.DoctypeNode {
+			return &DocumentType{node: c}
+		}
+	}
+	return nil
+}
+
+// createDocumentType is based on https://dom.spec.whatwg.org/#dom-domimplementation-createdocumenttype
+func createDocumentType(name, publicID, systemID string) (*html.Node, error) {
+	if i := strings.IndexAny(name, "\t\n\f\r >\x00"); i >= 0 {
+		return nil, fmt.Errorf("%w: doctype name %q contains %q", spec.ErrInvalidCharacter, name, name[i])
+	}
+	node := &html.Node{
+		Type: html.DoctypeNode,
+		Data: name,
+	}
+	if publicID != "" {
+		node.Attr = append(node.Attr, html.Attribute{Key: "public", Val: publicID})
+	}
+	if systemID != "" {
+		node.Attr = append(node.Attr, html.Attribute{Key: "system", Val: systemID})
+	}
+	return node, nil
+}
@@ -0,0 +1,90 @@
+package dom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/typelate/dom/spec"
+)
+
+var _ spec.DocumentType = (*DocumentType)(nil)
+
+func TestDocument_Doctype(t *testing.T) {
+	t.Run("html", func(t *testing.T) {
+		// language=html
+		document, _ := parseDocument(t, `<!DOCTYPE html><html lang="us-en"><head><title></title></head><body></body></html>`, "")
+
+		doctype := document.Doctype()
+		require.NotNil(t, doctype)
+		assert.Equal(t, spec.NodeTypeDocumentType, doctype.NodeType())
+		assert.Equal(t, "html", doctype.Name())
+		assert.Empty(t, doctype.PublicID())
+		assert.Empty(t, doctype.SystemID())
+		assert.True(t, doctype.IsConnected())
+		assert.True(t, doctype.OwnerDocument().IsSameNode(document))
+		assert.Equal(t, "<!DOCTYPE html>", doctype.(*DocumentType).String())
+	})
+	t.Run("legacy", func(t *testing.T) {
+		// language=html
+		document, _ := parseDocument(t, `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd"><html><head><title></title></head><body></body></html>`, "")
+
+		doctype := document.Doctype()
+		require.NotNil(t, doctype)
+		assert.Equal(t, "html", doctype.Name())
+		assert.Equal(t, "-//W3C//DTD HTML 4.01//EN", doctype.PublicID())
+		assert.Equal(t, "http://www.w3.org/TR/html4/strict.dtd", doctype.SystemID())
+	})
+	t.Run("missing", func(t *testing.T) {
+		// language=html
+		document, _ := parseDocument(t, `<html><head><title></title></head><body></body></html>`, "")
+		assert.Nil(t, document.Doctype())
+	})
+	t.Run("navigation", func(t *testing.T) {
+		// language=html
+		document, html := parseDocument(t, `<!DOCTYPE html><html><head><title></title></head><body></body></html>`, "html")
+
+		previous, ok := html.PreviousSibling().(*DocumentType)
+		require.True(t, ok)
+		assert.True(t, previous.IsSameNode(document.Doctype()))
+		assert.True(t, previous.NextSibling().IsSameNode(html))
+		assert.Nil(t, previous.ParentElement())
+		assert.Zero(t, previous.Length())
+	})
+}
+
+func TestDOMImplementation_CreateDocumentType(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		var document *Document
+		doctype, err := document.Implementation().CreateDocumentType("html", "-//W3C//DTD HTML 4.01//EN", "http://www.w3.org/TR/html4/strict.dtd")
+		require.NoError(t, err)
+		assert.Equal(t, "html", doctype.Name())
+		assert.Equal(t, "-//W3C//DTD HTML 4.01//EN", doctype.PublicID())
+		assert.Equal(t, "http://www.w3.org/TR/html4/strict.dtd", doctype.SystemID())
+		assert.Equal(t, `<!DOCTYPE html PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd">`, doctype.(*DocumentType).String())
+		assert.False(t, doctype.IsConnected())
+
+		clone := doctype.CloneNode(false).(spec.DocumentType)
+		assert.Equal(t, doctype.PublicID(), clone.PublicID())
+	})
+	t.Run("invalid name", func(t *testing.T) {
+		var document *Document
+		for _, name := range []string{"a b", "a>", "a\x00"} {
+			doctype, err := document.Implementation().CreateDocumentType(name, "", "")
+			assert.ErrorIs(t, err, spec.ErrInvalidCharacter)
+			assert.Nil(t, doctype)
+		}
+	})
+}
+
+func TestDocumentType_IsEqualNode(t *testing.T) {
+	var document *Document
+	a, err := document.Implementation().CreateDocumentType("html", "", "about:legacy-compat")
+	require.NoError(t, err)
+	b, err := document.Implementation().CreateDocumentType("html", "", "")
+	require.NoError(t, err)
+
+	assert.True(t, a.IsEqualNode(a.CloneNode(false)))
+	assert.False(t, a.IsEqualNode(b))
+}
@@ -4,7 +4,6 @@ import (
 	"iter"
 	"strings"
 
-	"github.com/andybalholm/cascadia"
 	"golang.org/x/net/html"
 	"golang.org/x/net/html/atom"
 
@@ -15,37 +14,125 @@ type Document struct {
 	node *html.Node
 }
 
-func (d *Document) Head() spec.Element { return d.QuerySelector("head") }
-func (d *Document) Body() spec.Element { return d.QuerySelector("body") }
+func (d *Document) Head() spec.Element { return htmlNodeToDomElement(documentHead(d.node)) }
+func (d *Document) Body() spec.Element { return htmlNodeToDomElement(documentBody(d.node)) }
 
-func (d *Document) String() string                  { return outerHTML(d.node) }
-func (d *Document) NodeType() spec.NodeType         { return nodeType(d.node.Type) }
-func (d *Document) CloneNode(deep bool) spec.Node   { return NewNode(cloneNode(d.node, deep)) }
-func (d *Document) IsSameNode(other spec.Node) bool { return isSameNode(d.node, other) }
+func (d *Document) DocumentElement() spec.Element {
+	return htmlNodeToDomElement(documentElement(d.node))
+}
+
+func (d *Document) GetElementByID(elementID string) spec.Element {
+	return htmlNodeToDomElement(getElementByID(d.node, elementID))
+}
+
+func (d *Document) Title() string         { return documentTitle(d.node) }
+func (d *Document) SetTitle(title string) { setDocumentTitle(d.node, title) }
+
+func (d *Document) GetElementsByName(elementName string) spec.NodeList[spec.Element] {
+	return filterDescendants(d.node, func(n *html.Node) bool {
+		return n.Namespace == "" && hasAttribute(n, "name") && getAttribute(n, "name") == elementName
+	})
+}
+
+func (d *Document) Forms() spec.ElementCollection   { return htmlElementsByAtom(d.node, atom.Form) }
+func (d *Document) Images() spec.ElementCollection  { return htmlElementsByAtom(d.node, atom.Img) }
+func (d *Document) Embeds() spec.ElementCollection  { return htmlElementsByAtom(d.node, atom.Embed) }
+func (d *Document) Scripts() spec.ElementCollection { return htmlElementsByAtom(d.node, atom.Script) }
+
+// Links returns the a and area elements with an href attribute.
+func (d *Document) Links() spec.ElementCollection {
+	return filterDescendants(d.node, func(n *html.Node) bool {
+		return n.Namespace == "" && (n.DataAtom == atom.A || n.DataAtom == atom.Area) && hasAttribute(n, "href")
+	})
+}
+
+func (d *Document) Doctype() spec.DocumentType { return doctype(d.node) }
+func (d *Document) Implementation() spec.DOMImplementation {
+	return &DOMImplementation{document: d}
+}
+
+func (d *Document) String() string                   { return outerHTML(d.node) }
+func (d *Document) NodeType() spec.NodeType          { return nodeType(d.node.Type) }
+func (d *Document) CloneNode(deep bool) spec.Node    { return NewNode(cloneNode(d.node, deep)) }
+func (d *Document) IsSameNode(other spec.Node) bool  { return isSameNode(d.node, other) }
+func (d *Document) IsEqualNode(other spec.Node) bool { return isEqualNode(d.node, other) }
+func (d *Document) GetRootNode() spec.Node           { return getRootNode(d.node) }
 func (d *Document) GetElementsByTagName(name string) spec.ElementCollection {
 	return getElementsByTagName(d.node, name)
 }
 
+func (d *Document) GetElementsByTagNameNS(namespace, localName string) spec.ElementCollection {
+	return getElementsByTagNameNS(d.node, namespace, localName)
+}
+
 func (d *Document) GetElementsByClassName(name string) spec.ElementCollection {
 	return getElementsByClassName(d.node, name)
 }
 
 func (d *Document) QuerySelector(query string) spec.Element {
-	return querySelector(d.node, query, false)
+	el, _ := d.QuerySelectorErr(query)
+	return el
 }
 
 func (d *Document) QuerySelectorAll(query string) spec.NodeList[spec.Element] {
-	return querySelectorAll(d.node, query, false)
+	list, _ := d.QuerySelectorAllErr(query)
+	return list
 }
 
 func (d *Document) QuerySelectorSequence(query string) iter.Seq[spec.Element] {
-	m := cascadia.MustCompile(query)
-	return func(yield func(spec.Element) bool) {
-		querySelectorSequence(d.node, m, yield)
+	seq, _ := d.QuerySelectorSequenceErr(query)
+	return seq
+}
+
+func (d *Document) QuerySelectorErr(query string) (spec.Element, error) {
+	return querySelector(d.node, query)
+}
+
+func (d *Document) QuerySelectorAllErr(query string) (spec.NodeList[spec.Element], error) {
+	list, err := querySelectorAll(d.node, query)
+	if err != nil {
+		return nodeListHTMLElements(nil), err
 	}
+	return list, nil
+}
+
+func (d *Document) QuerySelectorSequenceErr(query string) (iter.Seq[spec.Element], error) {
+	return querySelectorEach(d.node, query)
+}
+
+func (d *Document) QuerySelectorCompiled(selector *Selector) spec.Element {
+	return querySelectorMatcher(d.node, selector.matcher)
+}
+
+func (d *Document) QuerySelectorAllCompiled(selector *Selector) spec.NodeList[spec.Element] {
+	return querySelectorAllMatcher(d.node, selector.matcher)
+}
+
+func (d *Document) QuerySelectorSequenceCompiled(selector *Selector) iter.Seq[spec.Element] {
+	return querySelectorEachMatcher(d.node, selector.matcher)
 }
 
 func (d *Document) Contains(other spec.Node) bool { return contains(d.node, other) }
+func (d *Document) Normalize()                    { normalize(d.node) }
+
+func (d *Document) Descendants() iter.Seq[spec.Node]        { return descendantNodes(d.node) }
+func (d *Document) ChildNodesSeq() iter.Seq[spec.ChildNode] { return childNodesSeq(d.node) }
+func (d *Document) ChildrenSeq() iter.Seq[spec.Element]     { return childrenSeq(d.node) }
+
+func (d *Document) AddEventListener(eventType string, listener spec.EventListener, options spec.AddEventListenerOptions) {
+	addEventListener(d.node, eventType, listener, options)
+}
+
+func (d *Document) RemoveEventListener(eventType string, listener spec.EventListener, options spec.EventListenerOptions) {
+	removeEventListener(d.node, eventType, listener, options)
+}
+
+func (d *Document) DispatchEvent(event spec.Event) (bool, error) { return dispatchEvent(d.node, event) }
+
+func (d *Document) LookupNamespaceURI(prefix string) string {
+	return lookupNamespaceURI(d.node, prefix)
+}
+func (d *Document) LookupPrefix(namespace string) string { return lookupPrefix(d.node, namespace) }
 
 func (d *Document) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
 	return compareDocumentPosition(d.node, other)
@@ -56,34 +143,207 @@ func (d *Document) CompareDocumentPosition(other spec.Node) spec.DocumentPositio
 // https://developer.mozilla.org/en-US/docs/Web/API/Node/textContent
 func (d *Document) TextContent() string { return "" }
 
-func (*Document) CreateElement(localName string) spec.Element {
+func (d *Document) CreateElement(localName string) spec.Element {
 	localName = strings.ToLower(localName)
-	return &Element{
-		node: &html.Node{
-			DataAtom: atom.Lookup([]byte(localName)),
-			Type:     html.ElementNode,
-			Data:     localName,
-		},
-	}
+	return newElement(d.own(&html.Node{
+		DataAtom: atom.Lookup([]byte(localName)),
+		Type:     html.ElementNode,
+		Data:     localName,
+	}))
 }
 
-func (*Document) CreateElementIs(localName, is string) spec.Element {
+func (d *Document) CreateElementIs(localName, is string) spec.Element {
 	localName = strings.ToLower(localName)
-	return &Element{
-		node: &html.Node{
-			DataAtom: atom.Lookup([]byte(localName)),
-			Type:     html.ElementNode,
-			Data:     localName,
-			Attr:     []html.Attribute{{Key: "is", Val: is}},
-		},
+	return newElement(d.own(&html.Node{
+		DataAtom: atom.Lookup([]byte(localName)),
+		Type:     html.ElementNode,
+		Data:     localName,
+		Attr:     []html.Attribute{{Key: "is", Val: is}},
+	}))
+}
+
+func (d *Document) CreateElementNS(namespace, qualifiedName string) (spec.Element, error) {
+	node, err := createElementNS(namespace, qualifiedName)
+	if err != nil {
+		return nil, err
 	}
+	return newElement(d.own(node)), nil
 }
 
-func (*Document) CreateTextNode(text string) spec.Text {
+func (d *Document) CreateTextNode(text string) spec.Text {
 	return &Text{
-		node: &html.Node{
+		node: d.own(&html.Node{
 			Type: html.TextNode,
 			Data: text,
-		},
+		}),
 	}
 }
+
+func (d *Document) CreateComment(data string) spec.Comment {
+	return &Comment{
+		node: d.own(&html.Node{
+			Type: html.CommentNode,
+			Data: data,
+		}),
+	}
+}
+
+func (d *Document) CreateDocumentFragment() spec.DocumentFragment {
+	return &DocumentFragment{node: d.own(&html.Node{Type: documentFragmentNode})}
+}
+
+func (d *Document) ImportNode(node spec.Node, deep bool) (spec.Node, error) {
+	return importNode(d, node, deep)
+}
+
+func (d *Document) AdoptNode(node spec.Node) (spec.Node, error) { return adoptNode(d, node) }
+
+// DOMImplementation is returned by Document.Implementation.
+type DOMImplementation struct {
+	document *Document
+}
+
+func (i *DOMImplementation) CreateDocumentType(name, publicID, systemID string) (spec.DocumentType, error) {
+	node, err := createDocumentType(name, publicID, systemID)
+	if err != nil {
+		return nil, err
+	}
+	return &DocumentType{node: i.document.own(node)}, nil
+}
+
+func (d *Document) CreateAttribute(localName string) spec.Attr {
+	return d.ownAttr(&Attr{attr: html.Attribute{Key: strings.ToLower(localName)}})
+}
+
+// documentHead is based on https://html.spec.whatwg.org/#the-head-element-2
+func documentHead(document *html.Node) *html.Node {
+	root := documentElement(document)
+	if root == nil || root.Namespace != "" || root.DataAtom != atom.Html {
+		return nil
+	}
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Namespace == "" && c.DataAtom == atom.Head {
+			return c
+		}
+	}
+	return nil
+}
+
+// documentBody is based on https://html.spec.whatwg.org/#the-body-element-2
+func documentBody(document *html.Node) *html.Node {
+	root := documentElement(document)
+	if root == nil || root.Namespace != "" || root.DataAtom != atom.Html {
+		return nil
+	}
+	for c := root.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Namespace == "" && (c.DataAtom == atom.Body || c.DataAtom == atom.Frameset) {
+			return c
+		}
+	}
+	return nil
+}
+
+// getElementByID is based on https://dom.spec.whatwg.org/#dom-nonelementparentnode-getelementbyid
+func getElementByID(node *html.Node, elementID string) *html.Node {
+	if elementID == "" {
+		return nil
+	}
+	for n := range descendants(node) {
+		if n.Type == html.ElementNode && getAttribute(n, "id") == elementID {
+			return n
+		}
+	}
+	return nil
+}
+
+// titleElement is based on https://html.spec.whatwg.org/#the-title-element-2
+func titleElement(document *html.Node) *html.Node {
+	if root := documentElement(document); root != nil && root.Namespace == "svg" && root.Data == "svg" {
+		return svgTitleChild(root)
+	}
+	for n := range descendants(document) {
+		if n.Type == html.ElementNode && n.Namespace == "" && n.DataAtom == atom.Title {
+			return n
+		}
+	}
+	return nil
+}
+
+func svgTitleChild(node *html.Node) *html.Node {
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Namespace == "svg" && c.Data == "title" {
+			return c
+		}
+	}
+	return nil
+}
+
+// documentTitle is based on https://html.spec.whatwg.org/#document.title
+func documentTitle(document *html.Node) string {
+	title := titleElement(document)
+	if title == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for c := title.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			sb.WriteString(c.Data)
+		}
+	}
+	return strings.Join(strings.FieldsFunc(sb.String(), isASCIIWhitespace), " ")
+}
+
+// setDocumentTitle is based on https://html.spec.whatwg.org/#document.title
+func setDocumentTitle(document *html.Node, value string) {
+	root := documentElement(document)
+	if root == nil {
+		return
+	}
+	var title *html.Node
+	switch {
+	case root.Namespace == "svg" && root.Data == "svg":
+		title = svgTitleChild(root)
+		if title == nil {
+			title = &html.Node{Type: html.ElementNode, Namespace: "svg", Data: "title", DataAtom: atom.Title}
+			insertHTMLNodes(root, root.FirstChild, []*html.Node{title})
+		}
+	case root.Namespace == "":
+		title = titleElement(document)
+		if title == nil {
+			head := documentHead(document)
+			if head == nil {
+				return
+			}
+			title = &html.Node{Type: html.ElementNode, Data: "title", DataAtom: atom.Title}
+			insertHTMLNodes(head, nil, []*html.Node{title})
+		}
+	default:
+		return
+	}
+	stringReplaceAll(title, value)
+}
+
+// stringReplaceAll is based on https://dom.spec.whatwg.org/#string-replace-all
+func stringReplaceAll(node *html.Node, value string) {
+	var nodes []spec.Node
+	if value != "" {
+		nodes = append(nodes, &Text{node: &html.Node{Type: html.TextNode, Data: value}})
+	}
+	replaceChildren(node, nodes)
+}
+
+// isASCIIWhitespace is based on https://infra.spec.whatwg.org/#ascii-whitespace
+func isASCIIWhitespace(r rune) bool {
+	switch r {
+	case '\t', '\n', '\f', '\r', ' ':
+		return true
+	default:
+		return false
+	}
+}
+
+func htmlElementsByAtom(document *html.Node, a atom.Atom) *liveElements {
+	return filterDescendants(document, func(n *html.Node) bool {
+		return n.Namespace == "" && n.DataAtom == a
+	})
+}
@@ -125,7 +125,9 @@ func TestDocument_GetElementsByClassName(t *testing.T) {
 		require.NoError(t, err)
 		document := &Document{node: parsedDocument}
 		result := document.GetElementsByClassName("nothing-has-this-class")
-		require.Nil(t, result)
+		require.NotNil(t, result)
+		assert.Equal(t, 0, result.Length())
+		assert.Nil(t, result.Item(0))
 	})
 
 	t.Run("element found", func(t *testing.T) {
@@ -394,3 +396,176 @@ func TestDocument_CreateTextNode(t *testing.T) {
 
 	assert.Equal(t, exp, got)
 }
+
+func TestDocument_accessors(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang='us-en'>
+<head><title>
+	Hello,
+	world!
+</title><script src='a.js'></script></head>
+<body>
+	<form name='search'><input name='q'><input name='q'></form>
+	<img src='a.png' name='q' id='first'><img src='b.png' id='first'>
+	<a href='/'>home</a><a name='anchor'>no href</a>
+	<map><area href='/area'></map>
+	<embed src='a.swf'>
+	<svg><title>ignored</title><a href='/svg'></a><script></script></svg>
+	<title>second</title>
+</body>
+</html>`
+	document, _ := parseDocument(t, textHTML, "")
+
+	t.Run("DocumentElement", func(t *testing.T) {
+		assert.Equal(t, "HTML", document.DocumentElement().TagName())
+	})
+	t.Run("Head", func(t *testing.T) {
+		assert.Equal(t, "HEAD", document.Head().TagName())
+	})
+	t.Run("Body", func(t *testing.T) {
+		assert.Equal(t, "BODY", document.Body().TagName())
+	})
+	t.Run("GetElementByID", func(t *testing.T) {
+		el := document.GetElementByID("first")
+		require.NotNil(t, el)
+		assert.Equal(t, "a.png", el.GetAttribute("src"))
+		assert.Nil(t, document.GetElementByID("missing"))
+		assert.Nil(t, document.GetElementByID(""))
+	})
+	t.Run("Title", func(t *testing.T) {
+		assert.Equal(t, "Hello, world!", document.Title())
+	})
+	t.Run("GetElementsByName", func(t *testing.T) {
+		assert.Equal(t, 3, document.GetElementsByName("q").Length())
+		assert.Equal(t, "INPUT", document.GetElementsByName("q").Item(0).TagName())
+		assert.Equal(t, 1, document.GetElementsByName("search").Length())
+		assert.Equal(t, 0, document.GetElementsByName("").Length())
+	})
+	t.Run("collections", func(t *testing.T) {
+		assert.Equal(t, 1, document.Forms().Length())
+		assert.Equal(t, "search", document.Forms().NamedItem("search").GetAttribute("name"))
+		assert.Equal(t, 2, document.Images().Length())
+		assert.Equal(t, 1, document.Embeds().Length())
+		assert.Equal(t, 1, document.Scripts().Length(), "excludes the SVG script")
+
+		links := document.Links()
+		require.Equal(t, 2, links.Length(), "excludes anchors without href and SVG links")
+		assert.Equal(t, "/", links.Item(0).GetAttribute("href"))
+		assert.Equal(t, "/area", links.Item(1).GetAttribute("href"))
+	})
+}
+
+func TestDocument_Head(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html><html><head></head><body><svg><head></head></svg></body></html>`
+	document, _ := parseDocument(t, textHTML, "")
+	head := document.Head()
+	require.NotNil(t, head)
+	assert.True(t, head.ParentNode().IsSameNode(document.DocumentElement()))
+
+	assert.Nil(t, (&Document{node: &html.Node{Type: html.DocumentNode}}).Head())
+}
+
+func TestDocument_Body(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html><html><head></head><frameset></frameset></html>`
+	document, _ := parseDocument(t, textHTML, "")
+	body := document.Body()
+	require.NotNil(t, body)
+	assert.Equal(t, "FRAMESET", body.TagName())
+}
+
+func TestDocument_SetTitle(t *testing.T) {
+	t.Run("existing", func(t *testing.T) {
+		// language=html
+		document, _ := parseDocument(t, `<!DOCTYPE html><html><head><title>  a   b  </title></head><body></body></html>`, "")
+		assert.Equal(t, "a b", document.Title())
+		document.SetTitle("  peach  ")
+		assert.Equal(t, "peach", document.Title())
+		assert.Equal(t, "<title>  peach  </title>", document.Head().InnerHTML())
+	})
+	t.Run("missing", func(t *testing.T) {
+		// language=html
+		document, _ := parseDocument(t, `<!DOCTYPE html><html><head><meta charset="utf-8"></head><body></body></html>`, "")
+		assert.Equal(t, "", document.Title())
+		document.SetTitle("peach")
+		assert.Equal(t, `<meta charset="utf-8"/><title>peach</title>`, document.Head().InnerHTML())
+	})
+	t.Run("empty", func(t *testing.T) {
+		// language=html
+		document, _ := parseDocument(t, `<!DOCTYPE html><html><head><title>peach</title></head><body></body></html>`, "")
+		document.SetTitle("")
+		assert.Equal(t, "<title></title>", document.Head().InnerHTML())
+	})
+	t.Run("svg", func(t *testing.T) {
+		var document *Document
+		svg, err := document.CreateElementNS(spec.SVGNamespace, "svg")
+		require.NoError(t, err)
+		doc := &Document{node: &html.Node{Type: html.DocumentNode}}
+		doc.node.AppendChild(svg.(*Element).node)
+
+		assert.Equal(t, "", doc.Title())
+		doc.SetTitle(" Icon ")
+		assert.Equal(t, "Icon", doc.Title())
+		assert.Equal(t, "<svg><title> Icon </title></svg>", svg.OuterHTML())
+	})
+	t.Run("no document element", func(t *testing.T) {
+		doc := &Document{node: &html.Node{Type: html.DocumentNode}}
+		assert.NotPanics(t, func() { doc.SetTitle("peach") })
+		assert.Equal(t, "", doc.Title())
+	})
+}
+
+func TestDocument_IsEqualNode(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang="us-en">
+<head><title></title></head>
+<body><p>Hello!</p></body>
+</html>`
+	a, _ := parseDocument(t, textHTML, "")
+	b, _ := parseDocument(t, textHTML, "")
+	assert.True(t, a.IsEqualNode(b))
+	assert.True(t, a.GetRootNode().IsSameNode(a))
+
+	b.Body().Append(b.CreateTextNode(" "))
+	assert.False(t, a.IsEqualNode(b))
+}
+
+func TestDocument_insertValidity(t *testing.T) {
+	const (
+		errElement = "HierarchyRequestError: a Document can only have one element child after its doctype"
+		errDoctype = "HierarchyRequestError: a Document can only have one doctype before its element"
+	)
+	// language=html
+	document, _ := parseDocument(t, `<!DOCTYPE html><html><head></head><body></body></html>`, "")
+	root := document.DocumentElement()
+	doctype, err := document.Implementation().CreateDocumentType("html", "", "")
+	require.NoError(t, err)
+
+	assert.PanicsWithError(t, errElement, func() { root.After(document.CreateElement("div")) })
+	assert.PanicsWithError(t, errElement, func() { document.Doctype().Before(document.CreateElement("div")) })
+	assert.PanicsWithError(t, "HierarchyRequestError: a Document can only have one element child", func() {
+		root.ReplaceWith(document.CreateElement("div"), document.CreateElement("p"))
+	})
+	assert.PanicsWithError(t, "HierarchyRequestError: a Text can not be a child of a Document", func() {
+		root.After(document.CreateTextNode("x"))
+	})
+	assert.PanicsWithError(t, errDoctype, func() { root.Before(doctype) })
+	assert.PanicsWithError(t, errDoctype, func() { root.After(doctype) })
+	assert.PanicsWithError(t, "HierarchyRequestError: a Document can not be inserted", func() {
+		root.AppendChild(document)
+	})
+	assert.Equal(t, `<!DOCTYPE html><html><head></head><body></body></html>`, document.String())
+
+	t.Run("valid", func(t *testing.T) {
+		root.After(document.CreateComment("end"))
+		document.Doctype().ReplaceWith(doctype)
+		html := document.CreateElement("html")
+		root.ReplaceWith(html)
+		assert.True(t, document.DocumentElement().IsSameNode(html))
+		assert.True(t, document.Doctype().IsSameNode(doctype))
+		assert.Equal(t, `<!DOCTYPE html><html></html><!--end-->`, document.String())
+	})
+}
@@ -2,10 +2,10 @@ package dom
 
 import (
 	"bytes"
+	"fmt"
 	"iter"
 	"strings"
 
-	"github.com/andybalholm/cascadia"
 	"golang.org/x/net/html"
 
 	"github.com/typelate/dom/spec"
@@ -15,23 +15,22 @@ type Element struct {
 	node *html.Node
 }
 
-func (e *Element) QuerySelectorSequence(query string) iter.Seq[spec.Element] {
-	m := cascadia.MustCompile(query)
-	return func(yield func(spec.Element) bool) {
-		querySelectorSequence(e.node, m, yield)
-	}
-}
-
-func (e *Element) NodeType() spec.NodeType         { return nodeType(e.node.Type) }
-func (e *Element) IsConnected() bool               { return isConnected(e.node) }
-func (e *Element) OwnerDocument() spec.Document    { return ownerDocument(e.node) }
-func (e *Element) ParentNode() spec.Node           { return parentNode(e.node) }
-func (e *Element) ParentElement() spec.Element     { return parentElement(e.node) }
-func (e *Element) PreviousSibling() spec.ChildNode { return previousSibling(e.node) }
-func (e *Element) NextSibling() spec.ChildNode     { return nextSibling(e.node) }
-func (e *Element) TextContent() string             { return textContent(e.node) }
-func (e *Element) CloneNode(deep bool) spec.Node   { return NewNode(cloneNode(e.node, deep)) }
-func (e *Element) IsSameNode(other spec.Node) bool { return isSameNode(e.node, other) }
+func (e *Element) NodeType() spec.NodeType          { return nodeType(e.node.Type) }
+func (e *Element) IsConnected() bool                { return isConnected(e.node) }
+func (e *Element) OwnerDocument() spec.Document     { return ownerDocument(e.node) }
+func (e *Element) ParentNode() spec.Node            { return parentNode(e.node) }
+func (e *Element) ParentElement() spec.Element      { return parentElement(e.node) }
+func (e *Element) PreviousSibling() spec.ChildNode  { return previousSibling(e.node) }
+func (e *Element) NextSibling() spec.ChildNode      { return nextSibling(e.node) }
+func (e *Element) TextContent() string              { return textContent(e.node) }
+func (e *Element) CloneNode(deep bool) spec.Node    { return NewNode(cloneNode(e.node, deep)) }
+func (e *Element) IsSameNode(other spec.Node) bool  { return isSameNode(e.node, other) }
+func (e *Element) IsEqualNode(other spec.Node) bool { return isEqualNode(e.node, other) }
+func (e *Element) GetRootNode() spec.Node           { return getRootNode(e.node) }
+func (e *Element) Before(nodes ...spec.Node)        { before(e.node, nodes) }
+func (e *Element) After(nodes ...spec.Node)         { after(e.node, nodes) }
+func (e *Element) ReplaceWith(nodes ...spec.Node)   { replaceWith(e.node, nodes) }
+func (e *Element) Remove()                          { remove(e.node) }
 func (e *Element) Length() int {
 	c := e.node.FirstChild
 	result := 0
@@ -42,6 +41,26 @@ func (e *Element) Length() int {
 	return result
 }
 
+func (e *Element) Ancestors() iter.Seq[spec.Node]              { return ancestors(e.node) }
+func (e *Element) FollowingSiblings() iter.Seq[spec.ChildNode] { return followingSiblings(e.node) }
+func (e *Element) PrecedingSiblings() iter.Seq[spec.ChildNode] { return precedingSiblings(e.node) }
+func (e *Element) Descendants() iter.Seq[spec.Node]            { return descendantNodes(e.node) }
+func (e *Element) ChildNodesSeq() iter.Seq[spec.ChildNode]     { return childNodesSeq(e.node) }
+func (e *Element) ChildrenSeq() iter.Seq[spec.Element]         { return childrenSeq(e.node) }
+
+func (e *Element) AddEventListener(eventType string, listener spec.EventListener, options spec.AddEventListenerOptions) {
+	addEventListener(e.node, eventType, listener, options)
+}
+
+func (e *Element) RemoveEventListener(eventType string, listener spec.EventListener, options spec.EventListenerOptions) {
+	removeEventListener(e.node, eventType, listener, options)
+}
+
+func (e *Element) DispatchEvent(event spec.Event) (bool, error) { return dispatchEvent(e.node, event) }
+
+func (e *Element) LookupNamespaceURI(prefix string) string { return lookupNamespaceURI(e.node, prefix) }
+func (e *Element) LookupPrefix(namespace string) string    { return lookupPrefix(e.node, namespace) }
+
 func (e *Element) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
 	return compareDocumentPosition(e.node, other)
 }
@@ -53,66 +72,112 @@ func (e *Element) ChildElementCount() int             { return childElementCount
 func (e *Element) Prepend(nodes ...spec.Node)         { prependNodes(e.node, nodes) }
 func (e *Element) Append(nodes ...spec.Node)          { appendNodes(e.node, nodes...) }
 func (e *Element) ReplaceChildren(nodes ...spec.Node) { replaceChildren(e.node, nodes) }
+func (e *Element) Normalize()                         { normalize(e.node) }
 func (e *Element) GetElementsByTagName(name string) spec.ElementCollection {
 	return getElementsByTagName(e.node, name)
 }
 
+func (e *Element) GetElementsByTagNameNS(namespace, localName string) spec.ElementCollection {
+	return getElementsByTagNameNS(e.node, namespace, localName)
+}
+
 func (e *Element) GetElementsByClassName(name string) spec.ElementCollection {
 	return getElementsByClassName(e.node, name)
 }
 
 func (e *Element) QuerySelector(query string) spec.Element {
-	return querySelector(e.node, query, false)
+	el, _ := e.QuerySelectorErr(query)
+	return el
 }
 
 func (e *Element) QuerySelectorAll(query string) spec.NodeList[spec.Element] {
-	return querySelectorAll(e.node, query, false)
+	list, _ := e.QuerySelectorAllErr(query)
+	return list
+}
+
+func (e *Element) QuerySelectorSequence(query string) iter.Seq[spec.Element] {
+	seq, _ := e.QuerySelectorSequenceErr(query)
+	return seq
+}
+
+func (e *Element) QuerySelectorErr(query string) (spec.Element, error) {
+	return querySelector(e.node, query)
+}
+
+func (e *Element) QuerySelectorAllErr(query string) (spec.NodeList[spec.Element], error) {
+	list, err := querySelectorAll(e.node, query)
+	if err != nil {
+		return nodeListHTMLElements(nil), err
+	}
+	return list, nil
+}
+
+func (e *Element) QuerySelectorSequenceErr(query string) (iter.Seq[spec.Element], error) {
+	return querySelectorEach(e.node, query)
 }
-func (e *Element) Closest(selector string) spec.Element { return closest(e.node, selector) }
-func (e *Element) Matches(selector string) bool         { return matches(e.node, selector) }
+
+func (e *Element) QuerySelectorCompiled(selector *Selector) spec.Element {
+	return querySelectorMatcher(e.node, selector.matcher)
+}
+
+func (e *Element) QuerySelectorAllCompiled(selector *Selector) spec.NodeList[spec.Element] {
+	return querySelectorAllMatcher(e.node, selector.matcher)
+}
+
+func (e *Element) QuerySelectorSequenceCompiled(selector *Selector) iter.Seq[spec.Element] {
+	return querySelectorEachMatcher(e.node, selector.matcher)
+}
+
+func (e *Element) ClosestCompiled(selector *Selector) spec.Element {
+	return closestMatcher(e.node, selector.matcher)
+}
+
+func (e *Element) MatchesCompiled(selector *Selector) bool { return selector.matcher.Match(e.node) }
+
+func (e *Element) Closest(selector string) spec.Element {
+	el, _ := e.ClosestErr(selector)
+	return el
+}
+
+func (e *Element) Matches(selector string) bool {
+	ok, _ := e.MatchesErr(selector)
+	return ok
+}
+
+func (e *Element) ClosestErr(selector string) (spec.Element, error) { return closest(e.node, selector) }
+func (e *Element) MatchesErr(selector string) (bool, error)         { return matches(e.node, selector) }
 
 func (e *Element) HasChildNodes() bool                  { return hasChildNodes(e.node) }
 func (e *Element) ChildNodes() spec.NodeList[spec.Node] { return childNodes(e.node) }
 func (e *Element) FirstChild() spec.ChildNode           { return firstChild(e.node) }
 func (e *Element) LastChild() spec.ChildNode            { return lastChild(e.node) }
 func (e *Element) Contains(other spec.Node) bool        { return contains(e.node, other) }
-func (e *Element) InsertBefore(node, child spec.ChildNode) spec.ChildNode {
+func (e *Element) InsertBefore(node spec.Node, child spec.ChildNode) spec.Node {
 	return insertBefore(e.node, node, child)
 }
-func (e *Element) AppendChild(node spec.ChildNode) spec.ChildNode { return appendChild(e.node, node) }
-func (e *Element) ReplaceChild(node, child spec.ChildNode) spec.ChildNode {
+func (e *Element) AppendChild(node spec.Node) spec.Node { return appendChild(e.node, node) }
+func (e *Element) ReplaceChild(node spec.Node, child spec.ChildNode) spec.ChildNode {
 	return replaceChild(e.node, node, child)
 }
 func (e *Element) RemoveChild(node spec.ChildNode) spec.ChildNode { return removeChild(e.node, node) }
 
-func (e *Element) TagName() string                 { return strings.ToUpper(e.node.Data) }
+func (e *Element) TagName() string                 { return tagName(e.node) }
+func (e *Element) NamespaceURI() string            { return elementNamespaceURI(e.node) }
+func (e *Element) Prefix() string                  { return elementPrefix(e.node) }
+func (e *Element) LocalName() string               { return elementLocalName(e.node) }
 func (e *Element) ID() string                      { return getAttribute(e.node, "id") }
 func (e *Element) ClassName() string               { return getAttribute(e.node, "class") }
+func (e *Element) ClassList() spec.DOMTokenList    { return classList(e.node) }
+func (e *Element) Dataset() spec.DOMStringMap      { return &DOMStringMap{node: e.node} }
+func (e *Element) Style() spec.CSSStyleDeclaration { return &CSSStyleDeclaration{node: e.node} }
 func (e *Element) GetAttribute(name string) string { return getAttribute(e.node, name) }
 
-func (e *Element) SetAttribute(name, value string) {
-	name = strings.ToLower(name)
-	for index, att := range e.node.Attr {
-		if att.Key == name {
-			e.node.Attr[index].Val = value
-			return
-		}
-	}
-	e.node.Attr = append(e.node.Attr, html.Attribute{
-		Key: name, Val: value,
-	})
-}
+func (e *Element) SetAttribute(name, value string) { setAttribute(e.node, name, value) }
 
 func (e *Element) RemoveAttribute(name string) {
-	name = strings.ToLower(name)
-	filtered := e.node.Attr[:0]
-	for _, att := range e.node.Attr {
-		if att.Key == name {
-			continue
-		}
-		filtered = append(filtered, att)
+	if i := attributeIndex(e.node, name); i >= 0 {
+		removeAttributeIndex(e.node, i)
 	}
-	e.node.Attr = filtered
 }
 
 func (e *Element) ToggleAttribute(name string) bool {
@@ -125,117 +190,96 @@ func (e *Element) ToggleAttribute(name string) bool {
 	return true
 }
 
-func (e *Element) HasAttribute(name string) bool {
-	name = strings.ToLower(name)
-	for _, att := range e.node.Attr {
-		if att.Key == name {
-			return true
-		}
+func (e *Element) HasAttribute(name string) bool { return attributeIndex(e.node, name) >= 0 }
+
+func (e *Element) GetAttributeNS(namespace, localName string) string {
+	if i := attributeIndexNS(e.node, namespace, localName); i >= 0 {
+		return e.node.Attr[i].Val
 	}
-	return false
+	return ""
 }
 
-func (e *Element) SetInnerHTML(s string) {
-	nodes, err := html.ParseFragment(strings.NewReader(s), &html.Node{Type: html.ElementNode})
-	if err != nil {
-		panic(err)
-	}
-	clearChildren(e.node)
-	for _, n := range nodes {
-		e.node.AppendChild(n)
-	}
+func (e *Element) SetAttributeNS(namespace, qualifiedName, value string) error {
+	return setAttributeNS(e.node, namespace, qualifiedName, value)
 }
 
-func (e *Element) InnerHTML() string {
-	var buf bytes.Buffer
-	c := e.node.FirstChild
-	for c != nil {
-		err := html.Render(&buf, c)
-		if err != nil {
-			panic(err)
-		}
-		c = c.NextSibling
+func (e *Element) RemoveAttributeNS(namespace, localName string) {
+	if i := attributeIndexNS(e.node, namespace, localName); i >= 0 {
+		removeAttributeIndex(e.node, i)
 	}
-	return buf.String()
 }
 
-func (e *Element) SetOuterHTML(s string) {
-	nodes, err := html.ParseFragment(strings.NewReader(s), &html.Node{Type: html.ElementNode})
-	if err != nil {
-		panic(err)
-	}
-	if len(nodes) == 0 {
-		return
-	}
-	if e.node.Parent == nil {
-		panic("browser: SetOuterHTML called on an unattached node")
-	}
-	for _, node := range nodes {
-		e.node.Parent.InsertBefore(node, e.node)
-	}
-	e.node.Parent.RemoveChild(e.node)
+func (e *Element) HasAttributeNS(namespace, localName string) bool {
+	return attributeIndexNS(e.node, namespace, localName) >= 0
 }
 
-func (e *Element) OuterHTML() string { return outerHTML(e.node) }
-func (e *Element) String() string    { return e.OuterHTML() }
+func (e *Element) Attributes() spec.NamedNodeMap          { return &NamedNodeMap{element: e.node} }
+func (e *Element) GetAttributeNames() []string            { return getAttributeNames(e.node) }
+func (e *Element) HasAttributes() bool                    { return len(e.node.Attr) > 0 }
+func (e *Element) GetAttributeNode(name string) spec.Attr { return getAttributeNode(e.node, name) }
 
-type siblingElements struct {
-	firstChild *html.Node
+func (e *Element) SetAttributeNode(attr spec.Attr) (spec.Attr, error) {
+	return setAttributeNode(e.node, attr)
 }
 
-func (list siblingElements) Length() int {
-	result := 0
-	for c := list.firstChild; c != nil; c = c.NextSibling {
-		if c.Type != html.ElementNode {
-			continue
-		}
-		result++
-	}
-	return result
+func (e *Element) RemoveAttributeNode(attr spec.Attr) (spec.Attr, error) {
+	return removeAttributeNode(e.node, attr)
 }
 
-func (list siblingElements) Item(index int) spec.Element {
-	childIndex := 0
-	for c := list.firstChild; c != nil; c = c.NextSibling {
-		if c.Type != html.ElementNode {
-			continue
-		}
-		if childIndex == index {
-			return &Element{node: c}
-		}
-		childIndex++
+// SetInnerHTML is based on https://html.spec.whatwg.org/#dom-element-innerhtml
+// s is parsed with the element as the context.
+func (e *Element) SetInnerHTML(s string) error {
+	nodes, err := parseHTMLFragment(e.node, s)
+	if err != nil {
+		return err
 	}
+	replaceAllHTMLNodes(e.node, nodes)
 	return nil
 }
 
-func (list siblingElements) NamedItem(name string) spec.Element {
-	for c := list.firstChild; c != nil; c = c.NextSibling {
-		if c.Type != html.ElementNode {
-			continue
-		}
-		if isNamed(c, name) {
-			return &Element{node: c}
+// SetHTMLUnsafe is based on https://html.spec.whatwg.org/#dom-element-sethtmlunsafe
+// Declarative shadow roots are not supported, so it is the same as
+// SetInnerHTML.
+func (e *Element) SetHTMLUnsafe(s string) error { return e.SetInnerHTML(s) }
+
+func (e *Element) InnerHTML() string {
+	var buf bytes.Buffer
+	c := e.node.FirstChild
+	for c != nil {
+		err := html.Render(&buf, c)
+		if err != nil {
+			panic(err)
 		}
+		c = c.NextSibling
 	}
-	return nil
+	return buf.String()
 }
 
-type elementList []*html.Node
+// GetHTML is based on https://html.spec.whatwg.org/#dom-element-gethtml
+// There are no shadow roots to serialize, so it returns InnerHTML.
+func (e *Element) GetHTML(spec.GetHTMLOptions) string { return e.InnerHTML() }
 
-func (list elementList) Length() int { return len(list) }
-
-func (list elementList) Item(index int) spec.Element {
-	if index < 0 || index >= len(list) {
+// SetOuterHTML is based on https://html.spec.whatwg.org/#dom-element-outerhtml
+// s is parsed with the parent as the context. It does nothing when the
+// element has no parent.
+func (e *Element) SetOuterHTML(s string) error {
+	parent := e.node.Parent
+	if parent == nil {
 		return nil
 	}
-	return &Element{node: list[index]}
-}
-
-func (list elementList) NamedItem(name string) spec.Element {
-	for _, el := range list {
-		if isNamed(el, name) {
-			return &Element{node: el}
-		}
+	if parent.Type == html.DocumentNode {
+		return fmt.Errorf("%w: can not replace the document element", spec.ErrNoModificationAllowed)
+	}
+	nodes, err := parseHTMLFragment(parent, s)
+	if err != nil {
+		return err
 	}
+	previous, next := e.node.PrevSibling, e.node.NextSibling
+	unlinkHTMLNode(e.node)
+	linkHTMLNodes(parent, next, nodes)
+	queueTreeMutationRecord(parent, nodes, []*html.Node{e.node}, previous, next)
 	return nil
 }
+
+func (e *Element) OuterHTML() string { return outerHTML(e.node) }
+func (e *Element) String() string    { return e.OuterHTML() }
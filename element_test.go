@@ -143,6 +143,132 @@ func TestElement_NextSibling(t *testing.T) {
 	require.Equal(t, "youngest", getAttribute(siblingNode.node, "id"))
 }
 
+func TestElement_Ancestors(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang='us-en'>
+<head><title></title></head>
+<body><main><div id='target'></div></main></body>
+</html>`
+	document, target := parseDocument(t, textHTML, "#target")
+
+	var names []string
+	for n := range target.Ancestors() {
+		names = append(names, nodeTagName(n))
+	}
+	assert.Equal(t, []string{"MAIN", "BODY", "HTML", ""}, names)
+
+	var last spec.Node
+	for n := range target.Ancestors() {
+		last = n
+	}
+	assert.True(t, last.IsSameNode(document))
+
+	for n := range target.Ancestors() {
+		assert.Equal(t, "MAIN", nodeTagName(n))
+		break
+	}
+}
+
+func TestElement_FollowingSiblings(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang='us-en'>
+<head><title></title></head>
+<body><i></i><b></b><div id='target'></div><span></span>text<em></em></body>
+</html>`
+	_, target := parseDocument(t, textHTML, "#target")
+
+	t.Run("following", func(t *testing.T) {
+		var names []string
+		for n := range target.FollowingSiblings() {
+			names = append(names, nodeTagName(n))
+		}
+		assert.Equal(t, []string{"SPAN", "", "EM", ""}, names)
+	})
+	t.Run("preceding nearest first", func(t *testing.T) {
+		var names []string
+		for n := range target.PrecedingSiblings() {
+			names = append(names, nodeTagName(n))
+		}
+		assert.Equal(t, []string{"B", "I"}, names)
+	})
+	t.Run("early exit", func(t *testing.T) {
+		count := 0
+		for range target.FollowingSiblings() {
+			count++
+			break
+		}
+		assert.Equal(t, 1, count)
+	})
+	t.Run("remove while iterating", func(t *testing.T) {
+		for n := range target.FollowingSiblings() {
+			n.Remove()
+		}
+		assert.Nil(t, target.NextSibling())
+	})
+}
+
+func TestElement_Descendants(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang='us-en'>
+<head><title></title></head>
+<body><main id='target'><h1>Title</h1><p>Some <em>text</em></p><template><span></span></template></main></body>
+</html>`
+	_, target := parseDocument(t, textHTML, "#target")
+
+	t.Run("tree order without template contents", func(t *testing.T) {
+		var names []string
+		for n := range target.Descendants() {
+			if n.NodeType() == spec.NodeTypeText {
+				names = append(names, "#text")
+				continue
+			}
+			names = append(names, nodeTagName(n))
+		}
+		assert.Equal(t, []string{"H1", "#text", "P", "#text", "EM", "#text", "TEMPLATE"}, names)
+	})
+	t.Run("early exit", func(t *testing.T) {
+		var first spec.Node
+		for n := range target.Descendants() {
+			if n.NodeType() == spec.NodeTypeText {
+				first = n
+				break
+			}
+		}
+		require.NotNil(t, first)
+		assert.Equal(t, "Title", first.TextContent())
+	})
+	t.Run("child nodes", func(t *testing.T) {
+		var names []string
+		for n := range target.ChildNodesSeq() {
+			names = append(names, nodeTagName(n))
+		}
+		assert.Equal(t, []string{"H1", "P", "TEMPLATE"}, names)
+	})
+	t.Run("children", func(t *testing.T) {
+		p := target.QuerySelector("p")
+		var names []string
+		for el := range p.ChildrenSeq() {
+			names = append(names, el.TagName())
+		}
+		assert.Equal(t, []string{"EM"}, names)
+	})
+	t.Run("all", func(t *testing.T) {
+		list := target.ChildNodes()
+		var names []string
+		for i, n := range list.All() {
+			assert.True(t, n.IsSameNode(list.Item(i)))
+			names = append(names, nodeTagName(n))
+			if i == 1 {
+				break
+			}
+		}
+		assert.Equal(t, []string{"H1", "P"}, names)
+	})
+}
+
 func TestElement_TextContent(t *testing.T) {
 	t.Run("body no text", func(t *testing.T) {
 		// language=html
@@ -283,6 +409,59 @@ func TestElement_QuerySelector(t *testing.T) {
 	})
 }
 
+func TestElement_QuerySelectorErr(t *testing.T) {
+	// language=html
+	_, div := parseDocument(t, `<!DOCTYPE html><html><head></head><body><div id="root"><p class="x">Hello</p></div></body></html>`, "#root")
+
+	t.Run("valid", func(t *testing.T) {
+		p, err := div.QuerySelectorErr("p.x")
+		require.NoError(t, err)
+		require.NotNil(t, p)
+		list, err := div.QuerySelectorAllErr("p")
+		require.NoError(t, err)
+		assert.Equal(t, 1, list.Length())
+		ok, err := p.MatchesErr(".x")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		el, err := p.ClosestErr("#root")
+		require.NoError(t, err)
+		assert.True(t, el.IsSameNode(div))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		const selector = "p["
+		assertSelectorError := func(t *testing.T, err error) {
+			t.Helper()
+			require.ErrorIs(t, err, spec.ErrSyntax)
+			var selectorErr *spec.SelectorError
+			require.ErrorAs(t, err, &selectorErr)
+			assert.Equal(t, selector, selectorErr.Selector)
+		}
+		el, err := div.QuerySelectorErr(selector)
+		assertSelectorError(t, err)
+		assert.Nil(t, el)
+		list, err := div.QuerySelectorAllErr(selector)
+		assertSelectorError(t, err)
+		assert.Equal(t, 0, list.Length())
+		_, err = div.QuerySelectorSequenceErr(selector)
+		assertSelectorError(t, err)
+		_, err = div.ClosestErr(selector)
+		assertSelectorError(t, err)
+		_, err = div.MatchesErr(selector)
+		assertSelectorError(t, err)
+	})
+	t.Run("invalid without error", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			assert.Nil(t, div.QuerySelector("p["))
+			assert.Equal(t, 0, div.QuerySelectorAll("p[").Length())
+			for range div.QuerySelectorSequence("p[") {
+				t.Error("expected no elements")
+			}
+			assert.Nil(t, div.Closest("p["))
+			assert.False(t, div.Matches("p["))
+		})
+	})
+}
+
 func TestElement_CompareDocumentPosition(t *testing.T) {
 	t.Run("same node", func(t *testing.T) {
 		// language=html
@@ -346,3 +525,299 @@ func TestElement_SetAttribute(t *testing.T) {
 		require.Len(t, n.Attr, 1)
 	})
 }
+
+func TestElement_Normalize(t *testing.T) {
+	var document *Document
+	div := document.CreateElement("div")
+	first := document.CreateTextNode("a")
+	span := document.CreateElement("span")
+	span.Append(document.CreateTextNode(""), document.CreateTextNode("x"), document.CreateTextNode("y"))
+	div.Append(
+		document.CreateTextNode(""),
+		first,
+		document.CreateTextNode("b"),
+		document.CreateTextNode(""),
+		document.CreateTextNode("c"),
+		span,
+		document.CreateTextNode("d"),
+		document.CreateComment("e"),
+		document.CreateTextNode(""),
+	)
+
+	div.Normalize()
+
+	require.Equal(t, 4, div.ChildNodes().Length())
+	assert.True(t, div.FirstChild().IsSameNode(first), "the first non-empty text node is kept")
+	assert.Equal(t, "abc", first.Data())
+	assert.Equal(t, 1, span.ChildNodes().Length())
+	assert.Equal(t, "xy", span.TextContent())
+	assert.Equal(t, "<div>abc<span>xy</span>d<!--e--></div>", div.OuterHTML())
+}
+
+func TestElement_Before(t *testing.T) {
+	parseTarget := func(t *testing.T) (*Element, *Element) {
+		// language=html
+		textHTML := `<!DOCTYPE html>
+<html lang='us-en'>
+<head><title></title></head>
+<body><div id='parent'><a></a><b id='target'></b><i></i></div></body>
+</html>`
+		_, parent := parseDocument(t, textHTML, "#parent")
+		return parent, parent.QuerySelector("#target").(*Element)
+	}
+
+	t.Run("nodes", func(t *testing.T) {
+		parent, target := parseTarget(t)
+		var document *Document
+		target.Before(document.CreateElement("p"), document.CreateTextNode("x"))
+		assert.Equal(t, `<a></a><p></p>x<b id="target"></b><i></i>`, parent.InnerHTML())
+	})
+	t.Run("previous sibling argument", func(t *testing.T) {
+		parent, target := parseTarget(t)
+		a := parent.FirstChild().(spec.Node)
+		i := parent.LastChild().(spec.Node)
+		target.Before(i, a)
+		assert.Equal(t, `<i></i><a></a><b id="target"></b>`, parent.InnerHTML())
+	})
+	t.Run("fragment", func(t *testing.T) {
+		parent, target := parseTarget(t)
+		nodes, err := html.ParseFragment(strings.NewReader(`<p>1</p><p>2</p>`), &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div})
+		require.NoError(t, err)
+		target.Before(NewDocumentFragment(nodes))
+		assert.Equal(t, `<a></a><p>1</p><p>2</p><b id="target"></b><i></i>`, parent.InnerHTML())
+	})
+	t.Run("no parent", func(t *testing.T) {
+		var document *Document
+		el := document.CreateElement("div")
+		p := document.CreateElement("p")
+		assert.NotPanics(t, func() { el.Before(p) })
+		assert.Nil(t, p.ParentNode())
+	})
+}
+
+func TestElement_After(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang='us-en'>
+<head><title></title></head>
+<body><div id='parent'><a></a><b id='target'></b><i></i></div></body>
+</html>`
+	_, parent := parseDocument(t, textHTML, "#parent")
+	target := parent.QuerySelector("#target")
+	a := parent.FirstChild().(spec.Node)
+	i := parent.LastChild().(spec.Node)
+
+	var document *Document
+	target.After(i, document.CreateTextNode("x"), a)
+	assert.Equal(t, `<b id="target"></b><i></i>x<a></a>`, parent.InnerHTML())
+
+	target.After()
+	assert.Equal(t, `<b id="target"></b><i></i>x<a></a>`, parent.InnerHTML())
+}
+
+func TestElement_ReplaceWith(t *testing.T) {
+	parseTarget := func(t *testing.T) (*Element, *Element) {
+		// language=html
+		textHTML := `<!DOCTYPE html>
+<html lang='us-en'>
+<head><title></title></head>
+<body><div id='parent'><a></a><b id='target'></b><i></i></div></body>
+</html>`
+		_, parent := parseDocument(t, textHTML, "#parent")
+		return parent, parent.QuerySelector("#target").(*Element)
+	}
+
+	t.Run("nodes", func(t *testing.T) {
+		parent, target := parseTarget(t)
+		var document *Document
+		target.ReplaceWith(document.CreateElement("p"), document.CreateTextNode("x"))
+		assert.Equal(t, `<a></a><p></p>x<i></i>`, parent.InnerHTML())
+		assert.Nil(t, target.ParentNode())
+	})
+	t.Run("including itself", func(t *testing.T) {
+		parent, target := parseTarget(t)
+		var document *Document
+		target.ReplaceWith(document.CreateTextNode("x"), target)
+		assert.Equal(t, `<a></a>x<b id="target"></b><i></i>`, parent.InnerHTML())
+	})
+	t.Run("next sibling argument", func(t *testing.T) {
+		parent, target := parseTarget(t)
+		target.ReplaceWith(parent.LastChild())
+		assert.Equal(t, `<a></a><i></i>`, parent.InnerHTML())
+	})
+	t.Run("nothing", func(t *testing.T) {
+		parent, target := parseTarget(t)
+		target.ReplaceWith()
+		assert.Equal(t, `<a></a><i></i>`, parent.InnerHTML())
+	})
+}
+
+func TestElement_Remove(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang='us-en'>
+<head><title></title></head>
+<body><div id='parent'><a></a><b id='target'></b><i></i></div></body>
+</html>`
+	_, parent := parseDocument(t, textHTML, "#parent")
+	target := parent.QuerySelector("#target")
+
+	target.Remove()
+	assert.Equal(t, `<a></a><i></i>`, parent.InnerHTML())
+	assert.Nil(t, target.ParentNode())
+	assert.False(t, target.IsConnected())
+
+	assert.NotPanics(t, func() { target.Remove() })
+}
+
+func TestElement_IsEqualNode(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang="us-en">
+<head><title></title></head>
+<body>
+<div id="a"><p class="x" title="y">Hello, <b>world</b>!<!-- c --></p></div>
+<div id="b"><p title="y" class="x">Hello, <b>world</b>!<!-- c --></p></div>
+<div id="c"><p class="x" title="y">Hello, <i>world</i>!<!-- c --></p></div>
+<div id="d"><p class="x" title="z">Hello, <b>world</b>!<!-- c --></p></div>
+<div id="e"><p class="x" title="y">Hello, <b>world</b>!<!-- d --></p></div>
+<div id="f"><p class="x" title="y">Hello, <b>world</b>!</p></div>
+<div id="g"><svg><title></title></svg><svg><title></title></svg><math><title></title></math></div>
+</body>
+</html>`
+	document, _ := parseDocument(t, textHTML, "")
+	p := func(id string) spec.Element { return document.QuerySelector("#" + id + " > *") }
+
+	assert.True(t, p("a").IsEqualNode(p("a")))
+	assert.True(t, p("a").IsEqualNode(p("b")), "attribute order does not matter")
+	assert.True(t, p("a").IsEqualNode(p("a").CloneNode(true)))
+	assert.False(t, p("a").IsEqualNode(p("a").CloneNode(false)))
+	assert.False(t, p("a").IsEqualNode(p("c")), "child element names differ")
+	assert.False(t, p("a").IsEqualNode(p("d")), "attribute values differ")
+	assert.False(t, p("a").IsEqualNode(p("e")), "comments differ")
+	assert.False(t, p("a").IsEqualNode(p("f")), "child counts differ")
+	assert.False(t, p("a").IsEqualNode(nil))
+	assert.False(t, p("a").IsEqualNode(p("a").FirstChild()))
+
+	g := document.GetElementByID("g").Children()
+	assert.True(t, g.Item(0).IsEqualNode(g.Item(1)))
+	assert.False(t, g.Item(0).FirstElementChild().IsEqualNode(g.Item(2).FirstElementChild()), "namespaces differ")
+
+	html, err := document.CreateElementNS(spec.HTMLNamespace, "title")
+	require.NoError(t, err)
+	assert.False(t, html.IsEqualNode(g.Item(0).FirstElementChild()))
+}
+
+func TestElement_GetRootNode(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang="us-en">
+<head><title></title></head>
+<body><p>Hello!</p></body>
+</html>`
+	document, p := parseDocument(t, textHTML, "p")
+	assert.True(t, p.GetRootNode().IsSameNode(document))
+
+	div := document.CreateElement("div")
+	span := document.CreateElement("span")
+	div.Append(span)
+	assert.True(t, div.GetRootNode().IsSameNode(div))
+	assert.True(t, span.GetRootNode().IsSameNode(div))
+}
+
+func TestElement_SetInnerHTML(t *testing.T) {
+	// language=html
+	const textHTML = `<!DOCTYPE html>
+<html lang="us-en">
+<head><title>Inner HTML</title></head>
+<body><table><tbody id="rows"></tbody></table><select id="fruit"></select><div id="box"><p id="old">old</p></div></body></html>`
+
+	t.Run("table rows", func(t *testing.T) {
+		document, tbody := parseDocument(t, textHTML, "#rows")
+		require.NoError(t, tbody.SetInnerHTML(`<tr><td>Peach</td></tr><tr><td>Plum</td></tr>`))
+		assert.Equal(t, `<tr><td>Peach</td></tr><tr><td>Plum</td></tr>`, tbody.InnerHTML())
+		assert.Equal(t, 2, document.GetElementsByTagName("tr").Length())
+	})
+	t.Run("select options", func(t *testing.T) {
+		_, sel := parseDocument(t, textHTML, "#fruit")
+		require.NoError(t, sel.SetHTMLUnsafe(`<option>Peach</option><option>Plum</option>`))
+		assert.Equal(t, 2, sel.ChildElementCount())
+		assert.Equal(t, `<option>Peach</option><option>Plum</option>`, sel.GetHTML(spec.GetHTMLOptions{}))
+	})
+	t.Run("replaces children", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#box")
+		old := div.FirstElementChild()
+		require.NoError(t, div.SetInnerHTML(`new`))
+		assert.Equal(t, "new", div.TextContent())
+		assert.Nil(t, old.ParentNode())
+	})
+}
+
+func TestElement_SetOuterHTML(t *testing.T) {
+	// language=html
+	const textHTML = `<!DOCTYPE html>
+<html lang="us-en">
+<head><title>Outer HTML</title></head>
+<body><table><tbody id="rows"><tr id="row"><td>1</td></tr></tbody></table></body></html>`
+
+	t.Run("parent context", func(t *testing.T) {
+		document, row := parseDocument(t, textHTML, "#row")
+		require.NoError(t, row.SetOuterHTML(`<tr><td>2</td></tr><tr><td>3</td></tr>`))
+		assert.Equal(t, `<tr><td>2</td></tr><tr><td>3</td></tr>`, document.GetElementByID("rows").InnerHTML())
+		assert.Nil(t, row.ParentNode())
+	})
+	t.Run("no parent", func(t *testing.T) {
+		document, _ := parseDocument(t, textHTML, "")
+		div := document.CreateElement("div")
+		require.NoError(t, div.SetOuterHTML(`<p></p>`))
+		assert.Equal(t, `<div></div>`, div.OuterHTML())
+	})
+	t.Run("document parent", func(t *testing.T) {
+		document, _ := parseDocument(t, textHTML, "")
+		assert.ErrorIs(t, document.DocumentElement().SetOuterHTML(`<html></html>`), spec.ErrNoModificationAllowed)
+	})
+	t.Run("fragment parent", func(t *testing.T) {
+		document, _ := parseDocument(t, textHTML, "")
+		fragment := document.CreateDocumentFragment()
+		p := document.CreateElement("p")
+		fragment.Append(p)
+		require.NoError(t, p.SetOuterHTML(`<span>a</span>b`))
+		assert.Equal(t, `<span>a</span>b`, fragment.(*DocumentFragment).String())
+	})
+}
+
+func TestElement_insertValidity(t *testing.T) {
+	const (
+		errAncestor = "HierarchyRequestError: a node can not be inserted into itself"
+		errNotChild = "NotFoundError: the child is not a child of the parent"
+	)
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang='us-en'>
+<head><title></title></head>
+<body><div id='parent'><p id='child'>x</p></div></body>
+</html>`
+	document, div := parseDocument(t, textHTML, "#parent")
+	p := div.QuerySelector("#child")
+	text := document.CreateTextNode("y")
+
+	assert.PanicsWithError(t, errAncestor, func() { p.AppendChild(div) })
+	assert.PanicsWithError(t, errAncestor, func() { p.AppendChild(p) })
+	assert.PanicsWithError(t, errAncestor, func() { p.InsertBefore(div, p.FirstChild()) })
+	assert.PanicsWithError(t, errAncestor, func() { p.ReplaceChild(div, p.FirstChild()) })
+	assert.PanicsWithError(t, errAncestor, func() { p.Append(text, div) })
+	assert.PanicsWithError(t, errAncestor, func() { p.Prepend(div) })
+	assert.PanicsWithError(t, errAncestor, func() { p.ReplaceChildren(div) })
+	assert.PanicsWithError(t, errAncestor, func() { p.FirstChild().Before(div) })
+	assert.PanicsWithError(t, errAncestor, func() { p.FirstChild().After(div) })
+	assert.PanicsWithError(t, errAncestor, func() { p.FirstChild().ReplaceWith(div) })
+	_, err := p.InsertAdjacentElement(spec.InsertAfterBegin, div)
+	assert.ErrorIs(t, err, spec.ErrHierarchyRequest)
+
+	assert.PanicsWithError(t, errNotChild, func() { div.InsertBefore(text, p.FirstChild()) })
+	assert.PanicsWithValue(t, "dom: ReplaceChild called for a non-child node", func() { div.ReplaceChild(text, p.FirstChild()) })
+
+	assert.Nil(t, text.ParentNode())
+	assert.True(t, div.ParentNode().IsSameNode(document.Body()))
+	assert.Equal(t, `<p id="child">x</p>`, div.InnerHTML())
+}
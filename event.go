@@ -0,0 +1,280 @@
+package dom
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"weak"
+
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+var (
+	_ spec.Event       = (*Event)(nil)
+	_ spec.CustomEvent = (*CustomEvent)(nil)
+)
+
+// Event is based on https://dom.spec.whatwg.org/#interface-event. An Event
+// is not safe for concurrent use.
+type Event struct {
+	eventType string
+	init      spec.EventInit
+
+	target, currentTarget *html.Node
+	phase                 spec.EventPhase
+
+	dispatching              bool
+	stopPropagation          bool
+	stopImmediatePropagation bool
+	canceled                 bool
+	inPassiveListener        bool
+}
+
+// NewEvent is based on https://dom.spec.whatwg.org/#dom-event-event
+func NewEvent(eventType string, init spec.EventInit) *Event {
+	return &Event{eventType: eventType, init: init}
+}
+
+func (e *Event) Type() string                    { return e.eventType }
+func (e *Event) Target() spec.EventTarget        { return eventTarget(e.target) }
+func (e *Event) CurrentTarget() spec.EventTarget { return eventTarget(e.currentTarget) }
+func (e *Event) EventPhase() spec.EventPhase     { return e.phase }
+func (e *Event) Bubbles() bool                   { return e.init.Bubbles }
+func (e *Event) Cancelable() bool                { return e.init.Cancelable }
+func (e *Event) Composed() bool                  { return e.init.Composed }
+func (e *Event) DefaultPrevented() bool          { return e.canceled }
+func (e *Event) StopPropagation()                { e.stopPropagation = true }
+func (e *Event) StopImmediatePropagation() {
+	e.stopPropagation, e.stopImmediatePropagation = true, true
+}
+
+func (e *Event) domEvent() *Event { return e }
+
+// IsTrusted returns false; events in this package are only dispatched by
+// DispatchEvent.
+func (e *Event) IsTrusted() bool { return false }
+
+// PreventDefault is based on https://dom.spec.whatwg.org/#dom-event-preventdefault
+func (e *Event) PreventDefault() {
+	if e.init.Cancelable && !e.inPassiveListener {
+		e.canceled = true
+	}
+}
+
+// CustomEvent is based on https://dom.spec.whatwg.org/#interface-customevent.
+// Detail may hold any Go value.
+type CustomEvent struct {
+	Event
+	detail any
+}
+
+// NewCustomEvent is based on https://dom.spec.whatwg.org/#dom-customevent-customevent
+func NewCustomEvent(eventType string, init spec.CustomEventInit) *CustomEvent {
+	return &CustomEvent{Event: Event{eventType: eventType, init: init.EventInit}, detail: init.Detail}
+}
+
+func (e *CustomEvent) Detail() any { return e.detail }
+
+func eventTarget(node *html.Node) spec.EventTarget {
+	if node == nil {
+		return nil
+	}
+	target, _ := NewNode(node).(spec.EventTarget)
+	return target
+}
+
+// eventListener is based on https://dom.spec.whatwg.org/#concept-event-listener
+type eventListener struct {
+	eventType string
+	callback  spec.EventListener
+	capture   bool
+	once      bool
+	passive   bool
+	signal    context.Context
+
+	removed atomic.Bool
+	stop    func() bool
+}
+
+// eventListeners holds the listeners added to each node. A node's entry is
+// deleted when the node is garbage collected, which also stops watching the
+// signals of its listeners, so nothing this package holds outlives the node.
+//
+// html.Node has no field to keep the listeners on, and Go has no ephemerons,
+// so the listeners are held by this map. A listener referring to its target
+// therefore keeps the target alive until the listener is removed, by
+// RemoveEventListener, Once, or Signal.
+var eventListeners struct {
+	mu sync.Mutex
+	m  map[weak.Pointer[html.Node]][]*eventListener
+}
+
+func deleteEventListeners(key weak.Pointer[html.Node]) {
+	eventListeners.mu.Lock()
+	listeners := eventListeners.m[key]
+	delete(eventListeners.m, key)
+	eventListeners.mu.Unlock()
+	for _, l := range listeners {
+		l.removed.Store(true)
+		if l.stop != nil {
+			l.stop()
+		}
+	}
+}
+
+// addEventListener is based on https://dom.spec.whatwg.org/#add-an-event-listener
+func addEventListener(node *html.Node, eventType string, callback spec.EventListener, options spec.AddEventListenerOptions) {
+	if callback == nil || (options.Signal != nil && options.Signal.Err() != nil) {
+		return
+	}
+	key := weak.Make(node)
+	eventListeners.mu.Lock()
+	defer eventListeners.mu.Unlock()
+	if eventListeners.m == nil {
+		eventListeners.m = make(map[weak.Pointer[html.Node]][]*eventListener)
+	}
+	listeners, ok := eventListeners.m[key]
+	if !ok {
+		runtime.AddCleanup(node, deleteEventListeners, key)
+	}
+	for _, l := range listeners {
+		if l.eventType == eventType && l.callback == callback && l.capture == options.Capture {
+			return
+		}
+	}
+	l := &eventListener{
+		eventType: eventType,
+		callback:  callback,
+		capture:   options.Capture,
+		once:      options.Once,
+		passive:   options.Passive,
+		signal:    options.Signal,
+	}
+	eventListeners.m[key] = append(listeners, l)
+	if l.signal != nil {
+		l.stop = context.AfterFunc(l.signal, func() { removeListener(key, l) })
+	}
+}
+
+// removeEventListener is based on https://dom.spec.whatwg.org/#remove-an-event-listener
+func removeEventListener(node *html.Node, eventType string, callback spec.EventListener, options spec.EventListenerOptions) {
+	key := weak.Make(node)
+	eventListeners.mu.Lock()
+	var found *eventListener
+	for _, l := range eventListeners.m[key] {
+		if l.eventType == eventType && l.callback == callback && l.capture == options.Capture {
+			found = l
+			break
+		}
+	}
+	eventListeners.mu.Unlock()
+	if found != nil {
+		removeListener(key, found)
+	}
+}
+
+func removeListener(key weak.Pointer[html.Node], l *eventListener) {
+	eventListeners.mu.Lock()
+	l.removed.Store(true)
+	if listeners, ok := eventListeners.m[key]; ok {
+		eventListeners.m[key] = slices.DeleteFunc(listeners, func(other *eventListener) bool { return other == l })
+	}
+	eventListeners.mu.Unlock()
+	if l.stop != nil {
+		l.stop()
+	}
+}
+
+// dispatchEvent is based on https://dom.spec.whatwg.org/#concept-event-dispatch
+// for trees without shadow roots.
+func dispatchEvent(node *html.Node, event spec.Event) (bool, error) {
+	de, ok := event.(interface{ domEvent() *Event })
+	if !ok {
+		return false, fmt.Errorf("%w: event %T was not created by this package", spec.ErrNotSupported, event)
+	}
+	e := de.domEvent()
+	if e.dispatching {
+		return false, fmt.Errorf("%w: event %q is already being dispatched", spec.ErrInvalidState, e.eventType)
+	}
+	e.dispatching = true
+	defer func() {
+		e.dispatching = false
+		e.phase = spec.EventPhaseNone
+		e.currentTarget = nil
+		e.stopPropagation = false
+		e.stopImmediatePropagation = false
+		e.inPassiveListener = false
+	}()
+	e.target = node
+
+	path := eventPath(node)
+	for i := len(path) - 1; i >= 0; i-- {
+		e.phase = spec.EventPhaseCapturing
+		if path[i] == node {
+			e.phase = spec.EventPhaseAtTarget
+		}
+		invokeEventListeners(path[i], event, e, true)
+	}
+	for _, n := range path {
+		switch {
+		case n == node:
+			e.phase = spec.EventPhaseAtTarget
+		case !e.init.Bubbles:
+			continue
+		default:
+			e.phase = spec.EventPhaseBubbling
+		}
+		invokeEventListeners(n, event, e, false)
+	}
+	return !e.canceled, nil
+}
+
+// eventPath returns node and its ancestors. Template contents do not
+// propagate events to the template element, matching their separate
+// DocumentFragment in the spec.
+func eventPath(node *html.Node) []*html.Node {
+	path := []*html.Node{node}
+	for n := node; n.Parent != nil && !isTemplate(n.Parent); n = n.Parent {
+		path = append(path, n.Parent)
+	}
+	return path
+}
+
+// invokeEventListeners is based on https://dom.spec.whatwg.org/#concept-event-listener-invoke
+// and https://dom.spec.whatwg.org/#concept-event-listener-inner-invoke
+func invokeEventListeners(node *html.Node, event spec.Event, e *Event, capture bool) {
+	if e.stopPropagation {
+		return
+	}
+	key := weak.Make(node)
+	eventListeners.mu.Lock()
+	listeners := slices.Clone(eventListeners.m[key])
+	eventListeners.mu.Unlock()
+	if len(listeners) == 0 {
+		return
+	}
+	e.currentTarget = node
+	for _, l := range listeners {
+		if l.removed.Load() || l.eventType != e.eventType || l.capture != capture {
+			continue
+		}
+		if l.signal != nil && l.signal.Err() != nil {
+			removeListener(key, l)
+			continue
+		}
+		if l.once {
+			removeListener(key, l)
+		}
+		e.inPassiveListener = l.passive
+		l.callback.HandleEvent(event)
+		e.inPassiveListener = false
+		if e.stopImmediatePropagation {
+			return
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package dom
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+	"weak"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+var (
+	_ spec.EventTarget = (*Element)(nil)
+	_ spec.EventTarget = (*Document)(nil)
+	_ spec.EventTarget = (*Text)(nil)
+)
+
+func TestElement_DispatchEvent(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang='us-en'>
+<head><title></title></head>
+<body><main><button id='target'>Save</button></main></body>
+</html>`
+
+	// record adds a listener to each target that appends the name of the
+	// current target and the phase to calls.
+	record := func(calls *[]string, capture bool, targets ...spec.EventTarget) {
+		for _, target := range targets {
+			target.AddEventListener("click", spec.NewEventListener(func(event spec.Event) {
+				*calls = append(*calls, fmt.Sprintf("%s:%d", nodeTagName(event.CurrentTarget().(spec.Node)), event.EventPhase()))
+			}), spec.AddEventListenerOptions{EventListenerOptions: spec.EventListenerOptions{Capture: capture}})
+		}
+	}
+
+	t.Run("phases", func(t *testing.T) {
+		document, button := parseDocument(t, textHTML, "#target")
+		main := document.QuerySelector("main")
+		var calls []string
+		record(&calls, false, document, main, button)
+		record(&calls, true, document, main, button)
+
+		event := NewEvent("click", spec.EventInit{Bubbles: true})
+		ok, err := button.DispatchEvent(event)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, []string{":1", "MAIN:1", "BUTTON:2", "BUTTON:2", "MAIN:3", ":3"}, calls)
+		assert.True(t, event.Target().(spec.Node).IsSameNode(button))
+		assert.Nil(t, event.CurrentTarget())
+		assert.Equal(t, spec.EventPhaseNone, event.EventPhase())
+	})
+	t.Run("does not bubble", func(t *testing.T) {
+		document, button := parseDocument(t, textHTML, "#target")
+		var calls []string
+		record(&calls, false, document, button)
+		record(&calls, true, document)
+
+		_, err := button.DispatchEvent(NewEvent("click", spec.EventInit{}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{":1", "BUTTON:2"}, calls)
+	})
+	t.Run("stop propagation", func(t *testing.T) {
+		document, button := parseDocument(t, textHTML, "#target")
+		var calls []string
+		button.AddEventListener("click", spec.NewEventListener(func(event spec.Event) {
+			event.StopPropagation()
+		}), spec.AddEventListenerOptions{})
+		record(&calls, false, document, button)
+
+		_, err := button.DispatchEvent(NewEvent("click", spec.EventInit{Bubbles: true}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"BUTTON:2"}, calls)
+	})
+	t.Run("stop immediate propagation", func(t *testing.T) {
+		_, button := parseDocument(t, textHTML, "#target")
+		var calls []string
+		button.AddEventListener("click", spec.NewEventListener(func(event spec.Event) {
+			event.StopImmediatePropagation()
+		}), spec.AddEventListenerOptions{})
+		record(&calls, false, button)
+
+		_, err := button.DispatchEvent(NewEvent("click", spec.EventInit{Bubbles: true}))
+		require.NoError(t, err)
+		assert.Empty(t, calls)
+	})
+	t.Run("prevent default", func(t *testing.T) {
+		_, button := parseDocument(t, textHTML, "#target")
+		button.AddEventListener("click", spec.NewEventListener(func(event spec.Event) {
+			event.PreventDefault()
+		}), spec.AddEventListenerOptions{})
+
+		ok, err := button.DispatchEvent(NewEvent("click", spec.EventInit{Cancelable: true}))
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		event := NewEvent("click", spec.EventInit{})
+		ok, err = button.DispatchEvent(event)
+		require.NoError(t, err)
+		assert.True(t, ok, "not cancelable")
+		assert.False(t, event.DefaultPrevented())
+	})
+	t.Run("passive", func(t *testing.T) {
+		_, button := parseDocument(t, textHTML, "#target")
+		button.AddEventListener("click", spec.NewEventListener(func(event spec.Event) {
+			event.PreventDefault()
+		}), spec.AddEventListenerOptions{Passive: true})
+
+		ok, err := button.DispatchEvent(NewEvent("click", spec.EventInit{Cancelable: true}))
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+	t.Run("once and duplicates", func(t *testing.T) {
+		_, button := parseDocument(t, textHTML, "#target")
+		count := 0
+		listener := spec.NewEventListener(func(spec.Event) { count++ })
+		button.AddEventListener("click", listener, spec.AddEventListenerOptions{Once: true})
+		button.AddEventListener("click", listener, spec.AddEventListenerOptions{})
+		for range 2 {
+			_, err := button.DispatchEvent(NewEvent("click", spec.EventInit{}))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, count)
+	})
+	t.Run("remove", func(t *testing.T) {
+		_, button := parseDocument(t, textHTML, "#target")
+		count := 0
+		listener := spec.NewEventListener(func(spec.Event) { count++ })
+		button.AddEventListener("click", listener, spec.AddEventListenerOptions{EventListenerOptions: spec.EventListenerOptions{Capture: true}})
+		button.RemoveEventListener("click", listener, spec.EventListenerOptions{})
+		_, err := button.DispatchEvent(NewEvent("click", spec.EventInit{}))
+		require.NoError(t, err)
+		assert.Equal(t, 1, count, "capture must match")
+
+		button.RemoveEventListener("click", listener, spec.EventListenerOptions{Capture: true})
+		_, err = button.DispatchEvent(NewEvent("click", spec.EventInit{}))
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+	t.Run("remove during dispatch", func(t *testing.T) {
+		_, button := parseDocument(t, textHTML, "#target")
+		var calls []string
+		second := spec.NewEventListener(func(spec.Event) { calls = append(calls, "second") })
+		button.AddEventListener("click", spec.NewEventListener(func(spec.Event) {
+			calls = append(calls, "first")
+			button.RemoveEventListener("click", second, spec.EventListenerOptions{})
+		}), spec.AddEventListenerOptions{})
+		button.AddEventListener("click", second, spec.AddEventListenerOptions{})
+
+		_, err := button.DispatchEvent(NewEvent("click", spec.EventInit{}))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first"}, calls)
+	})
+	t.Run("signal", func(t *testing.T) {
+		_, button := parseDocument(t, textHTML, "#target")
+		count := 0
+		ctx, cancel := context.WithCancel(context.Background())
+		button.AddEventListener("click", spec.NewEventListener(func(spec.Event) { count++ }), spec.AddEventListenerOptions{Signal: ctx})
+		_, err := button.DispatchEvent(NewEvent("click", spec.EventInit{}))
+		require.NoError(t, err)
+		cancel()
+		_, err = button.DispatchEvent(NewEvent("click", spec.EventInit{}))
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		button.AddEventListener("click", spec.NewEventListener(func(spec.Event) { count++ }), spec.AddEventListenerOptions{Signal: ctx})
+		_, err = button.DispatchEvent(NewEvent("click", spec.EventInit{}))
+		require.NoError(t, err)
+		assert.Equal(t, 1, count, "signal already done")
+	})
+	t.Run("custom event detail", func(t *testing.T) {
+		document, button := parseDocument(t, textHTML, "#target")
+		type saved struct{ id int }
+		var detail any
+		document.AddEventListener("saved", spec.NewEventListener(func(event spec.Event) {
+			detail = event.(spec.CustomEvent).Detail()
+		}), spec.AddEventListenerOptions{})
+
+		_, err := button.DispatchEvent(NewCustomEvent("saved", spec.CustomEventInit{
+			EventInit: spec.EventInit{Bubbles: true, Composed: true},
+			Detail:    saved{id: 7},
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, saved{id: 7}, detail)
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, button := parseDocument(t, textHTML, "#target")
+		event := NewEvent("click", spec.EventInit{})
+		var nested error
+		button.AddEventListener("click", spec.NewEventListener(func(event spec.Event) {
+			_, nested = button.DispatchEvent(event)
+		}), spec.AddEventListenerOptions{})
+		_, err := button.DispatchEvent(event)
+		require.NoError(t, err)
+		require.ErrorIs(t, nested, spec.ErrInvalidState)
+
+		_, err = button.DispatchEvent(nil)
+		require.ErrorIs(t, err, spec.ErrNotSupported)
+	})
+	t.Run("text target", func(t *testing.T) {
+		_, button := parseDocument(t, textHTML, "#target")
+		var target spec.EventTarget
+		button.AddEventListener("input", spec.NewEventListener(func(event spec.Event) {
+			target = event.Target()
+		}), spec.AddEventListenerOptions{})
+		text := button.FirstChild().(*Text)
+		_, err := text.DispatchEvent(NewEvent("input", spec.EventInit{Bubbles: true}))
+		require.NoError(t, err)
+		require.NotNil(t, target)
+		assert.True(t, target.(spec.Node).IsSameNode(text))
+	})
+	t.Run("template contents", func(t *testing.T) {
+		document, _ := parseDocument(t, `<template><p>a</p></template>`, "")
+		template := document.QuerySelector("template").(spec.HTMLTemplateElement)
+		count := 0
+		template.AddEventListener("click", spec.NewEventListener(func(spec.Event) { count++ }), spec.AddEventListenerOptions{})
+		p := template.Content().FirstElementChild()
+		_, err := p.DispatchEvent(NewEvent("click", spec.EventInit{Bubbles: true}))
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+}
+
+func Test_eventListeners_cleanup(t *testing.T) {
+	// language=html
+	document, _ := parseDocument(t, `<!DOCTYPE html><html><head></head><body></body></html>`, "")
+
+	// addListener adds a listener to a new element that refers to a new value
+	// and, when self is set, to the element.
+	addListener := func(ctx context.Context, self bool) (weak.Pointer[html.Node], weak.Pointer[[]string]) {
+		div := document.CreateElement("div")
+		value := &[]string{"pear"}
+		listener := spec.NewEventListener(func(spec.Event) { *value = append(*value, "click") })
+		if self {
+			listener = spec.NewEventListener(func(spec.Event) {
+				*value = append(*value, "click")
+				div.Remove()
+			})
+		}
+		div.AddEventListener("click", listener, spec.AddEventListenerOptions{Signal: ctx})
+		return weak.Make(div.(*Element).node), weak.Make(value)
+	}
+	collected := func(node weak.Pointer[html.Node], value weak.Pointer[[]string]) func() bool {
+		return func() bool {
+			runtime.GC()
+			return node.Value() == nil && value.Value() == nil
+		}
+	}
+
+	t.Run("node collected", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		node, value := addListener(ctx, false)
+		assert.Eventually(t, collected(node, value), time.Second, 10*time.Millisecond, "the signal does not keep the listener after its node is collected")
+	})
+	t.Run("removed by signal", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		node, value := addListener(ctx, true)
+		cancel()
+		assert.Eventually(t, collected(node, value), time.Second, 10*time.Millisecond, "a listener referring to its target is released by its signal")
+	})
+}
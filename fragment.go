@@ -1,151 +1,146 @@
 package dom
 
 import (
-	"bytes"
 	"iter"
 	"slices"
 
-	"github.com/andybalholm/cascadia"
 	"golang.org/x/net/html"
 
 	"github.com/typelate/dom/spec"
 )
 
+// documentFragmentNode is the html.NodeType of the node backing a
+// DocumentFragment. x/net/html does not have a fragment node type, so it uses
+// a value far outside the range of the html package's types. These nodes can
+// not be rendered by html.Render; only their children can.
+const documentFragmentNode = html.NodeType(^uint32(0))
+
 type DocumentFragment struct {
-	nodes []*html.Node
+	node *html.Node
 }
 
+// NewDocumentFragment returns a fragment with nodes as its children. Nodes
+// that have a parent are removed from it first.
 func NewDocumentFragment(nodes []*html.Node) *DocumentFragment {
-	return &DocumentFragment{nodes: nodes}
+	d := &DocumentFragment{node: &html.Node{Type: documentFragmentNode}}
+	for _, n := range nodes {
+		detachHTMLNode(n)
+		d.node.AppendChild(n)
+	}
+	return d
 }
 
-func (d *DocumentFragment) String() string { return outerHTML(d.nodes...) }
+func (d *DocumentFragment) String() string { return outerHTML(slices.Collect(d.node.ChildNodes())...) }
 
 func (d *DocumentFragment) NodeType() spec.NodeType { return spec.NodeTypeDocumentFragment }
 
+// CloneNode returns a new fragment. When deep is true its children are
+// clones of the children of d.
 func (d *DocumentFragment) CloneNode(deep bool) spec.Node {
-	if !deep {
-		return &DocumentFragment{nodes: d.nodes}
+	clone := &html.Node{Type: documentFragmentNode}
+	document := ownerDocumentNode(d.node)
+	if isTemplate(d.node) {
+		document = templateContentsOwner(document)
 	}
-	df := &DocumentFragment{nodes: make([]*html.Node, len(d.nodes))}
-	for _, e := range d.nodes {
-		df.nodes = append(df.nodes, cloneNode(e, deep))
+	setNodeDocument(clone, document)
+	if deep {
+		for c := d.node.FirstChild; c != nil; c = c.NextSibling {
+			clone.AppendChild(cloneHTMLNode(c, true))
+		}
 	}
-	return df
+	return &DocumentFragment{node: clone}
 }
 
 func (d *DocumentFragment) IsSameNode(other spec.Node) bool {
 	o, ok := other.(*DocumentFragment)
-	if !ok {
-		return false
-	}
-	return d == o
+	return ok && o != nil && d.node == o.node
 }
 
-func (d *DocumentFragment) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
-	return compareDocumentFragmentPosition(d.nodes, other)
+func (d *DocumentFragment) IsEqualNode(other spec.Node) bool {
+	o, ok := other.(*DocumentFragment)
+	return ok && o != nil && equalChildNodes(d.node, o.node)
 }
 
-func (d *DocumentFragment) TextContent() string {
-	var buf bytes.Buffer
-	for _, n := range d.nodes {
-		recursiveTextContent(&buf, n)
-	}
-	return buf.String()
+// GetRootNode returns d since a fragment never has a parent.
+func (d *DocumentFragment) GetRootNode() spec.Node { return d }
+func (d *DocumentFragment) TextContent() string    { return textContent(d.node) }
+
+func (d *DocumentFragment) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
+	return compareDocumentPosition(d.node, other)
 }
 
-func (d *DocumentFragment) Children() spec.ElementCollection {
-	elementChildren := make(elementList, 0, len(d.nodes))
-	for _, n := range d.nodes {
-		if n != nil && n.Type == html.ElementNode {
-			elementChildren = append(elementChildren, n)
-		}
-	}
-	return slices.Clip(elementChildren)
+func (d *DocumentFragment) LookupNamespaceURI(string) string { return "" }
+func (d *DocumentFragment) LookupPrefix(string) string       { return "" }
+
+func (d *DocumentFragment) Children() spec.ElementCollection { return children(d.node) }
+func (d *DocumentFragment) FirstElementChild() spec.Element  { return firstElementChild(d.node) }
+func (d *DocumentFragment) LastElementChild() spec.Element   { return lastElementChild(d.node) }
+func (d *DocumentFragment) ChildElementCount() int           { return childElementCount(d.node) }
+
+func (d *DocumentFragment) Append(nodes ...spec.Node)          { appendNodes(d.node, nodes...) }
+func (d *DocumentFragment) Prepend(nodes ...spec.Node)         { prependNodes(d.node, nodes) }
+func (d *DocumentFragment) ReplaceChildren(nodes ...spec.Node) { replaceChildren(d.node, nodes) }
+func (d *DocumentFragment) Normalize()                         { normalize(d.node) }
+
+func (d *DocumentFragment) HasChildNodes() bool                  { return hasChildNodes(d.node) }
+func (d *DocumentFragment) ChildNodes() spec.NodeList[spec.Node] { return childNodes(d.node) }
+func (d *DocumentFragment) FirstChild() spec.ChildNode           { return firstChild(d.node) }
+func (d *DocumentFragment) LastChild() spec.ChildNode            { return lastChild(d.node) }
+func (d *DocumentFragment) Contains(other spec.Node) bool        { return contains(d.node, other) }
+func (d *DocumentFragment) InsertBefore(node spec.Node, child spec.ChildNode) spec.Node {
+	return insertBefore(d.node, node, child)
+}
+func (d *DocumentFragment) AppendChild(node spec.Node) spec.Node { return appendChild(d.node, node) }
+func (d *DocumentFragment) ReplaceChild(node spec.Node, child spec.ChildNode) spec.ChildNode {
+	return replaceChild(d.node, node, child)
+}
+func (d *DocumentFragment) RemoveChild(node spec.ChildNode) spec.ChildNode {
+	return removeChild(d.node, node)
 }
 
-func (d *DocumentFragment) FirstElementChild() spec.Element {
-	for _, n := range d.nodes {
-		if n.Type == html.ElementNode {
-			return &Element{node: n}
-		}
-	}
-	return nil
+func (d *DocumentFragment) Descendants() iter.Seq[spec.Node]        { return descendantNodes(d.node) }
+func (d *DocumentFragment) ChildNodesSeq() iter.Seq[spec.ChildNode] { return childNodesSeq(d.node) }
+func (d *DocumentFragment) ChildrenSeq() iter.Seq[spec.Element]     { return childrenSeq(d.node) }
+
+func (d *DocumentFragment) QuerySelector(query string) spec.Element {
+	el, _ := d.QuerySelectorErr(query)
+	return el
 }
 
-func (d *DocumentFragment) LastElementChild() spec.Element {
-	for i := range d.nodes {
-		n := d.nodes[len(d.nodes)-1-i]
-		if n.Type == html.ElementNode {
-			return &Element{node: n}
-		}
-	}
-	return nil
+func (d *DocumentFragment) QuerySelectorAll(query string) spec.NodeList[spec.Element] {
+	list, _ := d.QuerySelectorAllErr(query)
+	return list
 }
 
-func (d *DocumentFragment) ChildElementCount() int {
-	count := 0
-	for _, n := range d.nodes {
-		if n.Type == html.ElementNode {
-			count++
-		}
-	}
-	return count
+func (d *DocumentFragment) QuerySelectorSequence(query string) iter.Seq[spec.Element] {
+	seq, _ := d.QuerySelectorSequenceErr(query)
+	return seq
 }
 
-func (d *DocumentFragment) Append(nodes ...spec.Node) {
-	d.nodes = slices.Grow(d.nodes, len(nodes))
-	for _, node := range nodes {
-		d.nodes = append(d.nodes, domNodeToHTMLNode(node))
-	}
+func (d *DocumentFragment) QuerySelectorErr(query string) (spec.Element, error) {
+	return querySelector(d.node, query)
 }
 
-func (d *DocumentFragment) Prepend(nodes ...spec.Node) {
-	children := make([]*html.Node, 0, len(d.nodes)+len(nodes))
-	for _, node := range nodes {
-		children = append(children, domNodeToHTMLNode(node))
+func (d *DocumentFragment) QuerySelectorAllErr(query string) (spec.NodeList[spec.Element], error) {
+	list, err := querySelectorAll(d.node, query)
+	if err != nil {
+		return nodeListHTMLElements(nil), err
 	}
-	d.nodes = append(children, d.nodes...)
+	return list, nil
 }
 
-func (d *DocumentFragment) ReplaceChildren(nodes ...spec.Node) {
-	list := make([]*html.Node, 0, len(nodes))
-	for _, node := range nodes {
-		list = append(list, domNodeToHTMLNode(node))
-	}
-	d.nodes = list
+func (d *DocumentFragment) QuerySelectorSequenceErr(query string) (iter.Seq[spec.Element], error) {
+	return querySelectorEach(d.node, query)
 }
 
-func (d *DocumentFragment) QuerySelector(query string) spec.Element {
-	for _, n := range d.nodes {
-		el := querySelector(n, query, true)
-		if el != nil {
-			return el
-		}
-	}
-	return nil
+func (d *DocumentFragment) QuerySelectorCompiled(selector *Selector) spec.Element {
+	return querySelectorMatcher(d.node, selector.matcher)
 }
 
-func (d *DocumentFragment) QuerySelectorAll(query string) spec.NodeList[spec.Element] {
-	var list nodeListHTMLElements
-	for _, n := range d.nodes {
-		list = append(list, querySelectorAll(n, query, true)...)
-	}
-	return slices.Clip(list)
+func (d *DocumentFragment) QuerySelectorAllCompiled(selector *Selector) spec.NodeList[spec.Element] {
+	return querySelectorAllMatcher(d.node, selector.matcher)
 }
 
-func (d *DocumentFragment) QuerySelectorSequence(query string) iter.Seq[spec.Element] {
-	m := cascadia.MustCompile(query)
-	return func(yield func(spec.Element) bool) {
-		for _, n := range d.nodes {
-			if m.Match(n) {
-				if !yield(&Element{node: n}) {
-					return
-				}
-			}
-			if !querySelectorSequence(n, m, yield) {
-				return
-			}
-		}
-	}
+func (d *DocumentFragment) QuerySelectorSequenceCompiled(selector *Selector) iter.Seq[spec.Element] {
+	return querySelectorEachMatcher(d.node, selector.matcher)
 }
@@ -12,6 +12,7 @@ import (
 	"golang.org/x/net/html/atom"
 
 	"github.com/typelate/dom"
+	"github.com/typelate/dom/domtest"
 	"github.com/typelate/dom/spec"
 )
 
@@ -52,11 +53,8 @@ func TestDocumentFragment_CloneNode(t *testing.T) {
 		clonedFragment := clone.(*dom.DocumentFragment)
 
 		require.False(t, fragment.IsSameNode(clone))
-		children := fragment.Children()
-		clonedChildren := clonedFragment.Children()
-		for i := 0; i < children.Length(); i++ {
-			require.True(t, clonedChildren.Item(i).IsSameNode(children.Item(i)))
-		}
+		require.False(t, clonedFragment.HasChildNodes())
+		require.Equal(t, 2, fragment.ChildElementCount())
 	})
 }
 
@@ -407,3 +405,129 @@ func TestDocumentFragment_QuerySelectorEach(t *testing.T) {
 		assert.Equal(t, stopOnCall, callCount)
 	}
 }
+
+func TestDocumentFragment_Normalize(t *testing.T) {
+	fragment := parseDocumentFragment(t, `<em>x</em>`)
+	var document *dom.Document
+	fragment.Prepend(document.CreateTextNode("a"), document.CreateTextNode(""), document.CreateTextNode("b"))
+	fragment.Append(document.CreateTextNode("c"), document.CreateTextNode("d"))
+	fragment.QuerySelector("em").Append(document.CreateTextNode("y"))
+
+	fragment.Normalize()
+
+	assert.Equal(t, "ab<em>xy</em>cd", fragment.String())
+	assert.Equal(t, 1, fragment.QuerySelector("em").ChildNodes().Length())
+	assert.Equal(t, 3, fragment.ChildNodes().Length())
+}
+
+func TestDocumentFragment_IsEqualNode(t *testing.T) {
+	a := parseDocumentFragment(t, `Hello, <em class="x" id="y">world</em>!<br>`)
+	b := parseDocumentFragment(t, `Hello, <em id="y" class="x">world</em>!<br>`)
+	c := parseDocumentFragment(t, `Hello, <em>world</em>!`)
+
+	assert.True(t, a.IsEqualNode(b))
+	assert.True(t, a.IsEqualNode(a.CloneNode(true)))
+	assert.False(t, a.IsEqualNode(c))
+	assert.False(t, a.IsEqualNode(a.FirstElementChild()))
+	assert.True(t, a.GetRootNode().IsSameNode(a))
+}
+
+func TestDocumentFragment_insertion(t *testing.T) {
+	for _, tt := range []struct {
+		Name   string
+		Insert func(parent spec.Element, fragment spec.DocumentFragment)
+		Result string
+	}{
+		{
+			Name:   "Append",
+			Insert: func(parent spec.Element, fragment spec.DocumentFragment) { parent.Append(fragment) },
+			Result: `<div><hr/>Hello, <em>world</em>!<br/></div>`,
+		},
+		{
+			Name:   "Prepend",
+			Insert: func(parent spec.Element, fragment spec.DocumentFragment) { parent.Prepend(fragment) },
+			Result: `<div>Hello, <em>world</em>!<br/><hr/></div>`,
+		},
+		{
+			Name:   "ReplaceChildren",
+			Insert: func(parent spec.Element, fragment spec.DocumentFragment) { parent.ReplaceChildren(fragment) },
+			Result: `<div>Hello, <em>world</em>!<br/></div>`,
+		},
+		{
+			Name: "InsertBefore",
+			Insert: func(parent spec.Element, fragment spec.DocumentFragment) {
+				assert.True(t, parent.InsertBefore(fragment, parent.FirstChild()).IsSameNode(fragment))
+			},
+			Result: `<div>Hello, <em>world</em>!<br/><hr/></div>`,
+		},
+		{
+			Name: "AppendChild",
+			Insert: func(parent spec.Element, fragment spec.DocumentFragment) {
+				assert.True(t, parent.AppendChild(fragment).IsSameNode(fragment))
+			},
+			Result: `<div><hr/>Hello, <em>world</em>!<br/></div>`,
+		},
+		{
+			Name: "ReplaceChild",
+			Insert: func(parent spec.Element, fragment spec.DocumentFragment) {
+				hr := parent.FirstChild()
+				assert.True(t, parent.ReplaceChild(fragment, hr).IsSameNode(hr))
+				assert.Nil(t, hr.ParentNode())
+			},
+			Result: `<div>Hello, <em>world</em>!<br/></div>`,
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			document := new(dom.Document)
+			parent := document.CreateElement("div")
+			parent.Append(document.CreateElement("hr"))
+			fragment := parseDocumentFragment(t, `Hello, <em>world</em>!<br>`)
+			em := fragment.FirstElementChild()
+
+			tt.Insert(parent, fragment)
+
+			assert.Equal(t, tt.Result, parent.OuterHTML())
+			assert.False(t, fragment.HasChildNodes())
+			assert.Equal(t, "", fragment.String())
+			assert.True(t, em.ParentNode().IsSameNode(parent))
+		})
+	}
+}
+
+func TestDocumentFragment_ParentNode(t *testing.T) {
+	fragment := parseDocumentFragment(t, `Hello, <em>world</em>!<br>`)
+	em := fragment.FirstElementChild()
+
+	assert.True(t, em.ParentNode().IsSameNode(fragment))
+	assert.Nil(t, em.ParentElement())
+	assert.True(t, em.GetRootNode().IsSameNode(fragment))
+	assert.False(t, em.IsConnected())
+	assert.True(t, fragment.Contains(em))
+	assert.Equal(t, 4, fragment.ChildNodes().Length())
+	assert.Equal(t, "Hello, ", fragment.FirstChild().(spec.Text).Data())
+	assert.Equal(t, "BR", fragment.LastChild().(spec.Element).TagName())
+
+	assert.True(t, fragment.RemoveChild(em).IsSameNode(em))
+	assert.Nil(t, em.ParentNode())
+	assert.Equal(t, "Hello, !<br/>", fragment.String())
+}
+
+func TestDocument_CreateDocumentFragment(t *testing.T) {
+	// language=html
+	document := domtest.ParseStringDocument(t, `<!DOCTYPE html><html><head></head><body><main></main></body></html>`)
+
+	fragment := document.CreateDocumentFragment()
+	assert.Equal(t, spec.NodeTypeDocumentFragment, fragment.NodeType())
+	assert.False(t, fragment.HasChildNodes())
+
+	p := document.CreateElement("p")
+	fragment.Append(p, document.CreateTextNode("peach"))
+	assert.True(t, p.ParentNode().IsSameNode(fragment))
+	assert.True(t, p.OwnerDocument().IsSameNode(document))
+
+	main := document.QuerySelector("main")
+	main.AppendChild(fragment)
+	assert.Equal(t, "<main><p></p>peach</main>", main.OuterHTML())
+	assert.True(t, p.IsConnected())
+	assert.False(t, fragment.HasChildNodes())
+}
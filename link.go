@@ -0,0 +1,24 @@
+package dom
+
+import "github.com/typelate/dom/spec"
+
+// HTMLAnchorElement is based on https://html.spec.whatwg.org/#the-a-element
+type HTMLAnchorElement struct {
+	Element
+}
+
+func (a *HTMLAnchorElement) RelList() spec.DOMTokenList { return relList(a.node) }
+
+// HTMLAreaElement is based on https://html.spec.whatwg.org/#the-area-element
+type HTMLAreaElement struct {
+	Element
+}
+
+func (a *HTMLAreaElement) RelList() spec.DOMTokenList { return relList(a.node) }
+
+// HTMLLinkElement is based on https://html.spec.whatwg.org/#the-link-element
+type HTMLLinkElement struct {
+	Element
+}
+
+func (l *HTMLLinkElement) RelList() spec.DOMTokenList { return relList(l.node) }
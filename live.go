@@ -0,0 +1,184 @@
+package dom
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"weak"
+
+	"golang.org/x/net/html"
+)
+
+// NodeIterators and Ranges are updated by the mutation algorithms in this
+// file. They are tracked weakly so dropping one is enough to stop updating
+// it.
+var (
+	nodeIterators weakSet[NodeIterator]
+	liveRanges    weakSet[Range]
+)
+
+// weakSet holds pointers that are deleted once they are garbage collected.
+type weakSet[T any] struct {
+	mu sync.Mutex
+	m  map[weak.Pointer[T]]struct{}
+
+	// size is the length of m, so all does not lock an empty set.
+	size atomic.Int64
+}
+
+func (s *weakSet[T]) add(v *T) {
+	key := weak.Make(v)
+	s.mu.Lock()
+	if s.m == nil {
+		s.m = make(map[weak.Pointer[T]]struct{})
+	}
+	s.m[key] = struct{}{}
+	s.size.Store(int64(len(s.m)))
+	s.mu.Unlock()
+	runtime.AddCleanup(v, s.delete, key)
+}
+
+func (s *weakSet[T]) delete(key weak.Pointer[T]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.m, key)
+	s.size.Store(int64(len(s.m)))
+}
+
+// all returns the values that have not been garbage collected.
+func (s *weakSet[T]) all() []*T {
+	if s.size.Load() == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	values := make([]*T, 0, len(s.m))
+	for key := range s.m {
+		if v := key.Value(); v != nil {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// nodeIndex is based on https://dom.spec.whatwg.org/#concept-tree-index
+func nodeIndex(node *html.Node) int {
+	index := 0
+	for c := node.PrevSibling; c != nil; c = c.PrevSibling {
+		index++
+	}
+	return index
+}
+
+// preRemove runs the steps of https://dom.spec.whatwg.org/#concept-node-remove
+// that update NodeIterators and Ranges. It must be called before node is
+// removed from its parent.
+func preRemove(node *html.Node) {
+	iterators, ranges := nodeIterators.all(), liveRanges.all()
+	if len(iterators) == 0 && len(ranges) == 0 {
+		return
+	}
+	for _, it := range iterators {
+		it.preRemove(node)
+	}
+	if len(ranges) == 0 {
+		return
+	}
+	root, parent, index := rootHTMLNode(node), node.Parent, nodeIndex(node)
+	for _, r := range ranges {
+		r.update(root, func(p *boundaryPoint) {
+			if isInclusiveAncestor(node, p.node) {
+				*p = boundaryPoint{node: parent, offset: index}
+			}
+			if p.node == parent && p.offset > index {
+				p.offset--
+			}
+		})
+	}
+}
+
+// preInsert runs the steps of https://dom.spec.whatwg.org/#concept-node-insert
+// that update Ranges. It must be called before count nodes are inserted into
+// parent before child.
+func preInsert(parent, child *html.Node, count int) {
+	if child == nil {
+		return
+	}
+	ranges := liveRanges.all()
+	if len(ranges) == 0 {
+		return
+	}
+	root, index := rootHTMLNode(parent), nodeIndex(child)
+	for _, r := range ranges {
+		r.update(root, func(p *boundaryPoint) {
+			if p.node == parent && p.offset > index {
+				p.offset += count
+			}
+		})
+	}
+}
+
+// dataReplaced runs the steps of https://dom.spec.whatwg.org/#concept-cd-replace
+// that update Ranges after count code units at offset were replaced by
+// length code units.
+func dataReplaced(node *html.Node, offset, count, length int) {
+	ranges := liveRanges.all()
+	if len(ranges) == 0 {
+		return
+	}
+	root := rootHTMLNode(node)
+	for _, r := range ranges {
+		r.update(root, func(p *boundaryPoint) {
+			if p.node != node {
+				return
+			}
+			switch {
+			case p.offset > offset+count:
+				p.offset += length - count
+			case p.offset > offset:
+				p.offset = offset
+			}
+		})
+	}
+}
+
+// textSplit runs the steps of https://dom.spec.whatwg.org/#concept-text-split
+// that update Ranges after newNode was inserted after node.
+func textSplit(node, newNode *html.Node, offset int) {
+	ranges := liveRanges.all()
+	if len(ranges) == 0 {
+		return
+	}
+	root, parent, index := rootHTMLNode(node), node.Parent, nodeIndex(node)
+	for _, r := range ranges {
+		r.update(root, func(p *boundaryPoint) {
+			switch {
+			case p.node == node && p.offset > offset:
+				*p = boundaryPoint{node: newNode, offset: p.offset - offset}
+			case p.node == parent && p.offset == index+1:
+				p.offset++
+			}
+		})
+	}
+}
+
+// textMerged runs the steps of https://dom.spec.whatwg.org/#dom-node-normalize
+// that update Ranges before next, whose data now follows length code units
+// of node, is removed.
+func textMerged(node, next *html.Node, length int) {
+	ranges := liveRanges.all()
+	if len(ranges) == 0 {
+		return
+	}
+	root, parent, index := rootHTMLNode(node), next.Parent, nodeIndex(next)
+	for _, r := range ranges {
+		r.update(root, func(p *boundaryPoint) {
+			switch {
+			case p.node == next:
+				*p = boundaryPoint{node: node, offset: p.offset + length}
+			case p.node == parent && p.offset == index:
+				*p = boundaryPoint{node: node, offset: length}
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package dom
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+	"weak"
+
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+var _ spec.MutationObserver = (*MutationObserver)(nil)
+
+// MutationObserver is based on https://dom.spec.whatwg.org/#interface-mutationobserver.
+//
+// There is no microtask queue, so the callback is only called by
+// NotifyMutationObservers. An observer without a callback is read with
+// TakeRecords.
+type MutationObserver struct {
+	callback spec.MutationCallback
+
+	// The fields below are guarded by mutationObservers.mu.
+	nodes          []weak.Pointer[html.Node]
+	transientNodes []weak.Pointer[html.Node]
+	records        []spec.MutationRecord
+}
+
+// registeredObserver is based on https://dom.spec.whatwg.org/#registered-observer
+// and https://dom.spec.whatwg.org/#transient-registered-observer. source is
+// only set for a transient registered observer.
+type registeredObserver struct {
+	observer *MutationObserver
+	options  spec.MutationObserverInit
+	source   *registeredObserver
+}
+
+// mutationObservers holds the registered observer list of each node and the
+// observers with records waiting for NotifyMutationObservers. Like the spec,
+// a node keeps its observers alive but an observer does not keep its nodes
+// alive.
+var mutationObservers struct {
+	mu      sync.Mutex
+	m       map[weak.Pointer[html.Node]][]*registeredObserver
+	pending []*MutationObserver
+}
+
+// NewMutationObserver is based on https://dom.spec.whatwg.org/#dom-mutationobserver-mutationobserver
+// callback may be nil.
+func NewMutationObserver(callback spec.MutationCallback) *MutationObserver {
+	return &MutationObserver{callback: callback}
+}
+
+// Observe is based on https://dom.spec.whatwg.org/#dom-mutationobserver-observe
+func (o *MutationObserver) Observe(target spec.Node, options spec.MutationObserverInit) error {
+	if options.AttributeOldValue || options.AttributeFilter != nil {
+		options.Attributes = true
+	}
+	if options.CharacterDataOldValue {
+		options.CharacterData = true
+	}
+	if !options.ChildList && !options.Attributes && !options.CharacterData {
+		return fmt.Errorf("%w: one of ChildList, Attributes, or CharacterData must be set", spec.ErrType)
+	}
+	if _, ok := target.(*Attr); ok || target == nil {
+		return fmt.Errorf("%w: %T can not be observed", spec.ErrNotSupported, target)
+	}
+	node := domNodeToHTMLNode(target)
+	key := weak.Make(node)
+
+	mutationObservers.mu.Lock()
+	defer mutationObservers.mu.Unlock()
+	if mutationObservers.m == nil {
+		mutationObservers.m = make(map[weak.Pointer[html.Node]][]*registeredObserver)
+	}
+	for _, registered := range mutationObservers.m[key] {
+		if registered.observer == o && registered.source == nil {
+			o.removeTransientObservers(registered)
+			registered.options = options
+			return nil
+		}
+	}
+	addRegisteredObserver(node, key, &registeredObserver{observer: o, options: options})
+	o.nodes = append(o.nodes, key)
+	return nil
+}
+
+// Disconnect is based on https://dom.spec.whatwg.org/#dom-mutationobserver-disconnect
+func (o *MutationObserver) Disconnect() {
+	mutationObservers.mu.Lock()
+	defer mutationObservers.mu.Unlock()
+	for _, key := range slices.Concat(o.nodes, o.transientNodes) {
+		deleteRegisteredObservers(key, func(registered *registeredObserver) bool {
+			return registered.observer == o
+		})
+	}
+	o.nodes, o.transientNodes, o.records = nil, nil, nil
+}
+
+// TakeRecords is based on https://dom.spec.whatwg.org/#dom-mutationobserver-takerecords
+// It also ends the observation of removed subtrees through transient
+// registered observers, as NotifyMutationObservers does.
+func (o *MutationObserver) TakeRecords() []spec.MutationRecord {
+	mutationObservers.mu.Lock()
+	defer mutationObservers.mu.Unlock()
+	return o.takeRecords()
+}
+
+func (o *MutationObserver) takeRecords() []spec.MutationRecord {
+	records := o.records
+	o.records = nil
+	o.removeTransientObservers(nil)
+	return records
+}
+
+// removeTransientObservers removes the transient registered observers of o
+// copied from source, or all of them when source is nil.
+func (o *MutationObserver) removeTransientObservers(source *registeredObserver) {
+	for _, key := range o.transientNodes {
+		deleteRegisteredObservers(key, func(registered *registeredObserver) bool {
+			return registered.observer == o && registered.source != nil && (source == nil || registered.source == source)
+		})
+	}
+	if source == nil {
+		o.transientNodes = nil
+	}
+}
+
+// NotifyMutationObservers is based on https://dom.spec.whatwg.org/#notify-mutation-observers.
+// It calls the callback of each observer with queued records, in the order
+// the observers first queued a record, until none are left.
+func NotifyMutationObservers() {
+	for {
+		mutationObservers.mu.Lock()
+		pending := mutationObservers.pending
+		mutationObservers.pending = nil
+		mutationObservers.mu.Unlock()
+		if len(pending) == 0 {
+			return
+		}
+		for _, o := range pending {
+			mutationObservers.mu.Lock()
+			records := o.takeRecords()
+			mutationObservers.mu.Unlock()
+			if len(records) > 0 && o.callback != nil {
+				o.callback(records, o)
+			}
+		}
+	}
+}
+
+func addRegisteredObserver(node *html.Node, key weak.Pointer[html.Node], registered *registeredObserver) {
+	list, ok := mutationObservers.m[key]
+	if !ok {
+		runtime.AddCleanup(node, deleteMutationObservers, key)
+	}
+	mutationObservers.m[key] = append(list, registered)
+}
+
+func deleteRegisteredObservers(key weak.Pointer[html.Node], match func(*registeredObserver) bool) {
+	if list, ok := mutationObservers.m[key]; ok {
+		mutationObservers.m[key] = slices.DeleteFunc(list, match)
+	}
+}
+
+func deleteMutationObservers(key weak.Pointer[html.Node]) {
+	mutationObservers.mu.Lock()
+	defer mutationObservers.mu.Unlock()
+	delete(mutationObservers.m, key)
+}
+
+// addTransientObservers runs the step of https://dom.spec.whatwg.org/#concept-node-remove
+// that keeps subtree observers of parent observing node after it is removed.
+func addTransientObservers(parent, node *html.Node) {
+	mutationObservers.mu.Lock()
+	defer mutationObservers.mu.Unlock()
+	if len(mutationObservers.m) == 0 {
+		return
+	}
+	key := weak.Make(node)
+	for _, ancestor := range observedAncestors(parent, isTemplate(parent)) {
+		for _, registered := range mutationObservers.m[weak.Make(ancestor)] {
+			if !registered.options.Subtree {
+				continue
+			}
+			addRegisteredObserver(node, key, &registeredObserver{
+				observer: registered.observer,
+				options:  registered.options,
+				source:   registered,
+			})
+			registered.observer.transientNodes = append(registered.observer.transientNodes, key)
+		}
+	}
+}
+
+// observedAncestors returns the inclusive ancestors of node. The template
+// contents end at the template, which also backs their DocumentFragment, so
+// contents is set when node is a template standing for its contents.
+func observedAncestors(node *html.Node, contents bool) []*html.Node {
+	nodes := []*html.Node{node}
+	if contents {
+		return nodes
+	}
+	for n := node; n.Parent != nil; n = n.Parent {
+		nodes = append(nodes, n.Parent)
+		if isTemplate(n.Parent) {
+			break
+		}
+	}
+	return nodes
+}
+
+// mutation holds the arguments of https://dom.spec.whatwg.org/#queue-a-mutation-record.
+type mutation struct {
+	kind            spec.MutationRecordType
+	target          *html.Node
+	name, namespace string
+	oldValue        string
+	added, removed  []*html.Node
+	previousSibling *html.Node
+	nextSibling     *html.Node
+}
+
+// queueMutationRecord is based on https://dom.spec.whatwg.org/#queue-a-mutation-record
+func queueMutationRecord(m mutation) {
+	mutationObservers.mu.Lock()
+	defer mutationObservers.mu.Unlock()
+	if len(mutationObservers.m) == 0 {
+		return
+	}
+	var (
+		interested []*MutationObserver
+		oldValues  = make(map[*MutationObserver]bool)
+	)
+	for _, node := range observedAncestors(m.target, m.kind == spec.MutationChildList && isTemplate(m.target)) {
+		for _, registered := range mutationObservers.m[weak.Make(node)] {
+			options := registered.options
+			switch {
+			case node != m.target && !options.Subtree,
+				m.kind == spec.MutationAttributes && !options.Attributes,
+				m.kind == spec.MutationAttributes && options.AttributeFilter != nil && (m.namespace != "" || !slices.Contains(options.AttributeFilter, m.name)),
+				m.kind == spec.MutationCharacterData && !options.CharacterData,
+				m.kind == spec.MutationChildList && !options.ChildList:
+				continue
+			}
+			o := registered.observer
+			if _, ok := oldValues[o]; !ok {
+				interested = append(interested, o)
+				oldValues[o] = false
+			}
+			if (m.kind == spec.MutationAttributes && options.AttributeOldValue) || (m.kind == spec.MutationCharacterData && options.CharacterDataOldValue) {
+				oldValues[o] = true
+			}
+		}
+	}
+	if len(interested) == 0 {
+		return
+	}
+	record := spec.MutationRecord{
+		Type:               m.kind,
+		Target:             mutationTarget(m),
+		AddedNodes:         wrapHTMLNodes(m.added),
+		RemovedNodes:       wrapHTMLNodes(m.removed),
+		PreviousSibling:    NewNode(m.previousSibling),
+		NextSibling:        NewNode(m.nextSibling),
+		AttributeName:      m.name,
+		AttributeNamespace: m.namespace,
+	}
+	for _, o := range interested {
+		r := record
+		if oldValues[o] {
+			r.OldValue = m.oldValue
+		}
+		if len(o.records) == 0 && !slices.Contains(mutationObservers.pending, o) {
+			mutationObservers.pending = append(mutationObservers.pending, o)
+		}
+		o.records = append(o.records, r)
+	}
+}
+
+// mutationTarget returns the DocumentFragment for changes to the children of
+// a template, since they are the template contents.
+func mutationTarget(m mutation) spec.Node {
+	if m.kind == spec.MutationChildList && isTemplate(m.target) {
+		return &DocumentFragment{node: m.target}
+	}
+	return NewNode(m.target)
+}
+
+func wrapHTMLNodes(nodes []*html.Node) []spec.Node {
+	if len(nodes) == 0 {
+		return nil
+	}
+	result := make([]spec.Node, len(nodes))
+	for i, n := range nodes {
+		result[i] = NewNode(n)
+	}
+	return result
+}
+
+// queueTreeMutationRecord is based on https://dom.spec.whatwg.org/#queue-a-tree-mutation-record
+func queueTreeMutationRecord(target *html.Node, added, removed []*html.Node, previousSibling, nextSibling *html.Node) {
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+	queueMutationRecord(mutation{
+		kind:            spec.MutationChildList,
+		target:          target,
+		added:           added,
+		removed:         removed,
+		previousSibling: previousSibling,
+		nextSibling:     nextSibling,
+	})
+}
+
+// queueAttributeMutationRecord is based on https://dom.spec.whatwg.org/#handle-attribute-changes
+// oldValue is empty when the attribute is being added.
+func queueAttributeMutationRecord(node *html.Node, att html.Attribute, oldValue string) {
+	queueMutationRecord(mutation{
+		kind:      spec.MutationAttributes,
+		target:    node,
+		name:      att.Key,
+		namespace: attributeNamespaceURI(node, att),
+		oldValue:  oldValue,
+	})
+}
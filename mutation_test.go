@@ -0,0 +1,232 @@
+package dom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/typelate/dom/spec"
+)
+
+func TestMutationObserver(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang='us-en'>
+<head><title></title></head>
+<body><main id='target'><h1 class='title'>Title</h1><p>Some text</p></main><aside></aside></body>
+</html>`
+
+	observe := func(t *testing.T, target spec.Node, options spec.MutationObserverInit) *MutationObserver {
+		t.Helper()
+		observer := NewMutationObserver(nil)
+		require.NoError(t, observer.Observe(target, options))
+		t.Cleanup(observer.Disconnect)
+		return observer
+	}
+	names := func(nodes []spec.Node) []string {
+		var result []string
+		for _, n := range nodes {
+			if n.NodeType() == spec.NodeTypeText {
+				result = append(result, "#text")
+				continue
+			}
+			result = append(result, nodeTagName(n))
+		}
+		return result
+	}
+
+	t.Run("append and insert", func(t *testing.T) {
+		document, main := parseDocument(t, textHTML, "#target")
+		observer := observe(t, main, spec.MutationObserverInit{ChildList: true})
+		h1, p := main.FirstElementChild(), main.LastElementChild()
+
+		span := document.CreateElement("span")
+		main.AppendChild(span)
+		em := document.CreateElement("em")
+		main.InsertBefore(em, p)
+
+		records := observer.TakeRecords()
+		require.Len(t, records, 2)
+		assert.Equal(t, spec.MutationChildList, records[0].Type)
+		assert.True(t, records[0].Target.IsSameNode(main))
+		assert.Equal(t, []string{"SPAN"}, names(records[0].AddedNodes))
+		assert.True(t, records[0].PreviousSibling.IsSameNode(p))
+		assert.Nil(t, records[0].NextSibling)
+		assert.Equal(t, []string{"EM"}, names(records[1].AddedNodes))
+		assert.True(t, records[1].PreviousSibling.IsSameNode(h1))
+		assert.True(t, records[1].NextSibling.IsSameNode(p))
+		assert.Empty(t, observer.TakeRecords())
+	})
+	t.Run("replace and remove", func(t *testing.T) {
+		document, main := parseDocument(t, textHTML, "#target")
+		observer := observe(t, main, spec.MutationObserverInit{ChildList: true})
+		h1, p := main.FirstElementChild(), main.LastElementChild()
+
+		header := document.CreateElement("header")
+		main.ReplaceChild(header, h1)
+		main.RemoveChild(p)
+
+		records := observer.TakeRecords()
+		require.Len(t, records, 2)
+		assert.Equal(t, []string{"HEADER"}, names(records[0].AddedNodes))
+		assert.Equal(t, []string{"H1"}, names(records[0].RemovedNodes))
+		assert.Nil(t, records[0].PreviousSibling)
+		assert.True(t, records[0].NextSibling.IsSameNode(p))
+		assert.Empty(t, records[1].AddedNodes)
+		assert.Equal(t, []string{"P"}, names(records[1].RemovedNodes))
+		assert.True(t, records[1].PreviousSibling.IsSameNode(header))
+	})
+	t.Run("fragments and replace all", func(t *testing.T) {
+		document, main := parseDocument(t, textHTML, "#target")
+		observer := observe(t, main, spec.MutationObserverInit{ChildList: true})
+
+		fragment := document.CreateDocumentFragment()
+		fragment.Append(document.CreateElement("b"), document.CreateElement("i"))
+		fragmentObserver := observe(t, fragment, spec.MutationObserverInit{ChildList: true})
+		main.Prepend(fragment)
+		main.Append(document.CreateTextNode("end"))
+		main.ReplaceChildren(document.CreateElement("section"))
+		require.NoError(t, main.SetInnerHTML("<p>a</p><p>b</p>"))
+
+		records := observer.TakeRecords()
+		require.Len(t, records, 4)
+		assert.Equal(t, []string{"B", "I"}, names(records[0].AddedNodes))
+		assert.Equal(t, []string{"#text"}, names(records[1].AddedNodes))
+		assert.Equal(t, []string{"SECTION"}, names(records[2].AddedNodes))
+		assert.Equal(t, []string{"B", "I", "H1", "P", "#text"}, names(records[2].RemovedNodes))
+		assert.Equal(t, []string{"P", "P"}, names(records[3].AddedNodes))
+		assert.Equal(t, []string{"SECTION"}, names(records[3].RemovedNodes))
+
+		fragmentRecords := fragmentObserver.TakeRecords()
+		require.Len(t, fragmentRecords, 1)
+		assert.Equal(t, []string{"B", "I"}, names(fragmentRecords[0].RemovedNodes))
+	})
+	t.Run("outer html", func(t *testing.T) {
+		_, main := parseDocument(t, textHTML, "#target")
+		body := main.ParentElement()
+		observer := observe(t, body, spec.MutationObserverInit{ChildList: true})
+		require.NoError(t, main.SetOuterHTML("<nav></nav><div></div>"))
+
+		records := observer.TakeRecords()
+		require.Len(t, records, 1)
+		assert.Equal(t, []string{"NAV", "DIV"}, names(records[0].AddedNodes))
+		assert.Equal(t, []string{"MAIN"}, names(records[0].RemovedNodes))
+		assert.Nil(t, records[0].PreviousSibling)
+		assert.Equal(t, "ASIDE", nodeTagName(records[0].NextSibling))
+	})
+	t.Run("move between observed parents", func(t *testing.T) {
+		document, main := parseDocument(t, textHTML, "#target")
+		observer := observe(t, document, spec.MutationObserverInit{ChildList: true, Subtree: true})
+		aside := document.QuerySelector("aside")
+		aside.AppendChild(main.FirstElementChild())
+
+		records := observer.TakeRecords()
+		require.Len(t, records, 2)
+		assert.True(t, records[0].Target.IsSameNode(main))
+		assert.Equal(t, []string{"H1"}, names(records[0].RemovedNodes))
+		assert.True(t, records[1].Target.IsSameNode(aside))
+		assert.Equal(t, []string{"H1"}, names(records[1].AddedNodes))
+	})
+	t.Run("attributes", func(t *testing.T) {
+		_, main := parseDocument(t, textHTML, "#target")
+		observer := observe(t, main, spec.MutationObserverInit{AttributeOldValue: true})
+		main.SetAttribute("id", "main")
+		main.SetAttribute("hidden", "")
+		main.ToggleAttribute("hidden")
+		main.RemoveAttribute("id")
+		main.FirstElementChild().SetAttribute("class", "heading")
+
+		records := observer.TakeRecords()
+		require.Len(t, records, 4, "the h1 is not observed without Subtree")
+		for _, record := range records {
+			assert.Equal(t, spec.MutationAttributes, record.Type)
+			assert.True(t, record.Target.IsSameNode(main))
+		}
+		assert.Equal(t, []string{"id", "hidden", "hidden", "id"}, []string{records[0].AttributeName, records[1].AttributeName, records[2].AttributeName, records[3].AttributeName})
+		assert.Equal(t, []string{"target", "", "", "main"}, []string{records[0].OldValue, records[1].OldValue, records[2].OldValue, records[3].OldValue})
+	})
+	t.Run("attribute filter", func(t *testing.T) {
+		_, main := parseDocument(t, textHTML, "#target")
+		observer := observe(t, main, spec.MutationObserverInit{AttributeFilter: []string{"class"}, Subtree: true})
+		main.SetAttribute("id", "main")
+		main.FirstElementChild().ClassList().Add("large")
+
+		records := observer.TakeRecords()
+		require.Len(t, records, 1)
+		assert.Equal(t, "class", records[0].AttributeName)
+		assert.Equal(t, "H1", nodeTagName(records[0].Target))
+		assert.Empty(t, records[0].OldValue, "AttributeOldValue is not set")
+	})
+	t.Run("character data", func(t *testing.T) {
+		_, main := parseDocument(t, textHTML, "#target")
+		observer := observe(t, main, spec.MutationObserverInit{CharacterDataOldValue: true, Subtree: true})
+		text := main.QuerySelector("p").FirstChild().(*Text)
+		text.SetData("Other text")
+		text.AppendData("!")
+
+		records := observer.TakeRecords()
+		require.Len(t, records, 2)
+		assert.Equal(t, spec.MutationCharacterData, records[0].Type)
+		assert.True(t, records[0].Target.IsSameNode(text))
+		assert.Equal(t, "Some text", records[0].OldValue)
+		assert.Equal(t, "Other text", records[1].OldValue)
+	})
+	t.Run("removed subtree", func(t *testing.T) {
+		_, main := parseDocument(t, textHTML, "#target")
+		observer := observe(t, main, spec.MutationObserverInit{Attributes: true, ChildList: true, Subtree: true})
+		h1 := main.FirstElementChild()
+		h1.Remove()
+		h1.SetAttribute("id", "removed")
+
+		records := observer.TakeRecords()
+		require.Len(t, records, 2)
+		assert.Equal(t, spec.MutationChildList, records[0].Type)
+		assert.Equal(t, spec.MutationAttributes, records[1].Type)
+		assert.True(t, records[1].Target.IsSameNode(h1))
+
+		h1.SetAttribute("id", "again")
+		assert.Empty(t, observer.TakeRecords(), "taking the records ends the transient observer")
+	})
+	t.Run("observe again and disconnect", func(t *testing.T) {
+		_, main := parseDocument(t, textHTML, "#target")
+		observer := observe(t, main, spec.MutationObserverInit{ChildList: true})
+		require.NoError(t, observer.Observe(main, spec.MutationObserverInit{Attributes: true}))
+		main.FirstElementChild().Remove()
+		main.SetAttribute("id", "main")
+		require.Len(t, observer.TakeRecords(), 1)
+
+		main.SetAttribute("id", "other")
+		observer.Disconnect()
+		assert.Empty(t, observer.TakeRecords())
+		main.SetAttribute("id", "last")
+		assert.Empty(t, observer.TakeRecords())
+	})
+	t.Run("invalid options", func(t *testing.T) {
+		_, main := parseDocument(t, textHTML, "#target")
+		observer := NewMutationObserver(nil)
+		require.ErrorIs(t, observer.Observe(main, spec.MutationObserverInit{Subtree: true}), spec.ErrType)
+		require.ErrorIs(t, observer.Observe(main.GetAttributeNode("id"), spec.MutationObserverInit{Attributes: true}), spec.ErrNotSupported)
+	})
+	t.Run("notify", func(t *testing.T) {
+		_, main := parseDocument(t, textHTML, "#target")
+		var (
+			calls     [][]spec.MutationRecord
+			observers []spec.MutationObserver
+		)
+		observer := NewMutationObserver(func(records []spec.MutationRecord, observer spec.MutationObserver) {
+			calls = append(calls, records)
+			observers = append(observers, observer)
+		})
+		require.NoError(t, observer.Observe(main, spec.MutationObserverInit{ChildList: true}))
+		t.Cleanup(observer.Disconnect)
+		main.FirstElementChild().Remove()
+		main.FirstElementChild().Remove()
+		NotifyMutationObservers()
+		NotifyMutationObservers()
+
+		require.Len(t, calls, 1)
+		assert.Len(t, calls[0], 2)
+		assert.Same(t, observer, observers[0])
+	})
+}
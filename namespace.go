@@ -0,0 +1,267 @@
+package dom
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/typelate/dom/spec"
+)
+
+// x/net/html sets html.Node.Namespace to "" for HTML elements and to "svg" or
+// "math" for foreign elements. Elements created in any other namespace store
+// the namespace URI. Attribute namespaces hold the prefix x/net/html renders:
+// "xlink", "xml", or "xmlns".
+
+func elementNamespaceURI(node *html.Node) string {
+	switch node.Namespace {
+	case "":
+		return spec.HTMLNamespace
+	case "svg":
+		return spec.SVGNamespace
+	case "math":
+		return spec.MathMLNamespace
+	default:
+		return node.Namespace
+	}
+}
+
+// elementPrefix returns the prefix of a foreign element created with a
+// qualified name. Elements in the HTML namespace never have a prefix.
+func elementPrefix(node *html.Node) string {
+	if node.Namespace == "" {
+		return ""
+	}
+	prefix, _, ok := strings.Cut(node.Data, ":")
+	if !ok {
+		return ""
+	}
+	return prefix
+}
+
+func elementLocalName(node *html.Node) string {
+	if node.Namespace == "" {
+		return node.Data
+	}
+	if _, localName, ok := strings.Cut(node.Data, ":"); ok {
+		return localName
+	}
+	return node.Data
+}
+
+// tagName is based on https://dom.spec.whatwg.org/#dom-element-tagname
+func tagName(node *html.Node) string {
+	if node.Namespace == "" {
+		return strings.ToUpper(node.Data)
+	}
+	return node.Data
+}
+
+func attributeNamespaceURI(node *html.Node, att html.Attribute) string {
+	switch att.Namespace {
+	case "xlink":
+		return spec.XLinkNamespace
+	case "xml":
+		return spec.XMLNamespace
+	case "xmlns":
+		return spec.XMLNSNamespace
+	case "":
+		// the HTML parser puts xmlns attributes on foreign elements in the XMLNS namespace
+		if att.Key == "xmlns" && node != nil && node.Namespace != "" {
+			return spec.XMLNSNamespace
+		}
+		return ""
+	default:
+		return ""
+	}
+}
+
+// attributeIndexNS is based on https://dom.spec.whatwg.org/#concept-element-attributes-get-by-namespace
+func attributeIndexNS(node *html.Node, namespace, localName string) int {
+	for i, att := range node.Attr {
+		if att.Key == localName && attributeNamespaceURI(node, att) == namespace {
+			return i
+		}
+	}
+	return -1
+}
+
+// validateAndExtract is based on https://dom.spec.whatwg.org/#validate-and-extract
+func validateAndExtract(namespace, qualifiedName string) (prefix, localName string, err error) {
+	localName = qualifiedName
+	if p, l, ok := strings.Cut(qualifiedName, ":"); ok {
+		prefix, localName = p, l
+	}
+	if qualifiedName == "" || localName == "" || strings.Contains(localName, ":") || strings.ContainsAny(qualifiedName, "\t\n\f\r />\x00") ||
+		strings.Contains(qualifiedName, ":") && prefix == "" {
+		return "", "", fmt.Errorf("%w: %q is not a valid qualified name", spec.ErrInvalidCharacter, qualifiedName)
+	}
+	switch {
+	case prefix != "" && namespace == "":
+		return "", "", fmt.Errorf("%w: prefix %q requires a namespace", spec.ErrNamespace, prefix)
+	case prefix == "xml" && namespace != spec.XMLNamespace:
+		return "", "", fmt.Errorf("%w: prefix xml requires the XML namespace", spec.ErrNamespace)
+	case (qualifiedName == "xmlns" || prefix == "xmlns") != (namespace == spec.XMLNSNamespace):
+		return "", "", fmt.Errorf("%w: xmlns must be used with the XMLNS namespace", spec.ErrNamespace)
+	}
+	return prefix, localName, nil
+}
+
+// createElementNS is based on https://dom.spec.whatwg.org/#internal-createelementns-steps
+func createElementNS(namespace, qualifiedName string) (*html.Node, error) {
+	prefix, localName, err := validateAndExtract(namespace, qualifiedName)
+	if err != nil {
+		return nil, err
+	}
+	node := &html.Node{
+		Type:     html.ElementNode,
+		DataAtom: atom.Lookup([]byte(localName)),
+		Data:     qualifiedName,
+	}
+	switch namespace {
+	case spec.HTMLNamespace:
+		if prefix != "" {
+			return nil, fmt.Errorf("%w: prefixed elements in the HTML namespace", spec.ErrNotSupported)
+		}
+	case spec.SVGNamespace:
+		node.Namespace = "svg"
+	case spec.MathMLNamespace:
+		node.Namespace = "math"
+	case "":
+		return nil, fmt.Errorf("%w: elements in the null namespace", spec.ErrNotSupported)
+	default:
+		node.Namespace = namespace
+	}
+	return node, nil
+}
+
+// setAttributeNS is based on https://dom.spec.whatwg.org/#dom-element-setattributens
+//
+// Attributes may only be in the null, XLink, XML, or XMLNS namespaces, since
+// html.Attribute can not hold both a prefix and a namespace URI. The stored
+// prefix is the one x/net/html uses for the namespace.
+func setAttributeNS(node *html.Node, namespace, qualifiedName, value string) error {
+	prefix, localName, err := validateAndExtract(namespace, qualifiedName)
+	if err != nil {
+		return err
+	}
+	att := html.Attribute{Key: localName, Val: value}
+	switch namespace {
+	case "":
+	case spec.XLinkNamespace:
+		att.Namespace = "xlink"
+	case spec.XMLNamespace:
+		att.Namespace = "xml"
+	case spec.XMLNSNamespace:
+		if prefix != "" {
+			att.Namespace = "xmlns"
+		}
+	default:
+		return fmt.Errorf("%w: attributes in namespace %q", spec.ErrNotSupported, namespace)
+	}
+	if i := attributeIndexNS(node, namespace, localName); i >= 0 {
+		changeAttribute(node, i, value)
+		return nil
+	}
+	appendAttribute(node, att)
+	return nil
+}
+
+// getElementsByTagNameNS is based on https://dom.spec.whatwg.org/#concept-getelementsbytagnamens
+func getElementsByTagNameNS(node *html.Node, namespace, localName string) *liveElements {
+	return filterDescendants(node, func(n *html.Node) bool {
+		return (namespace == "*" || elementNamespaceURI(n) == namespace) &&
+			(localName == "*" || elementLocalName(n) == localName)
+	})
+}
+
+// locateNamespace is based on https://dom.spec.whatwg.org/#locate-a-namespace
+// where node is the element to start from.
+func locateNamespace(node *html.Node, prefix string) string {
+	switch prefix {
+	case "xml":
+		return spec.XMLNamespace
+	case "xmlns":
+		return spec.XMLNSNamespace
+	}
+	for el := node; el != nil && el.Type == html.ElementNode; el = el.Parent {
+		if elementPrefix(el) == prefix {
+			return elementNamespaceURI(el)
+		}
+		for _, att := range el.Attr {
+			if attributeNamespaceURI(el, att) != spec.XMLNSNamespace {
+				continue
+			}
+			if prefix != "" && att.Namespace == "xmlns" && att.Key == prefix ||
+				prefix == "" && att.Namespace == "" && att.Key == "xmlns" {
+				return att.Val
+			}
+		}
+	}
+	return ""
+}
+
+// locateNamespacePrefix is based on https://dom.spec.whatwg.org/#locate-a-namespace-prefix
+func locateNamespacePrefix(node *html.Node, namespace string) string {
+	if namespace == "" {
+		return ""
+	}
+	for el := node; el != nil && el.Type == html.ElementNode; el = el.Parent {
+		if prefix := elementPrefix(el); prefix != "" && elementNamespaceURI(el) == namespace {
+			return prefix
+		}
+		for _, att := range el.Attr {
+			if att.Namespace == "xmlns" && att.Val == namespace {
+				return att.Key
+			}
+		}
+	}
+	return ""
+}
+
+// lookupElement returns the element the namespace lookup algorithms start from for node.
+func lookupElement(node *html.Node) *html.Node {
+	if node == nil {
+		return nil
+	}
+	switch node.Type {
+	case html.ElementNode:
+		return node
+	case html.DocumentNode:
+		return documentElement(node)
+	case html.DoctypeNode:
+		return nil
+	default:
+		if node.Parent != nil && node.Parent.Type == html.ElementNode {
+			return node.Parent
+		}
+		return nil
+	}
+}
+
+func lookupNamespaceURI(node *html.Node, prefix string) string {
+	el := lookupElement(node)
+	if el == nil {
+		return ""
+	}
+	return locateNamespace(el, prefix)
+}
+
+func lookupPrefix(node *html.Node, namespace string) string {
+	el := lookupElement(node)
+	if el == nil {
+		return ""
+	}
+	return locateNamespacePrefix(el, namespace)
+}
+
+func documentElement(node *html.Node) *html.Node {
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode {
+			return c
+		}
+	}
+	return nil
+}
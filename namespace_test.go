@@ -0,0 +1,165 @@
+package dom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/typelate/dom/spec"
+)
+
+func TestElement_namespace(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang='us-en'>
+<head><title></title></head>
+<body><div id='icon'><svg viewBox='0 0 10 10'><linearGradient id='g'></linearGradient><use xlink:href='#g'></use></svg><math><mi>x</mi></math></div></body>
+</html>`
+	document, icon := parseDocument(t, textHTML, "#icon")
+
+	t.Run("html", func(t *testing.T) {
+		assert.Equal(t, spec.HTMLNamespace, icon.NamespaceURI())
+		assert.Equal(t, "", icon.Prefix())
+		assert.Equal(t, "div", icon.LocalName())
+		assert.Equal(t, "DIV", icon.TagName())
+	})
+	t.Run("svg", func(t *testing.T) {
+		gradient := icon.QuerySelector("#g")
+		assert.Equal(t, spec.SVGNamespace, gradient.NamespaceURI())
+		assert.Equal(t, "linearGradient", gradient.LocalName())
+		assert.Equal(t, "linearGradient", gradient.TagName())
+		assert.Equal(t, "0 0 10 10", icon.FirstElementChild().GetAttribute("viewBox"))
+	})
+	t.Run("mathml", func(t *testing.T) {
+		assert.Equal(t, spec.MathMLNamespace, icon.LastElementChild().NamespaceURI())
+	})
+	t.Run("xlink attribute", func(t *testing.T) {
+		use := icon.QuerySelector("use")
+		assert.Equal(t, "#g", use.GetAttributeNS(spec.XLinkNamespace, "href"))
+		assert.True(t, use.HasAttributeNS(spec.XLinkNamespace, "href"))
+		assert.False(t, use.HasAttributeNS("", "href"))
+		assert.Equal(t, "", use.GetAttribute("href"))
+		assert.Equal(t, "#g", use.GetAttribute("xlink:href"))
+
+		attr := use.GetAttributeNode("xlink:href")
+		assert.Equal(t, spec.XLinkNamespace, attr.NamespaceURI())
+		assert.Equal(t, "xlink", attr.Prefix())
+
+		require.NoError(t, use.SetAttributeNS(spec.XLinkNamespace, "xlink:href", "#h"))
+		assert.Equal(t, `<use xlink:href="#h"></use>`, use.OuterHTML())
+
+		use.RemoveAttributeNS(spec.XLinkNamespace, "href")
+		assert.False(t, use.HasAttributes())
+	})
+	t.Run("GetElementsByTagName", func(t *testing.T) {
+		assert.Equal(t, 1, icon.GetElementsByTagName("linearGradient").Length())
+		assert.Equal(t, 0, icon.GetElementsByTagName("lineargradient").Length())
+		assert.Equal(t, 0, icon.GetElementsByTagName("SVG").Length())
+		assert.Equal(t, 1, document.GetElementsByTagName("DIV").Length(), "HTML names are matched case-insensitively")
+		assert.Equal(t, 5, icon.GetElementsByTagName("*").Length())
+	})
+	t.Run("GetElementsByTagNameNS", func(t *testing.T) {
+		assert.Equal(t, 3, icon.GetElementsByTagNameNS(spec.SVGNamespace, "*").Length())
+		assert.Equal(t, 1, icon.GetElementsByTagNameNS(spec.SVGNamespace, "use").Length())
+		assert.Equal(t, 0, icon.GetElementsByTagNameNS(spec.HTMLNamespace, "use").Length())
+		assert.Equal(t, 2, icon.GetElementsByTagNameNS(spec.MathMLNamespace, "*").Length())
+		assert.Equal(t, 1, icon.GetElementsByTagNameNS("*", "mi").Length())
+	})
+}
+
+func TestDocument_CreateElementNS(t *testing.T) {
+	var document *Document
+
+	t.Run("svg", func(t *testing.T) {
+		svg, err := document.CreateElementNS(spec.SVGNamespace, "svg")
+		require.NoError(t, err)
+		gradient, err := document.CreateElementNS(spec.SVGNamespace, "linearGradient")
+		require.NoError(t, err)
+		svg.Append(gradient)
+
+		assert.Equal(t, "linearGradient", gradient.TagName())
+		assert.Equal(t, spec.SVGNamespace, gradient.NamespaceURI())
+		require.NoError(t, gradient.SetAttributeNS("", "gradientUnits", "userSpaceOnUse"))
+		assert.Equal(t, "userSpaceOnUse", gradient.GetAttribute("gradientUnits"))
+		assert.Equal(t, `<svg><linearGradient gradientUnits="userSpaceOnUse"></linearGradient></svg>`, svg.OuterHTML())
+	})
+	t.Run("prefixed", func(t *testing.T) {
+		rect, err := document.CreateElementNS(spec.SVGNamespace, "s:rect")
+		require.NoError(t, err)
+		assert.Equal(t, "s", rect.Prefix())
+		assert.Equal(t, "rect", rect.LocalName())
+		assert.Equal(t, "s:rect", rect.TagName())
+		assert.Equal(t, spec.SVGNamespace, rect.LookupNamespaceURI("s"))
+		assert.Equal(t, "s", rect.LookupPrefix(spec.SVGNamespace))
+	})
+	t.Run("html", func(t *testing.T) {
+		div, err := document.CreateElementNS(spec.HTMLNamespace, "div")
+		require.NoError(t, err)
+		assert.Equal(t, "DIV", div.TagName())
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, tt := range []struct {
+			Namespace, QualifiedName string
+			Err                      error
+		}{
+			{spec.SVGNamespace, "", spec.ErrInvalidCharacter},
+			{spec.SVGNamespace, "a b", spec.ErrInvalidCharacter},
+			{spec.SVGNamespace, ":a", spec.ErrInvalidCharacter},
+			{spec.SVGNamespace, "a:", spec.ErrInvalidCharacter},
+			{spec.SVGNamespace, "xml:a", spec.ErrNamespace},
+			{spec.SVGNamespace, "xmlns", spec.ErrNamespace},
+			{spec.XMLNSNamespace, "a", spec.ErrNamespace},
+			{"", "", spec.ErrInvalidCharacter},
+			{"", "a", spec.ErrNotSupported},
+			{"", "s:a", spec.ErrNamespace},
+		} {
+			el, err := document.CreateElementNS(tt.Namespace, tt.QualifiedName)
+			assert.ErrorIsf(t, err, tt.Err, "%q %q", tt.Namespace, tt.QualifiedName)
+			assert.Nil(t, el)
+		}
+	})
+}
+
+func TestElement_SetAttributeNS(t *testing.T) {
+	var document *Document
+	use, err := document.CreateElementNS(spec.SVGNamespace, "use")
+	require.NoError(t, err)
+
+	require.NoError(t, use.SetAttributeNS(spec.XLinkNamespace, "xlink:href", "#a"))
+	require.NoError(t, use.SetAttributeNS(spec.XMLNamespace, "xml:lang", "en"))
+	require.NoError(t, use.SetAttributeNS(spec.XMLNSNamespace, "xmlns:xlink", spec.XLinkNamespace))
+	require.NoError(t, use.SetAttributeNS("", "href", "#b"))
+	assert.Equal(t, `<use xlink:href="#a" xml:lang="en" xmlns:xlink="http://www.w3.org/1999/xlink" href="#b"></use>`, use.OuterHTML())
+	assert.Equal(t, []string{"xlink:href", "xml:lang", "xmlns:xlink", "href"}, use.GetAttributeNames())
+	assert.Equal(t, "#a", use.GetAttributeNS(spec.XLinkNamespace, "href"))
+	assert.Equal(t, "#b", use.GetAttributeNS("", "href"))
+	assert.Equal(t, "xlink", use.LookupPrefix(spec.XLinkNamespace))
+	assert.Equal(t, spec.XLinkNamespace, use.LookupNamespaceURI("xlink"))
+
+	assert.ErrorIs(t, use.SetAttributeNS("", "xlink:href", "#c"), spec.ErrNamespace)
+	assert.ErrorIs(t, use.SetAttributeNS(spec.XLinkNamespace, "xml:href", "#c"), spec.ErrNamespace)
+	assert.ErrorIs(t, use.SetAttributeNS("urn:example", "e:x", "#c"), spec.ErrNotSupported)
+}
+
+func TestNode_LookupNamespaceURI(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang='us-en'>
+<head><title></title></head>
+<body><p id='p'>text</p><svg xmlns='http://www.w3.org/2000/svg'><g><!-- c --></g></svg></body>
+</html>`
+	document, p := parseDocument(t, textHTML, "#p")
+	text := p.FirstChild()
+	comment := document.QuerySelector("g").FirstChild()
+
+	assert.Equal(t, spec.HTMLNamespace, document.LookupNamespaceURI(""))
+	assert.Equal(t, spec.HTMLNamespace, text.LookupNamespaceURI(""))
+	assert.Equal(t, spec.SVGNamespace, comment.LookupNamespaceURI(""))
+	assert.Equal(t, spec.XMLNamespace, text.LookupNamespaceURI("xml"))
+	assert.Equal(t, spec.XMLNSNamespace, text.LookupNamespaceURI("xmlns"))
+	assert.Equal(t, "", text.LookupNamespaceURI("unknown"))
+	assert.Equal(t, "", document.Doctype().LookupNamespaceURI(""))
+	assert.Equal(t, "", p.GetAttributeNode("id").LookupPrefix(spec.HTMLNamespace))
+	assert.Equal(t, spec.HTMLNamespace, p.GetAttributeNode("id").LookupNamespaceURI(""))
+}
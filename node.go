@@ -1,15 +1,22 @@
 // Package dom implements the spec interfaces using golang.org/x/net/html
 // nodes. CSS selectors are supported via andybalholm/cascadia.
+//
+// Elements are *Element values, except template, a, area and link elements,
+// which are *HTMLTemplateElement, *HTMLAnchorElement, *HTMLAreaElement and
+// *HTMLLinkElement. Use the spec interfaces in type assertions.
 package dom
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"iter"
 	"slices"
 	"strings"
 
 	"github.com/andybalholm/cascadia"
 	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
 
 	"github.com/typelate/dom/spec"
 )
@@ -36,6 +43,8 @@ func nodeType(nodeType html.NodeType) spec.NodeType {
 		return spec.NodeTypeComment
 	case html.DoctypeNode:
 		return spec.NodeTypeDocumentType
+	case documentFragmentNode:
+		return spec.NodeTypeDocumentFragment
 	default:
 		fallthrough
 	case html.ErrorNode, html.RawNode:
@@ -49,11 +58,17 @@ func NewNode(node *html.Node) spec.Node {
 	}
 	switch node.Type {
 	case html.ElementNode:
-		return &Element{node: node}
+		return newElement(node)
 	case html.TextNode:
 		return &Text{node: node}
+	case html.CommentNode:
+		return &Comment{node: node}
+	case html.DoctypeNode:
+		return &DocumentType{node: node}
 	case html.DocumentNode:
 		return &Document{node: node}
+	case documentFragmentNode:
+		return &DocumentFragment{node: node}
 	default:
 		panic("not supported")
 	}
@@ -65,18 +80,40 @@ func htmlNodeToDomChildNode(node *html.Node) spec.ChildNode {
 	}
 	switch node.Type {
 	case html.ElementNode:
-		return &Element{node: node}
+		return newElement(node)
 	case html.TextNode:
 		return &Text{node: node}
+	case html.CommentNode:
+		return &Comment{node: node}
+	case html.DoctypeNode:
+		return &DocumentType{node: node}
 	default:
 		panic("not supported")
 	}
 }
 
 func htmlNodeToDomElement(node *html.Node) spec.Element {
-	if node == nil {
+	if node == nil || node.Type != html.ElementNode {
 		return nil
 	}
+	return newElement(node)
+}
+
+// newElement wraps an element node, using the HTML element types for the
+// elements that have one.
+func newElement(node *html.Node) spec.Element {
+	if node.Namespace == "" {
+		switch node.DataAtom {
+		case atom.Template:
+			return &HTMLTemplateElement{Element{node: node}}
+		case atom.A:
+			return &HTMLAnchorElement{Element{node: node}}
+		case atom.Area:
+			return &HTMLAreaElement{Element{node: node}}
+		case atom.Link:
+			return &HTMLLinkElement{Element{node: node}}
+		}
+	}
 	return &Element{node: node}
 }
 
@@ -84,31 +121,31 @@ func domNodeToHTMLNode(node spec.Node) *html.Node {
 	switch ot := node.(type) {
 	case *Element:
 		return ot.node
+	case *HTMLTemplateElement:
+		return ot.node
+	case *HTMLAnchorElement:
+		return ot.node
+	case *HTMLAreaElement:
+		return ot.node
+	case *HTMLLinkElement:
+		return ot.node
 	case *Text:
 		return ot.node
+	case *Comment:
+		return ot.node
+	case *DocumentType:
+		return ot.node
 	case *Document:
 		return ot.node
+	case *DocumentFragment:
+		return ot.node
+	case *Attr:
+		return nil
 	default:
 		panic("not implemented")
 	}
 }
 
-func walkNodes(start *html.Node, fn func(node *html.Node) (done bool)) bool {
-	if fn(start) {
-		return true
-	}
-
-	c := start.FirstChild
-	for c != nil {
-		if walkNodes(c, fn) {
-			return true
-		}
-		c = c.NextSibling
-	}
-
-	return false
-}
-
 type firstChildIterator html.Node
 
 func (node *firstChildIterator) Length() int {
@@ -134,13 +171,23 @@ func (node *firstChildIterator) Item(index int) spec.Node {
 	return nil
 }
 
+func (node *firstChildIterator) All() iter.Seq2[int, spec.Node] {
+	return func(yield func(int, spec.Node) bool) {
+		i := 0
+		for c := (*html.Node)(node); c != nil; c = c.NextSibling {
+			if !yield(i, NewNode(c)) {
+				return
+			}
+			i++
+		}
+	}
+}
+
 func isConnected(node *html.Node) bool {
-	p := node.Parent
-	for p != nil {
+	for p := node.Parent; p != nil && !isTemplate(p); p = p.Parent {
 		if p.Type == html.DocumentNode {
 			return true
 		}
-		p = p.Parent
 	}
 	return false
 }
@@ -153,19 +200,21 @@ func ownerDocument(node *html.Node) spec.Document {
 	return &Document{node: n}
 }
 
-func ownerDocumentNode(node *html.Node) *html.Node {
-	p := node.Parent
-	for p != nil {
-		if p.Type == html.DocumentNode {
-			return p
-		}
-		p = p.Parent
+func parentNode(node *html.Node) spec.Node {
+	if isTemplate(node.Parent) {
+		return &DocumentFragment{node: node.Parent}
 	}
-	return nil
+	return NewNode(node.Parent)
 }
-func parentNode(node *html.Node) spec.Node       { return NewNode(node.Parent) }
-func parentElement(node *html.Node) spec.Element { return htmlNodeToDomElement(node.Parent) }
-func hasChildNodes(node *html.Node) bool         { return node.FirstChild != nil }
+
+func parentElement(node *html.Node) spec.Element {
+	if isTemplate(node.Parent) {
+		return nil
+	}
+	return htmlNodeToDomElement(node.Parent)
+}
+
+func hasChildNodes(node *html.Node) bool { return node.FirstChild != nil }
 func childNodes(node *html.Node) spec.NodeList[spec.Node] {
 	return (*firstChildIterator)(node.FirstChild)
 }
@@ -174,12 +223,84 @@ func lastChild(node *html.Node) spec.ChildNode       { return htmlNodeToDomChild
 func previousSibling(node *html.Node) spec.ChildNode { return htmlNodeToDomChildNode(node.PrevSibling) }
 func nextSibling(node *html.Node) spec.ChildNode     { return htmlNodeToDomChildNode(node.NextSibling) }
 
+// ancestors yields the ancestors of node. The contents of a template end at
+// their DocumentFragment.
+func ancestors(node *html.Node) iter.Seq[spec.Node] {
+	return func(yield func(spec.Node) bool) {
+		for n := node; n.Parent != nil; n = n.Parent {
+			if !yield(parentNode(n)) || isTemplate(n.Parent) {
+				return
+			}
+		}
+	}
+}
+
+func followingSiblings(node *html.Node) iter.Seq[spec.ChildNode] {
+	return func(yield func(spec.ChildNode) bool) {
+		for c := node.NextSibling; c != nil; {
+			next := c.NextSibling
+			if !yield(htmlNodeToDomChildNode(c)) {
+				return
+			}
+			c = next
+		}
+	}
+}
+
+func precedingSiblings(node *html.Node) iter.Seq[spec.ChildNode] {
+	return func(yield func(spec.ChildNode) bool) {
+		for c := node.PrevSibling; c != nil; {
+			prev := c.PrevSibling
+			if !yield(htmlNodeToDomChildNode(c)) {
+				return
+			}
+			c = prev
+		}
+	}
+}
+
+func childNodesSeq(node *html.Node) iter.Seq[spec.ChildNode] {
+	return func(yield func(spec.ChildNode) bool) {
+		for c := node.FirstChild; c != nil; {
+			next := c.NextSibling
+			if !yield(htmlNodeToDomChildNode(c)) {
+				return
+			}
+			c = next
+		}
+	}
+}
+
+func childrenSeq(node *html.Node) iter.Seq[spec.Element] {
+	return func(yield func(spec.Element) bool) {
+		for c := node.FirstChild; c != nil; {
+			next := c.NextSibling
+			if c.Type == html.ElementNode && !yield(newElement(c)) {
+				return
+			}
+			c = next
+		}
+	}
+}
+
+func descendantNodes(node *html.Node) iter.Seq[spec.Node] {
+	return func(yield func(spec.Node) bool) {
+		for n := range descendants(node) {
+			if !yield(NewNode(n)) {
+				return
+			}
+		}
+	}
+}
+
 func textContent(node *html.Node) string {
 	var buf bytes.Buffer
 	recursiveTextContent(&buf, node)
 	return buf.String()
 }
 
+// recursiveTextContent writes the data of n and its Text descendants,
+// skipping the contents of templates below n.
 func recursiveTextContent(sw io.StringWriter, n *html.Node) {
 	if n.Type == html.TextNode {
 		_, err := sw.WriteString(n.Data)
@@ -187,14 +308,24 @@ func recursiveTextContent(sw io.StringWriter, n *html.Node) {
 			panic(err)
 		}
 	}
-	c := n.FirstChild
-	for c != nil {
-		recursiveTextContent(sw, c)
-		c = c.NextSibling
+	for c := range descendants(n) {
+		if c.Type != html.TextNode {
+			continue
+		}
+		if _, err := sw.WriteString(c.Data); err != nil {
+			panic(err)
+		}
 	}
 }
 
+// cloneNode clones node and gives the clone the same node document.
 func cloneNode(node *html.Node, deep bool) *html.Node {
+	result := cloneHTMLNode(node, deep)
+	setNodeDocument(result, ownerDocumentNode(node))
+	return result
+}
+
+func cloneHTMLNode(node *html.Node, deep bool) *html.Node {
 	result := &html.Node{
 		Type:      node.Type,
 		Namespace: node.Namespace,
@@ -203,7 +334,7 @@ func cloneNode(node *html.Node, deep bool) *html.Node {
 	}
 	if deep {
 		for c := node.FirstChild; c != nil; c = c.NextSibling {
-			result.AppendChild(cloneNode(c, true))
+			result.AppendChild(cloneHTMLNode(c, true))
 		}
 	}
 	if node.Attr != nil {
@@ -218,91 +349,255 @@ func cloneNode(node *html.Node, deep bool) *html.Node {
 }
 
 func isSameNode(node *html.Node, other spec.Node) bool {
-	if node == nil || other == nil {
+	if _, ok := other.(*DocumentFragment); ok || node == nil || other == nil {
 		return false
 	}
 	n := domNodeToHTMLNode(other)
 	return n != nil && node == n
 }
 
+// isEqualNode is based on https://dom.spec.whatwg.org/#concept-node-equals
+func isEqualNode(node *html.Node, other spec.Node) bool {
+	switch other.(type) {
+	case nil, *Attr, *DocumentFragment:
+		return false
+	}
+	n := domNodeToHTMLNode(other)
+	return n != nil && equalHTMLNodes(node, n)
+}
+
+func equalHTMLNodes(a, b *html.Node) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case html.DoctypeNode:
+		if a.Data != b.Data ||
+			doctypeIdentifier(a, "public") != doctypeIdentifier(b, "public") ||
+			doctypeIdentifier(a, "system") != doctypeIdentifier(b, "system") {
+			return false
+		}
+	case html.ElementNode:
+		if elementNamespaceURI(a) != elementNamespaceURI(b) ||
+			elementPrefix(a) != elementPrefix(b) ||
+			elementLocalName(a) != elementLocalName(b) ||
+			!equalAttributes(a, b) {
+			return false
+		}
+	case html.TextNode, html.CommentNode:
+		if a.Data != b.Data {
+			return false
+		}
+	}
+	return equalChildNodes(a, b)
+}
+
+func equalChildNodes(a, b *html.Node) bool {
+	ac, bc := a.FirstChild, b.FirstChild
+	for ; ac != nil && bc != nil; ac, bc = ac.NextSibling, bc.NextSibling {
+		if !equalHTMLNodes(ac, bc) {
+			return false
+		}
+	}
+	return ac == nil && bc == nil
+}
+
+// equalAttributes reports whether each attribute of a has an equal attribute
+// on b, regardless of order.
+func equalAttributes(a, b *html.Node) bool {
+	if len(a.Attr) != len(b.Attr) {
+		return false
+	}
+	for _, at := range a.Attr {
+		if !slices.ContainsFunc(b.Attr, func(bt html.Attribute) bool {
+			return at.Key == bt.Key && at.Val == bt.Val &&
+				attributeNamespaceURI(a, at) == attributeNamespaceURI(b, bt)
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// getRootNode is based on https://dom.spec.whatwg.org/#concept-tree-root
+func getRootNode(node *html.Node) spec.Node {
+	for node.Parent != nil {
+		if isTemplate(node.Parent) {
+			return &DocumentFragment{node: node.Parent}
+		}
+		node = node.Parent
+	}
+	return NewNode(node)
+}
+
 func contains(node *html.Node, other spec.Node) bool {
 	o := domNodeToHTMLNode(other)
 	if o == nil {
 		return false
 	}
+	if o == node {
+		return true
+	}
+	for n := range descendants(node) {
+		if n == o {
+			return true
+		}
+	}
+	return false
+}
 
-	found := false
-	walkNodes(node, func(n *html.Node) bool {
-		found = n == o
-		return found
-	})
+// insertBefore is based on https://dom.spec.whatwg.org/#concept-node-pre-insert
+func insertBefore(parent *html.Node, node spec.Node, child spec.ChildNode) spec.Node {
+	var c *html.Node
+	if child != nil {
+		c = domNodeToHTMLNode(child)
+	}
+	checkInsert(parent, c, nil, []spec.Node{node})
+	if c != nil && c == domNodeToHTMLNode(node) {
+		c = c.NextSibling
+	}
+	insertHTMLNodes(parent, c, convertNodes([]spec.Node{node}))
+	return node
+}
 
-	return found
+// ensurePreInsertValidity is based on https://dom.spec.whatwg.org/#concept-node-ensure-pre-insertion-validity
+func ensurePreInsertValidity(node spec.Node, parent, child *html.Node) error {
+	return ensureInsertValidity(parent, child, nil, []spec.Node{node})
 }
 
-func insertBefore(parent *html.Node, node, child spec.ChildNode) spec.ChildNode {
-	n := domNodeToHTMLNode(node)
-	c := domNodeToHTMLNode(child)
-	if n.Parent != nil {
-		n.Parent.RemoveChild(n)
+// ensureInsertValidity checks that nodes can be inserted into parent before
+// child or, when replaced is not nil, in place of replaced. Several nodes are
+// checked like the fragment they are converted into. It is based on
+// https://dom.spec.whatwg.org/#concept-node-ensure-pre-insertion-validity and
+// the checks of https://dom.spec.whatwg.org/#concept-node-replace
+func ensureInsertValidity(parent, child, replaced *html.Node, nodes []spec.Node) error {
+	switch parent.Type {
+	case html.DocumentNode, html.ElementNode, documentFragmentNode:
+	default:
+		return fmt.Errorf("%w: %s nodes can not have children", spec.ErrHierarchyRequest, nodeType(parent.Type))
 	}
-	parent.InsertBefore(n, c)
-	return htmlNodeToDomChildNode(n)
+	var inserted []*html.Node
+	for _, node := range nodes {
+		n := domNodeToHTMLNode(node)
+		switch {
+		case n == nil:
+			return fmt.Errorf("%w: %s nodes can not be inserted", spec.ErrHierarchyRequest, node.NodeType())
+		case n.Type == html.DocumentNode:
+			return fmt.Errorf("%w: a Document can not be inserted", spec.ErrHierarchyRequest)
+		case isInclusiveAncestor(n, parent):
+			return fmt.Errorf("%w: a node can not be inserted into itself", spec.ErrHierarchyRequest)
+		}
+		if fragment, ok := node.(*DocumentFragment); ok {
+			inserted = slices.AppendSeq(inserted, fragment.node.ChildNodes())
+		} else {
+			inserted = append(inserted, n)
+		}
+	}
+	if replaced != nil {
+		child = replaced
+	}
+	if child != nil && child.Parent != parent {
+		return fmt.Errorf("%w: the child is not a child of the parent", spec.ErrNotFound)
+	}
+	single := len(nodes) == 1
+	if single {
+		_, fragment := nodes[0].(*DocumentFragment)
+		single = !fragment
+	}
+	elements := 0
+	for _, n := range inserted {
+		switch {
+		case n.Type == html.TextNode && parent.Type == html.DocumentNode,
+			n.Type == html.DoctypeNode && (parent.Type != html.DocumentNode || !single):
+			return fmt.Errorf("%w: a %s can not be a child of a %s", spec.ErrHierarchyRequest, nodeType(n.Type), nodeType(parent.Type))
+		case n.Type == html.ElementNode:
+			elements++
+		}
+	}
+	if parent.Type != html.DocumentNode {
+		return nil
+	}
+	if elements > 1 {
+		return fmt.Errorf("%w: a Document can only have one element child", spec.ErrHierarchyRequest)
+	}
+	if elements == 1 {
+		invalid := documentElement(parent) != nil && documentElement(parent) != replaced ||
+			replaced == nil && child != nil && child.Type == html.DoctypeNode
+		for c := child; c != nil && !invalid; c = c.NextSibling {
+			invalid = c != child && c.Type == html.DoctypeNode
+		}
+		if invalid {
+			return fmt.Errorf("%w: a Document can only have one element child after its doctype", spec.ErrHierarchyRequest)
+		}
+	}
+	if single && inserted[0].Type == html.DoctypeNode {
+		invalid := child == nil && documentElement(parent) != nil
+		for c := parent.FirstChild; c != nil && !invalid; c = c.NextSibling {
+			invalid = c.Type == html.DoctypeNode && c != replaced
+		}
+		for c := child; c != nil && !invalid; c = c.PrevSibling {
+			invalid = c != child && c.Type == html.ElementNode
+		}
+		if invalid {
+			return fmt.Errorf("%w: a Document can only have one doctype before its element", spec.ErrHierarchyRequest)
+		}
+	}
+	return nil
 }
 
-func appendChild(parent *html.Node, node spec.ChildNode) spec.ChildNode {
-	n := domNodeToHTMLNode(node)
-	if n.Parent != nil {
-		n.Parent.RemoveChild(n)
+// checkInsert panics with the error of ensureInsertValidity, for the methods
+// that do not return one.
+func checkInsert(parent, child, replaced *html.Node, nodes []spec.Node) {
+	if err := ensureInsertValidity(parent, child, replaced, nodes); err != nil {
+		panic(err)
 	}
-	parent.AppendChild(n)
-	return htmlNodeToDomChildNode(n)
 }
 
-func replaceChild(parent *html.Node, node, child spec.ChildNode) spec.ChildNode {
-	n := domNodeToHTMLNode(node)
+func appendChild(parent *html.Node, node spec.Node) spec.Node {
+	return insertBefore(parent, node, nil)
+}
+
+// replaceChild is based on https://dom.spec.whatwg.org/#concept-node-replace
+func replaceChild(parent *html.Node, node spec.Node, child spec.ChildNode) spec.ChildNode {
 	c := domNodeToHTMLNode(child)
 	if c.Parent != parent {
-		panic("browser: ReplaceChild called for an attached child node")
-	}
-	if c.PrevSibling != nil {
-		c.PrevSibling.NextSibling = n
+		panic("dom: ReplaceChild called for a non-child node")
 	}
-	if c.NextSibling != nil {
-		c.NextSibling.PrevSibling = n
-	}
-	if parent.FirstChild == c {
-		parent.FirstChild = n
+	checkInsert(parent, nil, c, []spec.Node{node})
+	n := domNodeToHTMLNode(node)
+	next := c.NextSibling
+	if next != nil && next == n {
+		next = next.NextSibling
 	}
-	if parent.LastChild == c {
-		parent.LastChild = n
+	previous := c.PrevSibling
+	if previous != nil && previous == n {
+		previous = previous.PrevSibling
 	}
-	n.PrevSibling = c.PrevSibling
-	n.NextSibling = c.NextSibling
-	n.Parent = c.Parent
-
-	c.PrevSibling = nil
-	c.NextSibling = nil
-	c.Parent = nil
-
-	return htmlNodeToDomChildNode(c)
+	list := convertNodes([]spec.Node{node})
+	unlinkHTMLNode(c)
+	linkHTMLNodes(parent, next, list)
+	queueTreeMutationRecord(parent, list, []*html.Node{c}, previous, next)
+	return child
 }
 
 func removeChild(parent *html.Node, node spec.ChildNode) spec.ChildNode {
 	n := domNodeToHTMLNode(node)
-	parent.RemoveChild(n)
+	if n.Parent != parent {
+		panic("dom: RemoveChild called for a non-child node")
+	}
+	detachHTMLNode(n)
 	return htmlNodeToDomChildNode(n)
 }
 
 func children(parent *html.Node) spec.ElementCollection {
-	return siblingElements{firstChild: parent.FirstChild}
+	return newLiveElements(parent, childElements)
 }
 
 func firstElementChild(node *html.Node) spec.Element {
 	child := node.FirstChild
 	for child != nil {
 		if child.Type == html.ElementNode {
-			return &Element{node: child}
+			return newElement(child)
 		}
 		child = child.NextSibling
 	}
@@ -313,7 +608,7 @@ func lastElementChild(node *html.Node) spec.Element {
 	child := node.LastChild
 	for child != nil {
 		if child.Type == html.ElementNode {
-			return &Element{node: child}
+			return newElement(child)
 		}
 		child = child.PrevSibling
 	}
@@ -334,87 +629,191 @@ func childElementCount(node *html.Node) int {
 	return result
 }
 
-func prependNodes(node *html.Node, nodes []spec.Node) {
-	for i := range nodes {
-		dn := nodes[len(nodes)-1-i]
-		if fragment, ok := dn.(*DocumentFragment); ok {
-			for _, n := range fragment.nodes {
-				prependHTMLNode(node, n)
+// prependNodes is based on https://dom.spec.whatwg.org/#dom-parentnode-prepend
+func prependNodes(parent *html.Node, nodes []spec.Node) {
+	checkInsert(parent, parent.FirstChild, nil, nodes)
+	list := convertNodes(nodes)
+	insertHTMLNodes(parent, parent.FirstChild, list)
+}
+
+// appendNodes is based on https://dom.spec.whatwg.org/#dom-parentnode-append
+func appendNodes(parent *html.Node, nodes ...spec.Node) {
+	checkInsert(parent, nil, nil, nodes)
+	insertHTMLNodes(parent, nil, convertNodes(nodes))
+}
+
+// replaceChildren is based on https://dom.spec.whatwg.org/#dom-parentnode-replacechildren
+func replaceChildren(parent *html.Node, nodes []spec.Node) {
+	checkInsert(parent, nil, nil, nodes)
+	replaceAllHTMLNodes(parent, convertNodes(nodes))
+}
+
+// replaceAllHTMLNodes is based on https://dom.spec.whatwg.org/#concept-node-replace-all
+// nodes must already be detached.
+func replaceAllHTMLNodes(parent *html.Node, nodes []*html.Node) {
+	removed := slices.Collect(parent.ChildNodes())
+	for _, c := range removed {
+		unlinkHTMLNode(c)
+	}
+	linkHTMLNodes(parent, nil, nodes)
+	queueTreeMutationRecord(parent, nodes, removed, nil, nil)
+}
+
+// convertNodes flattens nodes into the list of html nodes to insert and
+// detaches each from its current parent. DocumentFragment arguments are
+// replaced by their children, leaving the fragments empty. It is based on
+// https://dom.spec.whatwg.org/#converting-nodes-into-a-node
+func convertNodes(nodes []spec.Node) []*html.Node {
+	var list []*html.Node
+	for _, node := range nodes {
+		if fragment, ok := node.(*DocumentFragment); ok {
+			children := slices.Collect(fragment.node.ChildNodes())
+			for _, c := range children {
+				unlinkHTMLNode(c)
 			}
+			queueTreeMutationRecord(fragment.node, nil, children, nil, nil)
+			list = append(list, children...)
 			continue
 		}
-		n := domNodeToHTMLNode(dn)
-		prependHTMLNode(node, n)
+		n := domNodeToHTMLNode(node)
+		if n == nil {
+			panic(fmt.Errorf("%w: dom: %s nodes can not be inserted", spec.ErrHierarchyRequest, node.NodeType()))
+		}
+		list = append(list, n)
+	}
+	for _, n := range list {
+		detachHTMLNode(n)
 	}
+	return list
 }
 
-func prependHTMLNode(node *html.Node, n *html.Node) {
-	fc := node.FirstChild
-	if fc != nil {
-		fc.PrevSibling = n
-		n.NextSibling = fc
+// insertHTMLNodes inserts detached nodes into parent before child, which may
+// be nil to append them.
+func insertHTMLNodes(parent, child *html.Node, nodes []*html.Node) {
+	previous := parent.LastChild
+	if child != nil {
+		previous = child.PrevSibling
 	}
-	n.Parent = node
-	node.FirstChild = n
+	linkHTMLNodes(parent, child, nodes)
+	queueTreeMutationRecord(parent, nodes, nil, previous, child)
+}
 
-	if node.LastChild == nil {
-		node.LastChild = n
+// linkHTMLNodes is insertHTMLNodes without the mutation record.
+func linkHTMLNodes(parent, child *html.Node, nodes []*html.Node) {
+	preInsert(parent, child, len(nodes))
+	for _, n := range nodes {
+		parent.InsertBefore(n, child)
 	}
+	treeMutated(parent)
 }
 
-func appendNodes(parent *html.Node, nodes ...spec.Node) {
+func includesHTMLNode(nodes []spec.Node, n *html.Node) bool {
 	for _, node := range nodes {
 		if fragment, ok := node.(*DocumentFragment); ok {
-			for _, n := range fragment.nodes {
-				parent.AppendChild(n)
+			if n.Parent == fragment.node {
+				return true
 			}
 			continue
 		}
-		n := domNodeToHTMLNode(node)
-		parent.AppendChild(n)
+		if domNodeToHTMLNode(node) == n {
+			return true
+		}
 	}
+	return false
 }
 
-func replaceChildren(parent *html.Node, nodes []spec.Node) {
-	clearChildren(parent)
-	for _, node := range nodes {
-		n := domNodeToHTMLNode(node)
-		parent.AppendChild(n)
+// before is based on https://dom.spec.whatwg.org/#dom-childnode-before
+func before(node *html.Node, nodes []spec.Node) {
+	parent := node.Parent
+	if parent == nil {
+		return
+	}
+	viablePreviousSibling := node.PrevSibling
+	for viablePreviousSibling != nil && includesHTMLNode(nodes, viablePreviousSibling) {
+		viablePreviousSibling = viablePreviousSibling.PrevSibling
 	}
+	reference := parent.FirstChild
+	if viablePreviousSibling != nil {
+		reference = viablePreviousSibling.NextSibling
+	}
+	for reference != nil && includesHTMLNode(nodes, reference) {
+		reference = reference.NextSibling
+	}
+	checkInsert(parent, reference, nil, nodes)
+	list := convertNodes(nodes)
+	child := parent.FirstChild
+	if viablePreviousSibling != nil {
+		child = viablePreviousSibling.NextSibling
+	}
+	insertHTMLNodes(parent, child, list)
 }
 
-func clearChildren(node *html.Node) {
-	if fc := node.FirstChild; fc != nil {
-		fc.Parent = nil
+// after is based on https://dom.spec.whatwg.org/#dom-childnode-after
+func after(node *html.Node, nodes []spec.Node) {
+	parent := node.Parent
+	if parent == nil {
+		return
 	}
-	if lc := node.LastChild; lc != nil {
-		lc.Parent = nil
+	viableNextSibling := node.NextSibling
+	for viableNextSibling != nil && includesHTMLNode(nodes, viableNextSibling) {
+		viableNextSibling = viableNextSibling.NextSibling
 	}
-	node.FirstChild = nil
-	node.LastChild = nil
+	checkInsert(parent, viableNextSibling, nil, nodes)
+	insertHTMLNodes(parent, viableNextSibling, convertNodes(nodes))
 }
 
-func getElementsByTagName(node *html.Node, name string) elementList {
-	name = strings.ToUpper(name)
-	var list elementList
-	walkNodes(node, func(n *html.Node) bool {
-		if strings.ToUpper(n.Data) == name {
-			list = append(list, n)
+// replaceWith is based on https://dom.spec.whatwg.org/#dom-childnode-replacewith
+func replaceWith(node *html.Node, nodes []spec.Node) {
+	parent := node.Parent
+	if parent == nil {
+		return
+	}
+	viableNextSibling := node.NextSibling
+	for viableNextSibling != nil && includesHTMLNode(nodes, viableNextSibling) {
+		viableNextSibling = viableNextSibling.NextSibling
+	}
+	if includesHTMLNode(nodes, node) {
+		checkInsert(parent, viableNextSibling, nil, nodes)
+	} else {
+		checkInsert(parent, nil, node, nodes)
+	}
+	list := convertNodes(nodes)
+	if node.Parent == parent {
+		viableNextSibling = node.NextSibling
+		detachHTMLNode(node)
+	}
+	insertHTMLNodes(parent, viableNextSibling, list)
+}
+
+// remove is based on https://dom.spec.whatwg.org/#dom-childnode-remove
+func remove(node *html.Node) { detachHTMLNode(node) }
+
+// filterDescendants returns a live collection of the descendant elements of
+// node that match in tree order.
+func filterDescendants(node *html.Node, match func(*html.Node) bool) *liveElements {
+	return newLiveElements(node, func(root *html.Node) []*html.Node {
+		var nodes []*html.Node
+		for n := range descendants(root) {
+			if n.Type == html.ElementNode && match(n) {
+				nodes = append(nodes, n)
+			}
 		}
-		return false
+		return nodes
 	})
-	return list
 }
 
-func getElementsByClassName(node *html.Node, name string) elementList {
-	var list elementList
-	walkNodes(node, func(n *html.Node) bool {
-		if hasClasses(getAttribute(n, "class"), name) {
-			list = append(list, n)
-		}
-		return false
+// getElementsByTagName is based on https://dom.spec.whatwg.org/#concept-getelementsbytagname
+func getElementsByTagName(node *html.Node, qualifiedName string) *liveElements {
+	lower := strings.ToLower(qualifiedName)
+	return filterDescendants(node, func(n *html.Node) bool {
+		return qualifiedName == "*" || n.Namespace == "" && n.Data == lower || n.Namespace != "" && n.Data == qualifiedName
+	})
+}
+
+func getElementsByClassName(node *html.Node, name string) *liveElements {
+	return filterDescendants(node, func(n *html.Node) bool {
+		return hasClasses(getAttribute(n, "class"), name)
 	})
-	return list
 }
 
 func hasClasses(elementClassesStr, classesStr string) bool {
@@ -433,31 +832,54 @@ func hasClasses(elementClassesStr, classesStr string) bool {
 	return len(set) == 0
 }
 
-func querySelector(node *html.Node, query string, includeParent bool) spec.Element {
-	q := cascadia.MustCompile(query)
-	if includeParent && q.Match(node) {
-		return &Element{node: node}
+func querySelector(node *html.Node, query string) (spec.Element, error) {
+	m, err := compileSelector(query)
+	if err != nil {
+		return nil, err
 	}
-	if result := cascadia.Query(node, q); result != nil {
-		return &Element{node: result}
+	return querySelectorMatcher(node, m), nil
+}
+
+func querySelectorAll(node *html.Node, query string) (nodeListHTMLElements, error) {
+	m, err := compileSelector(query)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return querySelectorAllMatcher(node, m), nil
 }
 
-func querySelectorAll(node *html.Node, query string, includeParent bool) nodeListHTMLElements {
-	m := cascadia.MustCompile(query)
-	var results []*html.Node
-	if includeParent && m.Match(node) {
-		results = slices.Insert(results, 0, node)
+func querySelectorEach(node *html.Node, query string) (iter.Seq[spec.Element], error) {
+	m, err := compileSelector(query)
+	if err != nil {
+		return func(func(spec.Element) bool) {}, err
 	}
+	return querySelectorEachMatcher(node, m), nil
+}
+
+func querySelectorMatcher(node *html.Node, m cascadia.Matcher) spec.Element {
+	var result spec.Element
+	querySelectorSequence(node, m, func(el spec.Element) bool {
+		result = el
+		return false
+	})
+	return result
+}
+
+func querySelectorAllMatcher(node *html.Node, m cascadia.Matcher) nodeListHTMLElements {
+	var results nodeListHTMLElements
 	querySelectorSequence(node, m, func(element spec.Element) bool {
-		el := element.(*Element)
-		results = append(results, el.node)
+		results = append(results, domNodeToHTMLNode(element))
 		return true
 	})
 	return results
 }
 
+func querySelectorEachMatcher(node *html.Node, m cascadia.Matcher) iter.Seq[spec.Element] {
+	return func(yield func(spec.Element) bool) {
+		querySelectorSequence(node, m, yield)
+	}
+}
+
 var _ spec.NodeList[spec.Element] = nodeListHTMLElements(nil)
 
 type nodeListHTMLElements []*html.Node
@@ -465,24 +887,45 @@ type nodeListHTMLElements []*html.Node
 func (n nodeListHTMLElements) Length() int { return len(n) }
 
 func (n nodeListHTMLElements) Item(i int) spec.Element {
-	return &Element{
-		node: n[i],
+	return newElement(n[i])
+}
+
+func (n nodeListHTMLElements) All() iter.Seq2[int, spec.Element] {
+	return func(yield func(int, spec.Element) bool) {
+		for i, el := range n {
+			if !yield(i, newElement(el)) {
+				return
+			}
+		}
+	}
+}
+
+func closest(node *html.Node, selector string) (spec.Element, error) {
+	m, err := compileSelector(selector)
+	if err != nil {
+		return nil, err
 	}
+	return closestMatcher(node, m), nil
 }
 
-func closest(node *html.Node, selector string) spec.Element {
-	s := cascadia.MustCompile(selector)
+func closestMatcher(node *html.Node, m cascadia.Matcher) spec.Element {
 	for p := node; p != nil; p = p.Parent {
-		if s.Match(p) {
+		if m.Match(p) {
 			return htmlNodeToDomElement(p)
 		}
+		if isTemplate(p.Parent) {
+			break
+		}
 	}
 	return nil
 }
 
-func matches(node *html.Node, selector string) bool {
-	s := cascadia.MustCompile(selector)
-	return s.Match(node)
+func matches(node *html.Node, selector string) (bool, error) {
+	m, err := compileSelector(selector)
+	if err != nil {
+		return false, err
+	}
+	return m.Match(node), nil
 }
 
 func isNamed(node *html.Node, name string) bool {
@@ -494,24 +937,28 @@ func isNamed(node *html.Node, name string) bool {
 	return (id != "" && id == name) || (nm != "" && nm == name)
 }
 
+func hasAttribute(node *html.Node, name string) bool { return attributeIndex(node, name) >= 0 }
+
 func getAttribute(node *html.Node, name string) string {
-	name = strings.ToLower(name)
-	for _, att := range node.Attr {
-		if att.Key == name {
-			return att.Val
-		}
+	if i := attributeIndex(node, name); i >= 0 {
+		return node.Attr[i].Val
 	}
 	return ""
 }
 
+func setAttribute(node *html.Node, name, value string) {
+	if i := attributeIndex(node, name); i >= 0 {
+		changeAttribute(node, i, value)
+		return
+	}
+	appendAttribute(node, html.Attribute{
+		Key: attributeNameCase(node, name), Val: value,
+	})
+}
+
 func querySelectorSequence(n *html.Node, m cascadia.Matcher, yield func(spec.Element) bool) bool {
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		if m.Match(c) {
-			if !yield(&Element{node: c}) {
-				return false
-			}
-		}
-		if !querySelectorSequence(c, m, yield) {
+	for c := range descendants(n) {
+		if m.Match(c) && !yield(newElement(c)) {
 			return false
 		}
 	}
@@ -519,29 +966,61 @@ func querySelectorSequence(n *html.Node, m cascadia.Matcher, yield func(spec.Ele
 }
 
 // compareDocumentPosition is based on https://dom.spec.whatwg.org/#dom-node-comparedocumentposition
-// it does not support attributes
 func compareDocumentPosition(this *html.Node, other spec.Node) spec.DocumentPosition {
-	node1 := domNodeToHTMLNode(other)
-	node2 := this
-	if node1 == node2 {
+	return compareNodeDocumentPosition(this, nil, other)
+}
+
+// compareNodeDocumentPosition compares other to this. When the receiver is an
+// attribute, this is its element and thisAttr is the attribute.
+func compareNodeDocumentPosition(this *html.Node, thisAttr *Attr, other spec.Node) spec.DocumentPosition {
+	node1, node2 := domNodeToHTMLNode(other), this
+	attr1, attr2 := (*Attr)(nil), thisAttr
+	if a, ok := other.(*Attr); ok {
+		attr1 = a
+		node1 = a.ownerNode()
+	}
+	if attr2 != nil && attr2.IsSameNode(attr1) || attr1 == nil && attr2 == nil && node1 == node2 {
 		return 0
 	}
-	// attribute nodes not handled
+	if attr1 != nil && attr2 != nil && node1 != nil && node1 == node2 {
+		for _, att := range node2.Attr {
+			if sameAttribute(att, attr1.attr) {
+				return spec.DocumentPositionImplementationSpecific | spec.DocumentPositionPreceding
+			}
+			if sameAttribute(att, attr2.attr) {
+				return spec.DocumentPositionImplementationSpecific | spec.DocumentPositionFollowing
+			}
+		}
+	}
+	if node1 == nil || node2 == nil {
+		return spec.DocumentPositionDisconnected | spec.DocumentPositionImplementationSpecific
+	}
 	owner := ownerDocumentNode(node2)
 	sameRoot := ownerDocumentNode(node1) == owner
 	bothConnected := isConnected(node1) && isConnected(node2)
-	if node1 == nil || node2 == nil || !bothConnected || !sameRoot {
+	if !bothConnected || !sameRoot {
 		// random consistent value for preceding or following is not handled
 		return spec.DocumentPositionDisconnected | spec.DocumentPositionImplementationSpecific
 	}
-	for ancestor := range node2.Ancestors() {
-		if ancestor == node1 {
+	if node1 == node2 {
+		// one of the nodes is an attribute of the other
+		if attr2 != nil {
 			return spec.DocumentPositionContains | spec.DocumentPositionPreceding
 		}
+		return spec.DocumentPositionContainedBy | spec.DocumentPositionFollowing
 	}
-	for ancestor := range node1.Ancestors() {
-		if ancestor == node2 {
-			return spec.DocumentPositionContainedBy | spec.DocumentPositionFollowing
+	if attr1 == nil {
+		for ancestor := range node2.Ancestors() {
+			if ancestor == node1 {
+				return spec.DocumentPositionContains | spec.DocumentPositionPreceding
+			}
+		}
+	}
+	if attr2 == nil {
+		for ancestor := range node1.Ancestors() {
+			if ancestor == node2 {
+				return spec.DocumentPositionContainedBy | spec.DocumentPositionFollowing
+			}
 		}
 	}
 	for descendant := range owner.Descendants() {
@@ -554,38 +1033,3 @@ func compareDocumentPosition(this *html.Node, other spec.Node) spec.DocumentPosi
 	}
 	return spec.DocumentPositionFollowing
 }
-
-func compareDocumentFragmentPosition(this []*html.Node, other spec.Node) spec.DocumentPosition {
-	node1 := domNodeToHTMLNode(other)
-	if node1 == nil {
-		return spec.DocumentPositionDisconnected | spec.DocumentPositionImplementationSpecific
-	}
-
-	var closestPosition spec.DocumentPosition
-	foundAny := false
-
-	for _, node2 := range this {
-		if !isConnected(node2) || ownerDocumentNode(node1) != ownerDocumentNode(node2) {
-			continue
-		}
-		foundAny = true
-
-		pos := compareDocumentPosition(node2, other)
-
-		// Priority ordering: contains > preceding > following
-		if pos&spec.DocumentPositionContains != 0 {
-			return spec.DocumentPositionContains
-		}
-		if pos&spec.DocumentPositionPreceding != 0 {
-			closestPosition = spec.DocumentPositionPreceding
-		} else if pos&spec.DocumentPositionFollowing != 0 && closestPosition != spec.DocumentPositionPreceding {
-			closestPosition = spec.DocumentPositionFollowing
-		}
-	}
-
-	if !foundAny {
-		return spec.DocumentPositionDisconnected | spec.DocumentPositionImplementationSpecific
-	}
-
-	return closestPosition
-}
@@ -31,7 +31,7 @@ func Test_nodeType(t *testing.T) {
 	}
 }
 
-func parseDocument(t *testing.T, document, selector string) (*Document, *Element) {
+func parseDocument(t testing.TB, document, selector string) (*Document, *Element) {
 	t.Helper()
 	parsedDocument, err := html.Parse(strings.NewReader(document))
 	require.NoError(t, err)
@@ -0,0 +1,204 @@
+package dom
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"weak"
+
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+// weakMap associates values with nodes without keeping the nodes alive.
+// Entries are deleted once their node is garbage collected.
+type weakMap[V any] struct {
+	mu sync.Mutex
+	m  map[weak.Pointer[html.Node]]V
+}
+
+func (w *weakMap[V]) load(node *html.Node) (V, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	v, ok := w.m[weak.Make(node)]
+	return v, ok
+}
+
+func (w *weakMap[V]) store(node *html.Node, value V) {
+	key := weak.Make(node)
+	w.mu.Lock()
+	if w.m == nil {
+		w.m = make(map[weak.Pointer[html.Node]]V)
+	}
+	_, exists := w.m[key]
+	w.m[key] = value
+	w.mu.Unlock()
+	if !exists {
+		runtime.AddCleanup(node, w.delete, key)
+	}
+}
+
+// loadOrStore returns the value for node, storing the result of newValue
+// first if there is none.
+func (w *weakMap[V]) loadOrStore(node *html.Node, newValue func() V) V {
+	key := weak.Make(node)
+	w.mu.Lock()
+	if w.m == nil {
+		w.m = make(map[weak.Pointer[html.Node]]V)
+	}
+	v, exists := w.m[key]
+	if !exists {
+		v = newValue()
+		w.m[key] = v
+	}
+	w.mu.Unlock()
+	if !exists {
+		runtime.AddCleanup(node, w.delete, key)
+	}
+	return v
+}
+
+func (w *weakMap[V]) delete(key weak.Pointer[html.Node]) {
+	w.mu.Lock()
+	delete(w.m, key)
+	w.mu.Unlock()
+}
+
+// nodeDocuments records the node document of nodes created, cloned, adopted,
+// or removed through this package. See
+// https://dom.spec.whatwg.org/#concept-node-document
+//
+// html.Node has no field for it, so it is kept on the side. Documents are
+// held weakly too, since a document usually (indirectly) references the
+// nodes it owns.
+var nodeDocuments weakMap[weak.Pointer[html.Node]]
+
+func setNodeDocument(node, document *html.Node) {
+	if node == nil || document == nil || node.Type == html.DocumentNode {
+		return
+	}
+	nodeDocuments.store(node, weak.Make(document))
+}
+
+func recordedNodeDocument(node *html.Node) *html.Node {
+	document, ok := nodeDocuments.load(node)
+	if !ok {
+		return nil
+	}
+	return document.Value()
+}
+
+// ownerDocumentNode returns the node document of node or nil if it is not
+// known. Connected nodes belong to the document at their root and template
+// contents to the inert document of the template's node document. Otherwise
+// the outermost recorded inclusive ancestor wins, because inserting a node
+// adopts it into the node document of its new parent.
+//
+// The ancestors are walked without the lock of nodeDocuments, and the root,
+// which is recorded unless it was made outside this package, is looked up
+// first, so removing nodes does not contend on the lock.
+func ownerDocumentNode(node *html.Node) *html.Node {
+	if node.Type == html.DocumentNode {
+		return nil
+	}
+	root := node
+	for ; root.Parent != nil; root = root.Parent {
+		if isTemplate(root.Parent) {
+			return templateContentsOwner(ownerDocumentNode(root.Parent))
+		}
+	}
+	if root.Type == html.DocumentNode {
+		return root
+	}
+	if document := recordedNodeDocument(root); document != nil || root == node {
+		return document
+	}
+	return outermostRecordedDocument(node.Parent)
+}
+
+// outermostRecordedDocument returns the document recorded for the outermost
+// inclusive ancestor of node that has one.
+func outermostRecordedDocument(node *html.Node) *html.Node {
+	nodeDocuments.mu.Lock()
+	defer nodeDocuments.mu.Unlock()
+	var document weak.Pointer[html.Node]
+	for n := node; n != nil; n = n.Parent {
+		if d, ok := nodeDocuments.m[weak.Make(n)]; ok {
+			document = d
+		}
+	}
+	return document.Value()
+}
+
+// detachHTMLNode removes node from its parent, recording its node document
+// so it is kept after the node is disconnected.
+func detachHTMLNode(node *html.Node) {
+	parent, previous, next := node.Parent, node.PrevSibling, node.NextSibling
+	if parent == nil {
+		return
+	}
+	unlinkHTMLNode(node)
+	queueTreeMutationRecord(parent, nil, []*html.Node{node}, previous, next)
+}
+
+// unlinkHTMLNode is detachHTMLNode without the mutation record, for the
+// algorithms that queue one record for several changes.
+func unlinkHTMLNode(node *html.Node) {
+	parent := node.Parent
+	if parent == nil {
+		return
+	}
+	if document := ownerDocumentNode(node); document != recordedNodeDocument(node) {
+		setNodeDocument(node, document)
+	}
+	preRemove(node)
+	parent.RemoveChild(node)
+	treeMutated(parent)
+	addTransientObservers(parent, node)
+}
+
+// own sets the node document of node to d. It does nothing for a nil
+// Document, so the Create methods keep working on a zero value.
+func (d *Document) own(node *html.Node) *html.Node {
+	if d != nil {
+		setNodeDocument(node, d.node)
+	}
+	return node
+}
+
+// ownAttr sets the node document of the detached attribute a to d, like own.
+func (d *Document) ownAttr(a *Attr) *Attr {
+	if d != nil {
+		a.document = d.node
+	}
+	return a
+}
+
+// importNode is based on https://dom.spec.whatwg.org/#dom-document-importnode
+func importNode(d *Document, node spec.Node, deep bool) (spec.Node, error) {
+	switch n := node.(type) {
+	case *Document:
+		return nil, fmt.Errorf("%w: a document can not be imported", spec.ErrNotSupported)
+	case *Attr:
+		return d.ownAttr(n.CloneNode(deep).(*Attr)), nil
+	}
+	return NewNode(d.own(cloneHTMLNode(domNodeToHTMLNode(node), deep))), nil
+}
+
+// adoptNode is based on https://dom.spec.whatwg.org/#dom-document-adoptnode
+func adoptNode(d *Document, node spec.Node) (spec.Node, error) {
+	switch n := node.(type) {
+	case *Document:
+		return nil, fmt.Errorf("%w: a document can not be adopted", spec.ErrNotSupported)
+	case *Attr:
+		if i := n.index(); i >= 0 {
+			removeAttributeIndex(n.owner, i)
+		}
+		return d.ownAttr(n), nil
+	}
+	n := domNodeToHTMLNode(node)
+	detachHTMLNode(n)
+	d.own(n)
+	return node, nil
+}
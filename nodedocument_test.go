@@ -0,0 +1,241 @@
+package dom
+
+import (
+	"runtime"
+	"testing"
+	"time"
+	"weak"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+func TestDocument_CreateElement_OwnerDocument(t *testing.T) {
+	// language=html
+	document, _ := parseDocument(t, `<!DOCTYPE html><html><head></head><body></body></html>`, "")
+
+	div := document.CreateElement("div")
+	text := document.CreateTextNode("peach")
+	comment := document.CreateComment("pear")
+	doctype, err := document.Implementation().CreateDocumentType("html", "", "")
+	require.NoError(t, err)
+	for _, node := range []spec.ChildNode{div, text, comment, doctype} {
+		assert.False(t, node.IsConnected())
+		require.NotNil(t, node.OwnerDocument())
+		assert.True(t, node.OwnerDocument().IsSameNode(document))
+	}
+
+	t.Run("descendant", func(t *testing.T) {
+		div.Append(text)
+		assert.True(t, text.OwnerDocument().IsSameNode(document))
+	})
+	t.Run("clone", func(t *testing.T) {
+		clone := div.CloneNode(true).(spec.Element)
+		assert.True(t, clone.OwnerDocument().IsSameNode(document))
+		assert.True(t, clone.FirstChild().OwnerDocument().IsSameNode(document))
+		assert.True(t, text.CloneNode(false).(spec.Text).OwnerDocument().IsSameNode(document))
+		assert.True(t, comment.CloneNode(false).(spec.Comment).OwnerDocument().IsSameNode(document))
+	})
+	t.Run("split", func(t *testing.T) {
+		split, err := document.CreateTextNode("apple").SplitText(2)
+		require.NoError(t, err)
+		require.NotNil(t, split.OwnerDocument())
+		assert.True(t, split.OwnerDocument().IsSameNode(document))
+	})
+	t.Run("attribute", func(t *testing.T) {
+		attr := document.CreateAttribute("title")
+		require.NotNil(t, attr.OwnerDocument())
+		assert.True(t, attr.OwnerDocument().IsSameNode(document))
+		assert.True(t, attr.CloneNode(false).(spec.Attr).OwnerDocument().IsSameNode(document))
+
+		div.SetAttribute("lang", "en")
+		lang := div.GetAttributeNode("lang")
+		assert.True(t, lang.OwnerDocument().IsSameNode(document))
+		div.RemoveAttribute("lang")
+		assert.True(t, lang.OwnerDocument().IsSameNode(document), "removed attributes keep their document")
+	})
+	t.Run("removed", func(t *testing.T) {
+		p := document.Body().AppendChild(document.CreateElement("p")).(spec.Element)
+		p.Remove()
+		assert.True(t, p.OwnerDocument().IsSameNode(document))
+
+		parsed := document.QuerySelector("head")
+		removed := document.DocumentElement().RemoveChild(parsed)
+		assert.False(t, removed.IsConnected())
+		assert.True(t, removed.OwnerDocument().IsSameNode(document))
+	})
+	t.Run("zero value document", func(t *testing.T) {
+		var document *Document
+		assert.Nil(t, document.CreateElement("div").OwnerDocument())
+	})
+}
+
+func TestDocument_OwnerDocument_moved(t *testing.T) {
+	// language=html
+	a, aBody := parseDocument(t, `<!DOCTYPE html><html><head></head><body><p id="x"><em>Hello</em></p></body></html>`, "body")
+	// language=html
+	b, bBody := parseDocument(t, `<!DOCTYPE html><html><head></head><body></body></html>`, "body")
+
+	p := aBody.FirstElementChild()
+	em := p.FirstElementChild()
+	bBody.Append(p)
+	assert.True(t, p.OwnerDocument().IsSameNode(b))
+	assert.True(t, em.OwnerDocument().IsSameNode(b))
+	assert.Nil(t, a.GetElementByID("x"))
+
+	p.Remove()
+	assert.True(t, em.OwnerDocument().IsSameNode(b))
+
+	detached := a.CreateElement("div")
+	detached.Append(p)
+	assert.True(t, p.OwnerDocument().IsSameNode(a), "insertion adopts into the parent's document")
+	assert.True(t, em.OwnerDocument().IsSameNode(a))
+}
+
+func TestDocument_ImportNode(t *testing.T) {
+	// language=html
+	a, p := parseDocument(t, `<!DOCTYPE html><html><head></head><body><p class="x">Hello, <em>world</em>!</p></body></html>`, "p")
+	// language=html
+	b, _ := parseDocument(t, `<!DOCTYPE html><html><head></head><body></body></html>`, "")
+
+	t.Run("deep", func(t *testing.T) {
+		imported, err := b.ImportNode(p, true)
+		require.NoError(t, err)
+		el := imported.(spec.Element)
+		assert.True(t, el.IsEqualNode(p))
+		assert.False(t, el.IsSameNode(p))
+		assert.True(t, el.OwnerDocument().IsSameNode(b))
+		assert.True(t, el.FirstElementChild().OwnerDocument().IsSameNode(b))
+		assert.True(t, p.OwnerDocument().IsSameNode(a), "the original is not changed")
+	})
+	t.Run("shallow", func(t *testing.T) {
+		imported, err := b.ImportNode(p, false)
+		require.NoError(t, err)
+		el := imported.(spec.Element)
+		assert.Equal(t, "x", el.GetAttribute("class"))
+		assert.False(t, el.HasChildNodes())
+	})
+	t.Run("text", func(t *testing.T) {
+		imported, err := b.ImportNode(p.FirstChild(), false)
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, ", imported.(spec.Text).Data())
+		assert.True(t, imported.(spec.Text).OwnerDocument().IsSameNode(b))
+	})
+	t.Run("attribute", func(t *testing.T) {
+		imported, err := b.ImportNode(p.GetAttributeNode("class"), false)
+		require.NoError(t, err)
+		attr := imported.(spec.Attr)
+		assert.Equal(t, "x", attr.Value())
+		assert.Nil(t, attr.OwnerElement())
+		assert.True(t, attr.OwnerDocument().IsSameNode(b))
+		assert.True(t, p.GetAttributeNode("class").OwnerDocument().IsSameNode(a))
+	})
+	t.Run("fragment", func(t *testing.T) {
+		fragment := NewDocumentFragment([]*html.Node{cloneHTMLNode(p.node, true)})
+		imported, err := b.ImportNode(fragment, true)
+		require.NoError(t, err)
+		assert.True(t, imported.(*DocumentFragment).FirstElementChild().OwnerDocument().IsSameNode(b))
+	})
+	t.Run("document", func(t *testing.T) {
+		imported, err := b.ImportNode(a, true)
+		require.ErrorIs(t, err, spec.ErrNotSupported)
+		assert.Nil(t, imported)
+	})
+}
+
+func TestDocument_AdoptNode(t *testing.T) {
+	// language=html
+	a, p := parseDocument(t, `<!DOCTYPE html><html><head></head><body><p>Hello, <em>world</em>!</p></body></html>`, "p")
+	// language=html
+	b, _ := parseDocument(t, `<!DOCTYPE html><html><head></head><body></body></html>`, "")
+
+	adopted, err := b.AdoptNode(p)
+	require.NoError(t, err)
+	assert.True(t, adopted.IsSameNode(p))
+	assert.Nil(t, p.ParentNode())
+	assert.Nil(t, a.QuerySelector("p"))
+	assert.True(t, p.OwnerDocument().IsSameNode(b))
+	assert.True(t, p.FirstElementChild().OwnerDocument().IsSameNode(b))
+
+	t.Run("attribute", func(t *testing.T) {
+		// language=html
+		c, div := parseDocument(t, `<!DOCTYPE html><html><head></head><body><div title="x"></div></body></html>`, "div")
+		attr := div.GetAttributeNode("title")
+		_, err := b.AdoptNode(attr)
+		require.NoError(t, err)
+		assert.Nil(t, attr.OwnerElement())
+		assert.False(t, div.HasAttribute("title"))
+		assert.True(t, attr.OwnerDocument().IsSameNode(b))
+		assert.False(t, attr.OwnerDocument().IsSameNode(c))
+	})
+
+	_, err = b.AdoptNode(a)
+	assert.ErrorIs(t, err, spec.ErrNotSupported)
+}
+
+func Test_nodeDocuments_cleanup(t *testing.T) {
+	// language=html
+	document, _ := parseDocument(t, `<!DOCTYPE html><html><head></head><body></body></html>`, "")
+	key := weak.Make(document.CreateElement("div").(*Element).node)
+	require.NotNil(t, recordedNodeDocument(key.Value()))
+
+	assert.Eventually(t, func() bool {
+		runtime.GC()
+		nodeDocuments.mu.Lock()
+		defer nodeDocuments.mu.Unlock()
+		_, ok := nodeDocuments.m[key]
+		return !ok
+	}, time.Second, 10*time.Millisecond)
+}
+
+func BenchmarkDetach(b *testing.B) {
+	// nest returns the innermost of depth nested div elements below parent.
+	nest := func(document *Document, parent spec.Element, depth int) spec.Element {
+		for range depth {
+			div := document.CreateElement("div")
+			parent.AppendChild(div)
+			parent = div
+		}
+		return parent
+	}
+	items := func(document *Document, n int) []spec.Node {
+		nodes := make([]spec.Node, n)
+		for i := range nodes {
+			nodes[i] = document.CreateElement("li")
+		}
+		return nodes
+	}
+
+	b.Run("ReplaceChildren", func(b *testing.B) {
+		document, body := parseDocument(b, `<!DOCTYPE html><html><head></head><body></body></html>`, "body")
+		ul := nest(document, body, 20)
+		nodes := items(document, 100)
+		for b.Loop() {
+			ul.Append(nodes...)
+			ul.ReplaceChildren()
+		}
+	})
+	b.Run("disconnected", func(b *testing.B) {
+		document, _ := parseDocument(b, `<!DOCTYPE html><html><head></head><body></body></html>`, "")
+		ul := nest(document, document.CreateElement("div"), 20)
+		nodes := items(document, 100)
+		for b.Loop() {
+			ul.Append(nodes...)
+			ul.ReplaceChildren()
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			document, body := parseDocument(b, `<!DOCTYPE html><html><head></head><body></body></html>`, "body")
+			ul := nest(document, body, 20)
+			nodes := items(document, 100)
+			for pb.Next() {
+				ul.Append(nodes...)
+				ul.ReplaceChildren()
+			}
+		})
+	})
+}
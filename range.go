@@ -0,0 +1,771 @@
+package dom
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+func (d *Document) CreateRange() spec.Range {
+	return newRange(boundaryPoint{node: d.node}, boundaryPoint{node: d.node})
+}
+
+// StaticRange is based on https://dom.spec.whatwg.org/#interface-staticrange
+type StaticRange struct {
+	startContainer, endContainer spec.Node
+	startOffset, endOffset       int
+}
+
+// NewStaticRange is based on https://dom.spec.whatwg.org/#dom-staticrange-staticrange
+// It returns an error wrapping ErrInvalidNodeType if a container is nil, a
+// DocumentType or an Attr. The offsets are not checked.
+func NewStaticRange(init spec.StaticRangeInit) (*StaticRange, error) {
+	for _, container := range []spec.Node{init.StartContainer, init.EndContainer} {
+		switch container.(type) {
+		case nil, *DocumentType, *Attr:
+			return nil, fmt.Errorf("%w: %T can not contain a boundary point", spec.ErrInvalidNodeType, container)
+		}
+	}
+	return &StaticRange{
+		startContainer: init.StartContainer,
+		startOffset:    init.StartOffset,
+		endContainer:   init.EndContainer,
+		endOffset:      init.EndOffset,
+	}, nil
+}
+
+func (s *StaticRange) StartContainer() spec.Node { return s.startContainer }
+func (s *StaticRange) StartOffset() int          { return s.startOffset }
+func (s *StaticRange) EndContainer() spec.Node   { return s.endContainer }
+func (s *StaticRange) EndOffset() int            { return s.endOffset }
+
+func (s *StaticRange) Collapsed() bool {
+	return s.startContainer.IsSameNode(s.endContainer) && s.startOffset == s.endOffset
+}
+
+// Range is based on https://dom.spec.whatwg.org/#interface-range
+//
+// The boundary points are updated by the mutation algorithms in live.go.
+type Range struct {
+	mu         sync.Mutex
+	start, end boundaryPoint
+}
+
+func newRange(start, end boundaryPoint) *Range {
+	r := &Range{start: start, end: end}
+	liveRanges.add(r)
+	return r
+}
+
+func (r *Range) bounds() (start, end boundaryPoint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.start, r.end
+}
+
+func (r *Range) setBounds(start, end boundaryPoint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.start, r.end = start, end
+}
+
+// update calls fn with each boundary point when r is in the tree with root.
+// The root is found from the boundary points each time, since a subtree can
+// move to another document without the range changing.
+func (r *Range) update(root *html.Node, fn func(p *boundaryPoint)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rootHTMLNode(r.start.node) != root {
+		return
+	}
+	fn(&r.start)
+	fn(&r.end)
+}
+
+func (r *Range) StartContainer() spec.Node {
+	start, _ := r.bounds()
+	return NewNode(start.node)
+}
+
+func (r *Range) StartOffset() int {
+	start, _ := r.bounds()
+	return start.offset
+}
+
+func (r *Range) EndContainer() spec.Node {
+	_, end := r.bounds()
+	return NewNode(end.node)
+}
+
+func (r *Range) EndOffset() int {
+	_, end := r.bounds()
+	return end.offset
+}
+
+func (r *Range) Collapsed() bool {
+	start, end := r.bounds()
+	return start == end
+}
+
+// CommonAncestorContainer is based on https://dom.spec.whatwg.org/#dom-range-commonancestorcontainer
+func (r *Range) CommonAncestorContainer() spec.Node {
+	start, end := r.bounds()
+	return NewNode(commonAncestor(start.node, end.node))
+}
+
+func (r *Range) SetStart(node spec.Node, offset int) error {
+	n, err := rangeContainer(node)
+	if err != nil {
+		return err
+	}
+	return r.setBoundary(n, offset, true)
+}
+
+func (r *Range) SetEnd(node spec.Node, offset int) error {
+	n, err := rangeContainer(node)
+	if err != nil {
+		return err
+	}
+	return r.setBoundary(n, offset, false)
+}
+
+func (r *Range) SetStartBefore(node spec.Node) error {
+	parent, index, err := rangeChild(node)
+	if err != nil {
+		return err
+	}
+	return r.setBoundary(parent, index, true)
+}
+
+func (r *Range) SetStartAfter(node spec.Node) error {
+	parent, index, err := rangeChild(node)
+	if err != nil {
+		return err
+	}
+	return r.setBoundary(parent, index+1, true)
+}
+
+func (r *Range) SetEndBefore(node spec.Node) error {
+	parent, index, err := rangeChild(node)
+	if err != nil {
+		return err
+	}
+	return r.setBoundary(parent, index, false)
+}
+
+func (r *Range) SetEndAfter(node spec.Node) error {
+	parent, index, err := rangeChild(node)
+	if err != nil {
+		return err
+	}
+	return r.setBoundary(parent, index+1, false)
+}
+
+// setBoundary is based on https://dom.spec.whatwg.org/#concept-range-bp-set
+func (r *Range) setBoundary(node *html.Node, offset int, toStart bool) error {
+	if err := checkBoundaryPoint(node, offset); err != nil {
+		return err
+	}
+	p := boundaryPoint{node: node, offset: offset}
+	start, end := r.bounds()
+	sameRoot := rootHTMLNode(node) == rootHTMLNode(start.node)
+	if toStart {
+		if !sameRoot || comparePoints(p, end) > 0 {
+			end = p
+		}
+		start = p
+	} else {
+		if !sameRoot || comparePoints(p, start) < 0 {
+			start = p
+		}
+		end = p
+	}
+	r.setBounds(start, end)
+	return nil
+}
+
+// Collapse is based on https://dom.spec.whatwg.org/#dom-range-collapse
+func (r *Range) Collapse(toStart bool) {
+	start, end := r.bounds()
+	if toStart {
+		r.setBounds(start, start)
+	} else {
+		r.setBounds(end, end)
+	}
+}
+
+// SelectNode is based on https://dom.spec.whatwg.org/#concept-range-select
+func (r *Range) SelectNode(node spec.Node) error {
+	parent, index, err := rangeChild(node)
+	if err != nil {
+		return err
+	}
+	r.setBounds(boundaryPoint{node: parent, offset: index}, boundaryPoint{node: parent, offset: index + 1})
+	return nil
+}
+
+// SelectNodeContents is based on https://dom.spec.whatwg.org/#dom-range-selectnodecontents
+func (r *Range) SelectNodeContents(node spec.Node) error {
+	n, err := rangeContainer(node)
+	if err != nil {
+		return err
+	}
+	if n.Type == html.DoctypeNode {
+		return fmt.Errorf("%w: a DocumentType can not contain a boundary point", spec.ErrInvalidNodeType)
+	}
+	r.setBounds(boundaryPoint{node: n}, boundaryPoint{node: n, offset: nodeLength(n)})
+	return nil
+}
+
+// CompareBoundaryPoints is based on https://dom.spec.whatwg.org/#dom-range-compareboundarypoints
+func (r *Range) CompareBoundaryPoints(how spec.HowToCompare, sourceRange spec.Range) (int, error) {
+	source, ok := sourceRange.(*Range)
+	if !ok {
+		return 0, fmt.Errorf("%w: source range is a %T", spec.ErrWrongDocument, sourceRange)
+	}
+	start, end := r.bounds()
+	sourceStart, sourceEnd := source.bounds()
+	if rootHTMLNode(start.node) != rootHTMLNode(sourceStart.node) {
+		return 0, fmt.Errorf("%w: the ranges are in different trees", spec.ErrWrongDocument)
+	}
+	switch how {
+	case spec.StartToStart:
+		return comparePoints(start, sourceStart), nil
+	case spec.StartToEnd:
+		return comparePoints(end, sourceStart), nil
+	case spec.EndToEnd:
+		return comparePoints(end, sourceEnd), nil
+	case spec.EndToStart:
+		return comparePoints(start, sourceEnd), nil
+	default:
+		return 0, fmt.Errorf("%w: unknown comparison %d", spec.ErrNotSupported, how)
+	}
+}
+
+// DeleteContents is based on https://dom.spec.whatwg.org/#dom-range-deletecontents
+func (r *Range) DeleteContents() {
+	start, end := r.bounds()
+	if start == end {
+		return
+	}
+	if start.node == end.node && isCharacterData(start.node) {
+		_ = replaceData(start.node, start.offset, end.offset-start.offset, "")
+		return
+	}
+	nodes := containedNodes(start, end)
+	p := collapsedPoint(start, end)
+	if isCharacterData(start.node) {
+		_ = replaceData(start.node, start.offset, nodeLength(start.node)-start.offset, "")
+	}
+	for _, n := range nodes {
+		detachHTMLNode(n)
+	}
+	if isCharacterData(end.node) {
+		_ = replaceData(end.node, 0, end.offset, "")
+	}
+	r.setBounds(p, p)
+}
+
+// ExtractContents is based on https://dom.spec.whatwg.org/#dom-range-extractcontents
+func (r *Range) ExtractContents() (spec.DocumentFragment, error) {
+	start, end := r.bounds()
+	fragment := newRangeFragment(start)
+	p, err := extractContents(fragment, start, end)
+	if err != nil {
+		return nil, err
+	}
+	r.setBounds(p, p)
+	return &DocumentFragment{node: fragment}, nil
+}
+
+// CloneContents is based on https://dom.spec.whatwg.org/#dom-range-clonecontents
+func (r *Range) CloneContents() (spec.DocumentFragment, error) {
+	start, end := r.bounds()
+	fragment := newRangeFragment(start)
+	if err := cloneContents(fragment, start, end); err != nil {
+		return nil, err
+	}
+	return &DocumentFragment{node: fragment}, nil
+}
+
+// InsertNode is based on https://dom.spec.whatwg.org/#concept-range-insert
+func (r *Range) InsertNode(node spec.Node) error {
+	start, _ := r.bounds()
+	n := domNodeToHTMLNode(node)
+	if n == nil || start.node.Type == html.CommentNode ||
+		start.node.Type == html.TextNode && start.node.Parent == nil || start.node == n {
+		return fmt.Errorf("%w: can not insert at the start of the range", spec.ErrHierarchyRequest)
+	}
+	var reference *html.Node
+	if start.node.Type == html.TextNode {
+		reference = start.node
+	} else {
+		reference = childAt(start.node, start.offset)
+	}
+	parent := start.node
+	if reference != nil {
+		parent = reference.Parent
+	}
+	if err := ensurePreInsertValidity(node, parent, reference); err != nil {
+		return err
+	}
+	if start.node.Type == html.TextNode {
+		var err error
+		if reference, err = splitText(start.node, start.offset); err != nil {
+			return err
+		}
+	}
+	if n == reference {
+		reference = reference.NextSibling
+	}
+	length := 1
+	if fragment, ok := node.(*DocumentFragment); ok {
+		length = nodeLength(fragment.node)
+	} else {
+		detachHTMLNode(n)
+	}
+	offset := nodeLength(parent)
+	if reference != nil {
+		offset = nodeIndex(reference)
+	}
+	offset += length
+	insertHTMLNodes(parent, reference, convertNodes([]spec.Node{node}))
+	if start, end := r.bounds(); start == end {
+		r.setBounds(start, boundaryPoint{node: parent, offset: offset})
+	}
+	return nil
+}
+
+// SurroundContents is based on https://dom.spec.whatwg.org/#dom-range-surroundcontents
+func (r *Range) SurroundContents(newParent spec.Node) error {
+	start, end := r.bounds()
+	for _, p := range [][2]*html.Node{{start.node, end.node}, {end.node, start.node}} {
+		for n := p[0]; !isInclusiveAncestor(n, p[1]); n = n.Parent {
+			if n.Type != html.TextNode {
+				return fmt.Errorf("%w: the range partially contains a non-Text node", spec.ErrInvalidState)
+			}
+		}
+	}
+	switch newParent.NodeType() {
+	case spec.NodeTypeDocument, spec.NodeTypeDocumentType, spec.NodeTypeDocumentFragment:
+		return fmt.Errorf("%w: %T can not surround the range", spec.ErrInvalidNodeType, newParent)
+	case spec.NodeTypeText, spec.NodeTypeComment:
+		return fmt.Errorf("%w: %T can not have children", spec.ErrHierarchyRequest, newParent)
+	}
+	parent := domNodeToHTMLNode(newParent)
+	if parent == nil {
+		return fmt.Errorf("%w: %T can not surround the range", spec.ErrInvalidNodeType, newParent)
+	}
+	fragment := newRangeFragment(start)
+	p, err := extractContents(fragment, start, end)
+	if err != nil {
+		return err
+	}
+	r.setBounds(p, p)
+	replaceAllHTMLNodes(parent, nil)
+	if err := r.InsertNode(newParent); err != nil {
+		return err
+	}
+	insertHTMLNodes(parent, nil, convertNodes([]spec.Node{&DocumentFragment{node: fragment}}))
+	return r.SelectNode(newParent)
+}
+
+func (r *Range) CloneRange() spec.Range {
+	start, end := r.bounds()
+	return newRange(start, end)
+}
+
+// Detach is based on https://dom.spec.whatwg.org/#dom-range-detach
+func (r *Range) Detach() {}
+
+// IsPointInRange is based on https://dom.spec.whatwg.org/#dom-range-ispointinrange
+func (r *Range) IsPointInRange(node spec.Node, offset int) (bool, error) {
+	n := domNodeToHTMLNode(node)
+	start, end := r.bounds()
+	if n == nil || rootHTMLNode(n) != rootHTMLNode(start.node) {
+		return false, nil
+	}
+	if err := checkBoundaryPoint(n, offset); err != nil {
+		return false, err
+	}
+	p := boundaryPoint{node: n, offset: offset}
+	return comparePoints(p, start) >= 0 && comparePoints(p, end) <= 0, nil
+}
+
+// ComparePoint is based on https://dom.spec.whatwg.org/#dom-range-comparepoint
+func (r *Range) ComparePoint(node spec.Node, offset int) (int, error) {
+	n := domNodeToHTMLNode(node)
+	start, end := r.bounds()
+	if n == nil || rootHTMLNode(n) != rootHTMLNode(start.node) {
+		return 0, fmt.Errorf("%w: the node is not in the tree of the range", spec.ErrWrongDocument)
+	}
+	if err := checkBoundaryPoint(n, offset); err != nil {
+		return 0, err
+	}
+	p := boundaryPoint{node: n, offset: offset}
+	switch {
+	case comparePoints(p, start) < 0:
+		return -1, nil
+	case comparePoints(p, end) > 0:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// IntersectsNode is based on https://dom.spec.whatwg.org/#dom-range-intersectsnode
+func (r *Range) IntersectsNode(node spec.Node) bool {
+	n := domNodeToHTMLNode(node)
+	start, end := r.bounds()
+	if n == nil || rootHTMLNode(n) != rootHTMLNode(start.node) {
+		return false
+	}
+	if n.Parent == nil {
+		return true
+	}
+	index := nodeIndex(n)
+	return comparePoints(boundaryPoint{node: n.Parent, offset: index}, end) < 0 &&
+		comparePoints(boundaryPoint{node: n.Parent, offset: index + 1}, start) > 0
+}
+
+// String is based on https://dom.spec.whatwg.org/#dom-range-stringifier
+func (r *Range) String() string {
+	start, end := r.bounds()
+	if start.node == end.node && start.node.Type == html.TextNode {
+		s, _ := substringData(start.node, start.offset, end.offset-start.offset)
+		return s
+	}
+	var sb strings.Builder
+	if start.node.Type == html.TextNode {
+		s, _ := substringData(start.node, start.offset, nodeLength(start.node)-start.offset)
+		sb.WriteString(s)
+	}
+	for n := range commonAncestor(start.node, end.node).Descendants() {
+		if n.Type == html.TextNode && isContained(n, start, end) {
+			sb.WriteString(n.Data)
+		}
+	}
+	if end.node.Type == html.TextNode {
+		s, _ := substringData(end.node, 0, end.offset)
+		sb.WriteString(s)
+	}
+	return sb.String()
+}
+
+// boundaryPoint is based on https://dom.spec.whatwg.org/#concept-range-bp
+type boundaryPoint struct {
+	node   *html.Node
+	offset int
+}
+
+// comparePoints returns -1, 0 or 1 when a is before, equal to or after b.
+// It is based on https://dom.spec.whatwg.org/#concept-range-bp-position
+// and expects both points to have the same root.
+func comparePoints(a, b boundaryPoint) int {
+	if a.node == b.node {
+		return cmp.Compare(a.offset, b.offset)
+	}
+	if compareTreeOrder(a.node, b.node) > 0 {
+		return -comparePoints(b, a)
+	}
+	if isInclusiveAncestor(a.node, b.node) {
+		child := b.node
+		for child.Parent != a.node {
+			child = child.Parent
+		}
+		if nodeIndex(child) < a.offset {
+			return 1
+		}
+	}
+	return -1
+}
+
+// compareTreeOrder returns -1, 0 or 1 when a precedes, is or follows b in
+// tree order.
+func compareTreeOrder(a, b *html.Node) int {
+	if a == b {
+		return 0
+	}
+	pathA, pathB := inclusiveAncestorPath(a), inclusiveAncestorPath(b)
+	i := 0
+	for i < len(pathA) && i < len(pathB) && pathA[i] == pathB[i] {
+		i++
+	}
+	switch {
+	case i == len(pathA):
+		return -1
+	case i == len(pathB):
+		return 1
+	}
+	for n := pathA[i].NextSibling; n != nil; n = n.NextSibling {
+		if n == pathB[i] {
+			return -1
+		}
+	}
+	return 1
+}
+
+// inclusiveAncestorPath returns the inclusive ancestors of node starting
+// with its root.
+func inclusiveAncestorPath(node *html.Node) []*html.Node {
+	var path []*html.Node
+	for n := node; n != nil; n = n.Parent {
+		path = append(path, n)
+	}
+	slices.Reverse(path)
+	return path
+}
+
+func rootHTMLNode(node *html.Node) *html.Node {
+	for node.Parent != nil {
+		node = node.Parent
+	}
+	return node
+}
+
+func commonAncestor(a, b *html.Node) *html.Node {
+	for !isInclusiveAncestor(a, b) {
+		a = a.Parent
+	}
+	return a
+}
+
+// nodeLength is based on https://dom.spec.whatwg.org/#concept-node-length
+func nodeLength(node *html.Node) int {
+	switch node.Type {
+	case html.DoctypeNode:
+		return 0
+	case html.TextNode, html.CommentNode:
+		return utf16Length(node.Data)
+	}
+	length := 0
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		length++
+	}
+	return length
+}
+
+func isCharacterData(node *html.Node) bool {
+	return node.Type == html.TextNode || node.Type == html.CommentNode
+}
+
+func childAt(node *html.Node, index int) *html.Node {
+	c := node.FirstChild
+	for ; c != nil && index > 0; index-- {
+		c = c.NextSibling
+	}
+	return c
+}
+
+// rangeContainer returns the node backing a boundary point container.
+func rangeContainer(node spec.Node) (*html.Node, error) {
+	n := domNodeToHTMLNode(node)
+	if n == nil {
+		return nil, fmt.Errorf("%w: attributes can not contain a boundary point", spec.ErrNotSupported)
+	}
+	return n, nil
+}
+
+// rangeChild returns the parent of node and the index of node in it.
+func rangeChild(node spec.Node) (*html.Node, int, error) {
+	n, err := rangeContainer(node)
+	if err != nil {
+		return nil, 0, err
+	}
+	if n.Parent == nil {
+		return nil, 0, fmt.Errorf("%w: the node does not have a parent", spec.ErrInvalidNodeType)
+	}
+	return n.Parent, nodeIndex(n), nil
+}
+
+func checkBoundaryPoint(node *html.Node, offset int) error {
+	if node.Type == html.DoctypeNode {
+		return fmt.Errorf("%w: a DocumentType can not contain a boundary point", spec.ErrInvalidNodeType)
+	}
+	if length := nodeLength(node); offset < 0 || offset > length {
+		return fmt.Errorf("%w: offset %d is not in the range [0, %d]", spec.ErrIndexSize, offset, length)
+	}
+	return nil
+}
+
+// isContained is based on https://dom.spec.whatwg.org/#contained and
+// expects node to have the same root as the points.
+func isContained(node *html.Node, start, end boundaryPoint) bool {
+	return comparePoints(boundaryPoint{node: node}, start) > 0 &&
+		comparePoints(boundaryPoint{node: node, offset: nodeLength(node)}, end) < 0
+}
+
+// containedNodes returns the nodes contained between start and end whose
+// parent is not contained, in tree order.
+func containedNodes(start, end boundaryPoint) []*html.Node {
+	var nodes []*html.Node
+	var collect func(parent *html.Node)
+	collect = func(parent *html.Node) {
+		for c := parent.FirstChild; c != nil; c = c.NextSibling {
+			switch {
+			case isContained(c, start, end):
+				nodes = append(nodes, c)
+			case isInclusiveAncestor(c, start.node) || isInclusiveAncestor(c, end.node):
+				collect(c)
+			}
+		}
+	}
+	collect(commonAncestor(start.node, end.node))
+	return nodes
+}
+
+// partitionRange returns the children of the common ancestor of start and
+// end that are partially contained before and after the contained children.
+func partitionRange(start, end boundaryPoint) (first, last *html.Node, contained []*html.Node, err error) {
+	for c := commonAncestor(start.node, end.node).FirstChild; c != nil; c = c.NextSibling {
+		switch {
+		case isContained(c, start, end):
+			if c.Type == html.DoctypeNode {
+				return nil, nil, nil, fmt.Errorf("%w: the range contains a DocumentType", spec.ErrHierarchyRequest)
+			}
+			contained = append(contained, c)
+		case isInclusiveAncestor(c, start.node):
+			first = c
+		case isInclusiveAncestor(c, end.node):
+			last = c
+		}
+	}
+	return first, last, contained, nil
+}
+
+// collapsedPoint returns the point a range collapses to once the contents
+// between start and end are removed.
+func collapsedPoint(start, end boundaryPoint) boundaryPoint {
+	if isInclusiveAncestor(start.node, end.node) {
+		return start
+	}
+	n := start.node
+	for !isInclusiveAncestor(n.Parent, end.node) {
+		n = n.Parent
+	}
+	return boundaryPoint{node: n.Parent, offset: nodeIndex(n) + 1}
+}
+
+func newRangeFragment(start boundaryPoint) *html.Node {
+	fragment := &html.Node{Type: documentFragmentNode}
+	setNodeDocument(fragment, treeDocument(start.node))
+	return fragment
+}
+
+// treeDocument returns node when it is a document and its node document
+// otherwise.
+func treeDocument(node *html.Node) *html.Node {
+	if node.Type == html.DocumentNode {
+		return node
+	}
+	return ownerDocumentNode(node)
+}
+
+// cloneCharacterData returns a clone of node with the data between the
+// offsets from and to.
+func cloneCharacterData(node *html.Node, from, to int) *html.Node {
+	clone := cloneNode(node, false)
+	clone.Data, _ = substringData(node, from, to-from)
+	return clone
+}
+
+func appendHTMLNode(parent, node *html.Node) {
+	detachHTMLNode(node)
+	insertHTMLNodes(parent, nil, []*html.Node{node})
+}
+
+// extractContents is based on https://dom.spec.whatwg.org/#concept-range-extract
+// It moves the contents between start and end to parent and returns the
+// point the range collapses to.
+func extractContents(parent *html.Node, start, end boundaryPoint) (boundaryPoint, error) {
+	if start == end {
+		return start, nil
+	}
+	if start.node == end.node && isCharacterData(start.node) {
+		appendHTMLNode(parent, cloneCharacterData(start.node, start.offset, end.offset))
+		_ = replaceData(start.node, start.offset, end.offset-start.offset, "")
+		return start, nil
+	}
+	first, last, contained, err := partitionRange(start, end)
+	if err != nil {
+		return boundaryPoint{}, err
+	}
+	p := collapsedPoint(start, end)
+	if first != nil {
+		if isCharacterData(first) {
+			length := nodeLength(first)
+			appendHTMLNode(parent, cloneCharacterData(first, start.offset, length))
+			_ = replaceData(first, start.offset, length-start.offset, "")
+		} else {
+			clone := cloneNode(first, false)
+			appendHTMLNode(parent, clone)
+			if _, err := extractContents(clone, start, boundaryPoint{node: first, offset: nodeLength(first)}); err != nil {
+				return boundaryPoint{}, err
+			}
+		}
+	}
+	for _, c := range contained {
+		appendHTMLNode(parent, c)
+	}
+	if last != nil {
+		if isCharacterData(last) {
+			appendHTMLNode(parent, cloneCharacterData(last, 0, end.offset))
+			_ = replaceData(last, 0, end.offset, "")
+		} else {
+			clone := cloneNode(last, false)
+			appendHTMLNode(parent, clone)
+			if _, err := extractContents(clone, boundaryPoint{node: last}, end); err != nil {
+				return boundaryPoint{}, err
+			}
+		}
+	}
+	return p, nil
+}
+
+// cloneContents is based on https://dom.spec.whatwg.org/#concept-range-clone
+// It appends clones of the contents between start and end to parent.
+func cloneContents(parent *html.Node, start, end boundaryPoint) error {
+	if start == end {
+		return nil
+	}
+	if start.node == end.node && isCharacterData(start.node) {
+		appendHTMLNode(parent, cloneCharacterData(start.node, start.offset, end.offset))
+		return nil
+	}
+	first, last, contained, err := partitionRange(start, end)
+	if err != nil {
+		return err
+	}
+	if first != nil {
+		if isCharacterData(first) {
+			appendHTMLNode(parent, cloneCharacterData(first, start.offset, nodeLength(first)))
+		} else {
+			clone := cloneNode(first, false)
+			appendHTMLNode(parent, clone)
+			if err := cloneContents(clone, start, boundaryPoint{node: first, offset: nodeLength(first)}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, c := range contained {
+		appendHTMLNode(parent, cloneNode(c, true))
+	}
+	if last != nil {
+		if isCharacterData(last) {
+			appendHTMLNode(parent, cloneCharacterData(last, 0, end.offset))
+		} else {
+			clone := cloneNode(last, false)
+			appendHTMLNode(parent, clone)
+			if err := cloneContents(clone, boundaryPoint{node: last}, end); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
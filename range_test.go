@@ -0,0 +1,322 @@
+package dom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/typelate/dom/spec"
+)
+
+func TestRange(t *testing.T) {
+	// language=html
+	const textHTML = `<!DOCTYPE html>
+<html lang="us-en">
+<head><title>Range</title></head>
+<body><p id="p">Hello, <em>wor</em>ld!</p><ul id="list"><li>A</li><li>B</li><li>C</li></ul></body>
+</html>`
+
+	// selectMatch returns a range from offset start of the first text node of
+	// p to offset end of the text node in its em.
+	selectMatch := func(t *testing.T, document *Document, p spec.Element, start, end int) spec.Range {
+		t.Helper()
+		r := document.CreateRange()
+		require.NoError(t, r.SetStart(p.FirstChild(), start))
+		require.NoError(t, r.SetEnd(p.QuerySelector("em").FirstChild(), end))
+		return r
+	}
+
+	t.Run("CreateRange", func(t *testing.T) {
+		document, _ := parseDocument(t, textHTML, "")
+		r := document.CreateRange()
+		assert.True(t, r.StartContainer().IsSameNode(document))
+		assert.Equal(t, 0, r.StartOffset())
+		assert.True(t, r.Collapsed())
+		assert.True(t, r.CommonAncestorContainer().IsSameNode(document))
+		assert.Equal(t, "", r.String())
+	})
+	t.Run("String", func(t *testing.T) {
+		document, p := parseDocument(t, textHTML, "#p")
+		r := selectMatch(t, document, p, 3, 2)
+		assert.Equal(t, "lo, wo", r.String())
+		assert.False(t, r.Collapsed())
+		assert.True(t, r.CommonAncestorContainer().IsSameNode(p))
+
+		require.NoError(t, r.SelectNodeContents(p))
+		assert.Equal(t, "Hello, world!", r.String())
+		assert.Equal(t, 3, r.EndOffset())
+	})
+	t.Run("highlight across an element boundary", func(t *testing.T) {
+		document, p := parseDocument(t, textHTML, "#p")
+		r := selectMatch(t, document, p, 3, 2)
+		mark := document.CreateElement("mark")
+		require.ErrorIs(t, r.SurroundContents(mark), spec.ErrInvalidState, "em is partially selected")
+
+		fragment, err := r.ExtractContents()
+		require.NoError(t, err)
+		assert.Equal(t, "lo, <em>wo</em>", fragment.(*DocumentFragment).String())
+		assert.True(t, r.Collapsed())
+		assert.True(t, r.StartContainer().IsSameNode(p))
+		assert.Equal(t, 1, r.StartOffset())
+
+		mark.Append(fragment)
+		require.NoError(t, r.InsertNode(mark))
+		assert.Equal(t, "Hel<mark>lo, <em>wo</em></mark><em>r</em>ld!", p.InnerHTML())
+		assert.True(t, r.StartContainer().IsSameNode(p))
+		assert.Equal(t, 1, r.StartOffset())
+		assert.Equal(t, 2, r.EndOffset(), "a collapsed range is extended over the inserted node")
+	})
+	t.Run("SurroundContents", func(t *testing.T) {
+		document, p := parseDocument(t, textHTML, "#p")
+		r := document.CreateRange()
+		require.NoError(t, r.SetStart(p.FirstChild(), 3))
+		require.NoError(t, r.SetEndAfter(p.QuerySelector("em")))
+		mark := document.CreateElement("mark")
+		mark.Append(document.CreateTextNode("replaced"))
+		require.NoError(t, r.SurroundContents(mark))
+		assert.Equal(t, "Hel<mark>lo, <em>wor</em></mark>ld!", p.InnerHTML())
+		assert.Equal(t, "lo, wor", r.String())
+		assert.True(t, r.StartContainer().IsSameNode(p))
+
+		require.ErrorIs(t, r.SurroundContents(document.CreateDocumentFragment()), spec.ErrInvalidNodeType)
+		require.ErrorIs(t, r.SurroundContents(document.CreateTextNode("")), spec.ErrHierarchyRequest)
+	})
+	t.Run("CloneContents", func(t *testing.T) {
+		document, p := parseDocument(t, textHTML, "#p")
+		r := selectMatch(t, document, p, 3, 2)
+		fragment, err := r.CloneContents()
+		require.NoError(t, err)
+		assert.Equal(t, "lo, <em>wo</em>", fragment.(*DocumentFragment).String())
+		assert.Equal(t, "Hello, <em>wor</em>ld!", p.InnerHTML())
+	})
+	t.Run("DeleteContents", func(t *testing.T) {
+		document, p := parseDocument(t, textHTML, "#p")
+		r := selectMatch(t, document, p, 3, 2)
+		r.DeleteContents()
+		assert.Equal(t, "Hel<em>r</em>ld!", p.InnerHTML())
+		assert.True(t, r.Collapsed())
+		assert.True(t, r.StartContainer().IsSameNode(p))
+		assert.Equal(t, 1, r.StartOffset())
+
+		require.NoError(t, r.SetStart(p.FirstChild(), 1))
+		require.NoError(t, r.SetEnd(p.FirstChild(), 2))
+		r.DeleteContents()
+		assert.Equal(t, "Hl<em>r</em>ld!", p.InnerHTML())
+		assert.Equal(t, spec.NodeTypeText, r.StartContainer().NodeType())
+		assert.Equal(t, 1, r.EndOffset())
+	})
+	t.Run("InsertNode", func(t *testing.T) {
+		document, p := parseDocument(t, textHTML, "#p")
+		r := document.CreateRange()
+		require.NoError(t, r.SetStart(p.FirstChild(), 5))
+		require.NoError(t, r.SetEnd(p.LastChild(), 2))
+		require.NoError(t, r.InsertNode(document.CreateElement("br")))
+		assert.Equal(t, "Hello<br/>, <em>wor</em>ld!", p.InnerHTML())
+		assert.Equal(t, "Hello", r.StartContainer().TextContent(), "the start stays in the split text")
+		assert.Equal(t, ", world", r.String())
+
+		require.ErrorIs(t, r.InsertNode(p), spec.ErrHierarchyRequest)
+		require.NoError(t, r.InsertNode(document.CreateComment("x")))
+		require.NoError(t, r.SetStart(p.QuerySelector("br"), 0))
+		require.ErrorIs(t, r.InsertNode(document.Doctype()), spec.ErrHierarchyRequest)
+	})
+	t.Run("boundary errors", func(t *testing.T) {
+		document, p := parseDocument(t, textHTML, "#p")
+		r := document.CreateRange()
+		require.ErrorIs(t, r.SetStart(p, 4), spec.ErrIndexSize)
+		require.ErrorIs(t, r.SetEnd(p.FirstChild(), -1), spec.ErrIndexSize)
+		require.ErrorIs(t, r.SetStart(document.Doctype(), 0), spec.ErrInvalidNodeType)
+		require.ErrorIs(t, r.SelectNode(document), spec.ErrInvalidNodeType)
+		require.ErrorIs(t, r.SelectNodeContents(document.Doctype()), spec.ErrInvalidNodeType)
+		require.ErrorIs(t, r.SetStart(p.GetAttributeNode("id"), 0), spec.ErrNotSupported)
+	})
+	t.Run("setting a boundary after the end", func(t *testing.T) {
+		document, p := parseDocument(t, textHTML, "#p")
+		r := document.CreateRange()
+		require.NoError(t, r.SetStart(p, 2))
+		assert.True(t, r.EndContainer().IsSameNode(p), "the end moves with the start")
+		require.NoError(t, r.SetEnd(p, 1))
+		assert.Equal(t, 1, r.StartOffset(), "the start moves with the end")
+
+		r.Collapse(true)
+		detached := document.CreateElement("div")
+		require.NoError(t, r.SetEnd(detached, 0))
+		assert.True(t, r.StartContainer().IsSameNode(detached), "a different root collapses the range")
+	})
+	t.Run("comparisons", func(t *testing.T) {
+		document, ul := parseDocument(t, textHTML, "#list")
+		items := ul.Children()
+		r := document.CreateRange()
+		require.NoError(t, r.SelectNode(items.Item(1)))
+		other := document.CreateRange()
+		require.NoError(t, other.SelectNodeContents(ul))
+
+		for how, want := range map[spec.HowToCompare]int{
+			spec.StartToStart: 1,
+			spec.StartToEnd:   1,
+			spec.EndToEnd:     -1,
+			spec.EndToStart:   -1,
+		} {
+			got, err := r.CompareBoundaryPoints(how, other)
+			require.NoError(t, err)
+			assert.Equal(t, want, got, how)
+		}
+		_, err := r.CompareBoundaryPoints(spec.HowToCompare(9), other)
+		require.ErrorIs(t, err, spec.ErrNotSupported)
+		detached := document.CreateRange()
+		require.NoError(t, detached.SelectNodeContents(document.CreateElement("div")))
+		_, err = r.CompareBoundaryPoints(spec.StartToStart, detached)
+		require.ErrorIs(t, err, spec.ErrWrongDocument)
+
+		in, err := r.IsPointInRange(items.Item(1).FirstChild(), 1)
+		require.NoError(t, err)
+		assert.True(t, in)
+		in, err = r.IsPointInRange(ul, 0)
+		require.NoError(t, err)
+		assert.False(t, in)
+		_, err = r.IsPointInRange(ul, 9)
+		require.ErrorIs(t, err, spec.ErrIndexSize)
+
+		for offset, want := range []int{-1, 0, 0, 1} {
+			got, err := r.ComparePoint(ul, offset)
+			require.NoError(t, err)
+			assert.Equal(t, want, got, offset)
+		}
+		_, err = r.ComparePoint(document.CreateElement("div"), 0)
+		require.ErrorIs(t, err, spec.ErrWrongDocument)
+
+		assert.False(t, r.IntersectsNode(items.Item(0)))
+		assert.True(t, r.IntersectsNode(items.Item(1)))
+		assert.True(t, r.IntersectsNode(ul))
+		assert.True(t, r.IntersectsNode(document))
+		assert.False(t, r.IntersectsNode(items.Item(2)))
+		assert.False(t, r.IntersectsNode(document.CreateElement("div")))
+	})
+	t.Run("CloneRange", func(t *testing.T) {
+		document, p := parseDocument(t, textHTML, "#p")
+		r := selectMatch(t, document, p, 3, 2)
+		clone := r.CloneRange()
+		r.Collapse(true)
+		r.Detach()
+		assert.Equal(t, "lo, wo", clone.String())
+	})
+}
+
+func TestRange_live(t *testing.T) {
+	// language=html
+	const textHTML = `<!DOCTYPE html>
+<html lang="us-en">
+<head><title>Live</title></head>
+<body><ul id="list"><li>A</li><li id="b">Bee</li><li>C</li></ul></body>
+</html>`
+
+	t.Run("insertion", func(t *testing.T) {
+		document, ul := parseDocument(t, textHTML, "#list")
+		r := document.CreateRange()
+		require.NoError(t, r.SetStart(ul, 1))
+		require.NoError(t, r.SetEnd(ul, 2))
+		ul.Prepend(document.CreateElement("li"), document.CreateElement("li"))
+		assert.Equal(t, 3, r.StartOffset())
+		assert.Equal(t, 4, r.EndOffset())
+		ul.Append(document.CreateElement("li"))
+		assert.Equal(t, 4, r.EndOffset())
+		assert.Equal(t, "Bee", r.String())
+	})
+	t.Run("removal", func(t *testing.T) {
+		document, ul := parseDocument(t, textHTML, "#list")
+		b := document.GetElementByID("b")
+		r := document.CreateRange()
+		require.NoError(t, r.SetStart(b.FirstChild(), 1))
+		require.NoError(t, r.SetEnd(ul, 3))
+		ul.FirstElementChild().Remove()
+		assert.Equal(t, 2, r.EndOffset())
+		b.Remove()
+		assert.True(t, r.StartContainer().IsSameNode(ul), "a removed start moves to the parent")
+		assert.Equal(t, 0, r.StartOffset())
+		assert.Equal(t, 1, r.EndOffset())
+		assert.Equal(t, "C", r.String())
+	})
+	t.Run("removal after adopt", func(t *testing.T) {
+		document, _ := parseDocument(t, textHTML, "#list")
+		div := document.CreateElement("div")
+		span := document.CreateElement("span")
+		div.AppendChild(span)
+		r := document.CreateRange()
+		require.NoError(t, r.SelectNodeContents(span))
+
+		other, _ := parseDocument(t, textHTML, "")
+		_, err := other.AdoptNode(div)
+		require.NoError(t, err)
+		span.Remove()
+		assert.True(t, r.StartContainer().IsSameNode(div), "the range follows its subtree to the other document")
+		assert.True(t, r.EndContainer().IsSameNode(div))
+		assert.Equal(t, 0, r.StartOffset())
+		assert.True(t, r.CommonAncestorContainer().IsSameNode(div))
+	})
+	t.Run("character data", func(t *testing.T) {
+		document, _ := parseDocument(t, textHTML, "#list")
+		text := document.GetElementByID("b").FirstChild().(spec.Text)
+		r := document.CreateRange()
+		require.NoError(t, r.SetStart(text, 1))
+		require.NoError(t, r.SetEnd(text, 3))
+		require.NoError(t, text.InsertData(0, "Honey"))
+		assert.Equal(t, 6, r.StartOffset())
+		assert.Equal(t, 8, r.EndOffset())
+		assert.Equal(t, "ee", r.String())
+		require.NoError(t, text.DeleteData(5, 2))
+		assert.Equal(t, 5, r.StartOffset(), "offsets in deleted data move to its start")
+		assert.Equal(t, 6, r.EndOffset())
+		text.SetData("Bee")
+		assert.Equal(t, 0, r.StartOffset())
+		assert.Equal(t, 0, r.EndOffset())
+	})
+	t.Run("SplitText and Normalize", func(t *testing.T) {
+		document, _ := parseDocument(t, textHTML, "#list")
+		b := document.GetElementByID("b")
+		text := b.FirstChild().(spec.Text)
+		r := document.CreateRange()
+		require.NoError(t, r.SetStart(text, 1))
+		require.NoError(t, r.SetEnd(b, 1))
+		second, err := text.SplitText(1)
+		require.NoError(t, err)
+		assert.True(t, r.StartContainer().IsSameNode(text))
+		assert.Equal(t, 1, r.StartOffset())
+		assert.Equal(t, 2, r.EndOffset(), "the end stays after the split text")
+
+		require.NoError(t, r.SetStart(second, 1))
+		b.Normalize()
+		assert.True(t, r.StartContainer().IsSameNode(text))
+		assert.Equal(t, 2, r.StartOffset())
+		assert.True(t, r.EndContainer().IsSameNode(b))
+		assert.Equal(t, 1, r.EndOffset())
+		assert.Equal(t, "e", r.String())
+
+		require.NoError(t, r.SetEnd(b, 1))
+		_, err = text.SplitText(2)
+		require.NoError(t, err)
+		require.NoError(t, r.SetStart(b, 1))
+		b.Normalize()
+		assert.True(t, r.StartContainer().IsSameNode(text), "a point between merged nodes moves into the text")
+		assert.Equal(t, 2, r.StartOffset())
+	})
+}
+
+func TestNewStaticRange(t *testing.T) {
+	document, p := parseDocument(t, `<!DOCTYPE html><p>Hello</p>`, "p")
+	text := p.FirstChild().(spec.Text)
+	r, err := NewStaticRange(spec.StaticRangeInit{StartContainer: text, StartOffset: 1, EndContainer: text, EndOffset: 9})
+	require.NoError(t, err)
+	assert.True(t, r.StartContainer().IsSameNode(text))
+	assert.Equal(t, 1, r.StartOffset())
+	assert.Equal(t, 9, r.EndOffset(), "offsets are not checked")
+	assert.False(t, r.Collapsed())
+
+	require.NoError(t, text.InsertData(0, "Oh, "))
+	assert.Equal(t, 1, r.StartOffset(), "static ranges are not live")
+
+	_, err = NewStaticRange(spec.StaticRangeInit{StartContainer: document.Doctype(), EndContainer: text})
+	require.ErrorIs(t, err, spec.ErrInvalidNodeType)
+	_, err = NewStaticRange(spec.StaticRangeInit{StartContainer: text})
+	require.ErrorIs(t, err, spec.ErrInvalidNodeType)
+}
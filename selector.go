@@ -0,0 +1,99 @@
+package dom
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/andybalholm/cascadia"
+
+	"github.com/typelate/dom/spec"
+)
+
+// Selector is a parsed group of CSS selectors. It is safe for concurrent use.
+// Use it with the Compiled query methods to avoid parsing the same selector
+// on every call.
+type Selector struct {
+	source  string
+	matcher cascadia.Matcher
+}
+
+// CompileSelector parses selector. It returns a *spec.SelectorError when
+// selector is not valid.
+func CompileSelector(selector string) (*Selector, error) {
+	m, err := cascadia.ParseGroup(selector)
+	if err != nil {
+		return nil, &spec.SelectorError{Selector: selector, Err: err}
+	}
+	return &Selector{source: selector, matcher: m}, nil
+}
+
+// MustCompileSelector is like CompileSelector but panics when selector is
+// not valid. It simplifies initializing package level variables.
+func MustCompileSelector(selector string) *Selector {
+	s, err := CompileSelector(selector)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func (s *Selector) String() string { return s.source }
+
+// selectorCacheSize bounds the number of selectors kept by selectors.
+const selectorCacheSize = 256
+
+// selectors caches the selectors parsed for the string based query methods.
+var selectors = selectorCache{size: selectorCacheSize}
+
+// compileSelector returns the cached result of CompileSelector.
+func compileSelector(selector string) (cascadia.Matcher, error) {
+	s, err := selectors.load(selector)
+	if err != nil {
+		return nil, err
+	}
+	return s.matcher, nil
+}
+
+type selectorCacheEntry struct {
+	source   string
+	selector *Selector
+	err      error
+}
+
+// selectorCache is a least recently used cache of parsed selectors. Errors
+// are cached too, so a repeated invalid selector is not parsed again.
+type selectorCache struct {
+	size int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   list.List
+}
+
+func (c *selectorCache) load(source string) (*Selector, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[source]; ok {
+		c.order.MoveToFront(e)
+		entry := e.Value.(*selectorCacheEntry)
+		c.mu.Unlock()
+		return entry.selector, entry.err
+	}
+	c.mu.Unlock()
+
+	s, err := CompileSelector(source)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]*list.Element)
+	}
+	if _, ok := c.entries[source]; !ok {
+		c.entries[source] = c.order.PushFront(&selectorCacheEntry{source: source, selector: s, err: err})
+		for c.order.Len() > c.size {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(*selectorCacheEntry).source)
+		}
+	}
+	return s, err
+}
@@ -0,0 +1,169 @@
+package dom
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/typelate/dom/spec"
+)
+
+// language=html
+const selectorTestHTML = `<!DOCTYPE html>
+<html lang="us-en">
+<head><title>Fruit</title></head>
+<body>
+<ul id="fruit">
+	<li class="fruit red">Apple</li>
+	<li class="fruit yellow">Banana</li>
+	<li class="fruit orange"><span class="name">Peach</span></li>
+</ul>
+</body>
+</html>`
+
+func TestCompileSelector(t *testing.T) {
+	document, ul := parseDocument(t, selectorTestHTML, "#fruit")
+
+	t.Run("compiled queries", func(t *testing.T) {
+		sel, err := CompileSelector("li.fruit")
+		require.NoError(t, err)
+		assert.Equal(t, "li.fruit", sel.String())
+
+		body := document.Body().(*Element)
+		assert.Equal(t, 3, body.QuerySelectorAllCompiled(sel).Length())
+		assert.Equal(t, "Apple", body.QuerySelectorCompiled(sel).TextContent())
+		assert.Equal(t, "Apple", document.QuerySelectorCompiled(sel).TextContent())
+		count := 0
+		for range document.QuerySelectorSequenceCompiled(sel) {
+			count++
+		}
+		assert.Equal(t, 3, count)
+
+		span := ul.QuerySelector(".name").(*Element)
+		assert.True(t, span.ClosestCompiled(MustCompileSelector("ul")).IsSameNode(ul))
+		assert.True(t, span.ParentElement().(*Element).MatchesCompiled(MustCompileSelector(".orange")))
+		assert.False(t, span.MatchesCompiled(sel))
+	})
+	t.Run("fragment", func(t *testing.T) {
+		fragment := NewDocumentFragment(nil)
+		fragment.Append(ul.CloneNode(true))
+		assert.Equal(t, 3, fragment.QuerySelectorAllCompiled(MustCompileSelector("li")).Length())
+		assert.NotNil(t, fragment.QuerySelectorCompiled(MustCompileSelector(".name")))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := CompileSelector("li[")
+		require.ErrorIs(t, err, spec.ErrSyntax)
+		assert.Panics(t, func() { MustCompileSelector("li[") })
+	})
+}
+
+func TestSelectorCache(t *testing.T) {
+	t.Run("bounded", func(t *testing.T) {
+		cache := selectorCache{size: 4}
+		for i := range 10 {
+			_, err := cache.load("#id" + strconv.Itoa(i))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 4, cache.order.Len())
+		assert.Len(t, cache.entries, 4)
+		assert.Contains(t, cache.entries, "#id9")
+		assert.NotContains(t, cache.entries, "#id0")
+	})
+	t.Run("least recently used", func(t *testing.T) {
+		cache := selectorCache{size: 2}
+		a, _ := cache.load("a")
+		_, _ = cache.load("b")
+		again, _ := cache.load("a")
+		assert.Same(t, a, again)
+		_, _ = cache.load("c")
+		assert.Contains(t, cache.entries, "a")
+		assert.NotContains(t, cache.entries, "b")
+	})
+	t.Run("errors", func(t *testing.T) {
+		cache := selectorCache{size: 2}
+		_, err := cache.load("a[")
+		require.ErrorIs(t, err, spec.ErrSyntax)
+		_, again := cache.load("a[")
+		assert.Same(t, err, again)
+	})
+	t.Run("concurrent", func(t *testing.T) {
+		document, _ := parseDocument(t, selectorTestHTML, "")
+		var wg sync.WaitGroup
+		for i := range 8 {
+			wg.Go(func() {
+				for j := range 100 {
+					query := "li:nth-child(" + strconv.Itoa((i+j)%3+1) + ")"
+					assert.NotNil(t, document.QuerySelector(query))
+				}
+			})
+		}
+		wg.Wait()
+	})
+}
+
+func BenchmarkSelector(b *testing.B) {
+	document, ul := parseDocument(b, selectorTestHTML, "#fruit")
+	span := ul.QuerySelector(".name").(*Element)
+	const query = "ul#fruit > li.fruit.orange span.name"
+
+	b.Run("QuerySelector", func(b *testing.B) {
+		b.Run("parse", func(b *testing.B) {
+			for b.Loop() {
+				sel, _ := CompileSelector(query)
+				_ = document.QuerySelectorCompiled(sel)
+			}
+		})
+		b.Run("cached", func(b *testing.B) {
+			for b.Loop() {
+				_ = document.QuerySelector(query)
+			}
+		})
+		b.Run("compiled", func(b *testing.B) {
+			sel := MustCompileSelector(query)
+			for b.Loop() {
+				_ = document.QuerySelectorCompiled(sel)
+			}
+		})
+	})
+	b.Run("Matches", func(b *testing.B) {
+		b.Run("parse", func(b *testing.B) {
+			for b.Loop() {
+				sel, _ := CompileSelector(query)
+				_ = span.MatchesCompiled(sel)
+			}
+		})
+		b.Run("cached", func(b *testing.B) {
+			for b.Loop() {
+				_ = span.Matches(query)
+			}
+		})
+		b.Run("compiled", func(b *testing.B) {
+			sel := MustCompileSelector(query)
+			for b.Loop() {
+				_ = span.MatchesCompiled(sel)
+			}
+		})
+	})
+	b.Run("Closest", func(b *testing.B) {
+		b.Run("parse", func(b *testing.B) {
+			for b.Loop() {
+				sel, _ := CompileSelector("ul#fruit")
+				_ = span.ClosestCompiled(sel)
+			}
+		})
+		b.Run("cached", func(b *testing.B) {
+			for b.Loop() {
+				_ = span.Closest("ul#fruit")
+			}
+		})
+		b.Run("compiled", func(b *testing.B) {
+			sel := MustCompileSelector("ul#fruit")
+			for b.Loop() {
+				_ = span.ClosestCompiled(sel)
+			}
+		})
+	})
+}
@@ -0,0 +1,45 @@
+package spec
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors named after the DOMException error names in
+// https://webidl.spec.whatwg.org/#idl-DOMException-error-names.
+//
+// Implementations wrap these with additional context; use errors.Is to check
+// for a particular kind of failure.
+var (
+	ErrHierarchyRequest      = errors.New("HierarchyRequestError")
+	ErrIndexSize             = errors.New("IndexSizeError")
+	ErrInUseAttribute        = errors.New("InUseAttributeError")
+	ErrInvalidCharacter      = errors.New("InvalidCharacterError")
+	ErrInvalidNodeType       = errors.New("InvalidNodeTypeError")
+	ErrInvalidState          = errors.New("InvalidStateError")
+	ErrNamespace             = errors.New("NamespaceError")
+	ErrNoModificationAllowed = errors.New("NoModificationAllowedError")
+	ErrNotFound              = errors.New("NotFoundError")
+	ErrNotSupported          = errors.New("NotSupportedError")
+	ErrSyntax                = errors.New("SyntaxError")
+	ErrWrongDocument         = errors.New("WrongDocumentError")
+)
+
+// ErrType is returned where the spec throws a TypeError, an ECMAScript error
+// rather than a DOMException.
+var ErrType = errors.New("TypeError")
+
+// SelectorError is returned by the query methods with an Err suffix when a
+// selector can not be parsed. It models the SyntaxError thrown by
+// querySelector and matches ErrSyntax with errors.Is.
+type SelectorError struct {
+	Selector string
+	// Err is the parser (or browser) error describing the problem.
+	Err error
+}
+
+func (e *SelectorError) Error() string {
+	return fmt.Sprintf("invalid selector %q: %v", e.Selector, e.Err)
+}
+
+func (e *SelectorError) Unwrap() []error { return []error{ErrSyntax, e.Err} }
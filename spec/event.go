@@ -0,0 +1,105 @@
+package spec
+
+import "context"
+
+// EventPhase is based on https://dom.spec.whatwg.org/#dom-event-eventphase.
+type EventPhase int
+
+// EventPhase values from https://dom.spec.whatwg.org/#interface-event.
+const (
+	EventPhaseNone EventPhase = iota
+	EventPhaseCapturing
+	EventPhaseAtTarget
+	EventPhaseBubbling
+)
+
+// EventInit is based on https://dom.spec.whatwg.org/#dictdef-eventinit.
+type EventInit struct {
+	Bubbles    bool
+	Cancelable bool
+	Composed   bool
+}
+
+// CustomEventInit is based on https://dom.spec.whatwg.org/#dictdef-customeventinit.
+type CustomEventInit struct {
+	EventInit
+	Detail any
+}
+
+// Event is based on https://dom.spec.whatwg.org/#interface-event.
+type Event interface {
+	Type() string
+	// Target and CurrentTarget return nil before the event is dispatched.
+	// CurrentTarget is also nil after dispatch.
+	Target() EventTarget
+	CurrentTarget() EventTarget
+	EventPhase() EventPhase
+
+	Bubbles() bool
+	Cancelable() bool
+	// Composed reports whether the event propagates out of a shadow root.
+	// Trees without shadow roots dispatch composed events like any other.
+	Composed() bool
+	IsTrusted() bool
+
+	StopPropagation()
+	StopImmediatePropagation()
+	// PreventDefault cancels a Cancelable event unless it is called from a
+	// passive listener.
+	PreventDefault()
+	DefaultPrevented() bool
+}
+
+// CustomEvent is based on https://dom.spec.whatwg.org/#interface-customevent.
+type CustomEvent interface {
+	Event
+	Detail() any
+}
+
+// EventListener is based on https://dom.spec.whatwg.org/#callbackdef-eventlistener.
+// Listeners are compared with ==, so they must be comparable and
+// RemoveEventListener must be given the value passed to AddEventListener.
+type EventListener interface {
+	HandleEvent(event Event)
+}
+
+// NewEventListener adapts fn to an EventListener. Each call returns a
+// distinct listener.
+func NewEventListener(fn func(event Event)) EventListener {
+	return &eventListenerFunc{fn: fn}
+}
+
+type eventListenerFunc struct {
+	fn func(event Event)
+}
+
+func (l *eventListenerFunc) HandleEvent(event Event) { l.fn(event) }
+
+// EventListenerOptions is based on https://dom.spec.whatwg.org/#dictdef-eventlisteneroptions.
+type EventListenerOptions struct {
+	Capture bool
+}
+
+// AddEventListenerOptions is based on https://dom.spec.whatwg.org/#dictdef-addeventlisteneroptions.
+type AddEventListenerOptions struct {
+	EventListenerOptions
+	Once    bool
+	Passive bool
+	// Signal removes the listener when it is done, like an AbortSignal.
+	// A listener is not added when Signal is already done.
+	Signal context.Context
+}
+
+// EventTarget is based on https://dom.spec.whatwg.org/#interface-eventtarget.
+// Listeners run synchronously, in the goroutine calling DispatchEvent.
+type EventTarget interface {
+	// AddEventListener does nothing when listener is nil or was already
+	// added with the same type and Capture option.
+	AddEventListener(eventType string, listener EventListener, options AddEventListenerOptions)
+	RemoveEventListener(eventType string, listener EventListener, options EventListenerOptions)
+	// DispatchEvent returns false when a listener canceled the event. It
+	// returns an error wrapping ErrInvalidState when the event is already
+	// being dispatched, or ErrNotSupported when it was not created by the
+	// same package as the target.
+	DispatchEvent(event Event) (bool, error)
+}
@@ -12,14 +12,27 @@ import "iter"
 // Contains, InsertBefore, AppendChild, ReplaceChild, RemoveChild) live on
 // ParentNode instead, keeping leaf types like Text slim.
 //
-// NodeValue and IsEqualNode are omitted: NodeValue only applies to Text (which
-// has Data) and Attr; IsEqualNode varies per node type.
+// NodeValue is omitted: it only applies to CharacterData (which has Data) and
+// Attr (which has Value).
 type Node interface {
 	NodeType() NodeType
 	CloneNode(deep bool) Node
 	IsSameNode(other Node) bool
+
+	// IsEqualNode is based on https://dom.spec.whatwg.org/#concept-node-equals
+	IsEqualNode(other Node) bool
+
+	// GetRootNode is based on https://dom.spec.whatwg.org/#dom-node-getrootnode
+	// and does not support the composed option.
+	GetRootNode() Node
+
 	TextContent() string
 	CompareDocumentPosition(other Node) DocumentPosition
+
+	// LookupNamespaceURI and LookupPrefix use the empty string where the
+	// spec uses null.
+	LookupNamespaceURI(prefix string) string
+	LookupPrefix(namespace string) string
 }
 
 // ChildNode extends Node with tree-position methods for nodes that can be children.
@@ -32,12 +45,24 @@ type ChildNode interface {
 	ParentElement() Element
 	PreviousSibling() ChildNode
 	NextSibling() ChildNode
+	ChildNodeSequences
 
 	// Length is based on https://dom.spec.whatwg.org/#concept-node-length
 	Length() int
+
+	// Before, After, ReplaceWith, and Remove are from the ChildNode mixin
+	// https://dom.spec.whatwg.org/#interface-childnode. DocumentFragment
+	// arguments are expanded to their children. They do nothing when the
+	// node has no parent.
+	Before(nodes ...Node)
+	After(nodes ...Node)
+	ReplaceWith(nodes ...Node)
+	Remove()
 }
 
-// Normalizer may be implemented by a Node and should follow https://dom.spec.whatwg.org/#dom-node-normalize
+// Normalizer removes empty Text descendants and merges adjacent ones. It is
+// implemented by Element, Document, and DocumentFragment and should follow
+// https://dom.spec.whatwg.org/#dom-node-normalize
 type Normalizer interface {
 	Normalize()
 }
@@ -55,6 +80,24 @@ const (
 	DocumentPositionImplementationSpecific
 )
 
+// GetHTMLOptions is based on https://html.spec.whatwg.org/#gethtmloptions.
+// Shadow roots are only serialized by implementations that have them.
+type GetHTMLOptions struct {
+	SerializableShadowRoots bool
+}
+
+// InsertPosition is the position argument of the Element InsertAdjacent
+// methods. Positions are matched ASCII case-insensitively.
+type InsertPosition string
+
+// InsertPosition values from https://dom.spec.whatwg.org/#dom-element-insertadjacentelement.
+const (
+	InsertBeforeBegin InsertPosition = "beforebegin"
+	InsertAfterBegin  InsertPosition = "afterbegin"
+	InsertBeforeEnd   InsertPosition = "beforeend"
+	InsertAfterEnd    InsertPosition = "afterend"
+)
+
 // NodeType values from https://dom.spec.whatwg.org/#interface-node.
 type NodeType int
 
@@ -112,28 +155,133 @@ func (nt NodeType) String() string {
 type NodeList[T Node] interface {
 	Length() int
 	Item(int) T
+
+	// All yields the index and node of each item in order.
+	All() iter.Seq2[int, T]
 }
 
-// Text represents a text node. See https://dom.spec.whatwg.org/#interface-text.
-type Text interface {
+// ChildNodeSequences adds iterator-based traversal from a node outward.
+// The sequences read the tree as they go and stop when the loop body breaks.
+type ChildNodeSequences interface {
+	// Ancestors yields the parent, its parent, and so on up to the root.
+	Ancestors() iter.Seq[Node]
+	// FollowingSiblings yields the siblings after the node in tree order.
+	FollowingSiblings() iter.Seq[ChildNode]
+	// PrecedingSiblings yields the siblings before the node, nearest first.
+	PrecedingSiblings() iter.Seq[ChildNode]
+}
+
+// ParentNodeSequences adds iterator-based traversal below a node.
+// The sequences read the tree as they go and stop when the loop body breaks.
+type ParentNodeSequences interface {
+	// Descendants yields the descendants of the node in tree order.
+	Descendants() iter.Seq[Node]
+	ChildNodesSeq() iter.Seq[ChildNode]
+	ChildrenSeq() iter.Seq[Element]
+}
+
+// CharacterData is the shared interface of Text and Comment. See
+// https://dom.spec.whatwg.org/#interface-characterdata.
+//
+// Offsets, counts, and Length are measured in UTF-16 code units, as in
+// JavaScript. Methods taking an offset return an error wrapping ErrIndexSize
+// when the offset is greater than Length.
+type CharacterData interface {
 	ChildNode
 
 	Data() string
 	SetData(string)
+
+	SubstringData(offset, count int) (string, error)
+	AppendData(data string)
+	InsertData(offset int, data string) error
+	DeleteData(offset, count int) error
+	ReplaceData(offset, count int, data string) error
+}
+
+// Text represents a text node. See https://dom.spec.whatwg.org/#interface-text.
+type Text interface {
+	CharacterData
+	EventTarget
+
+	// SplitText breaks the node in two at offset, inserting the remainder as
+	// the next sibling, and returns the new node.
+	SplitText(offset int) (Text, error)
+
+	// WholeText returns the data of the node and its contiguous Text siblings.
+	WholeText() string
 }
 
 // Document represents a document node. See https://dom.spec.whatwg.org/#interface-document.
 type Document interface {
 	Node
+	Normalizer
+	ParentNodeSequences
+	EventTarget
 
 	ElementQueries
 
 	CreateElement(localName string) Element
 	CreateElementIs(localName, is string) Element
+	// CreateElementNS returns an error wrapping ErrInvalidCharacter or
+	// ErrNamespace when qualifiedName is not valid in namespace.
+	CreateElementNS(namespace, qualifiedName string) (Element, error)
 	CreateTextNode(text string) Text
+	CreateComment(data string) Comment
+	CreateDocumentFragment() DocumentFragment
+	CreateAttribute(localName string) Attr
+
+	// ImportNode returns a copy of node owned by the document. It returns an
+	// error wrapping ErrNotSupported if node is a Document.
+	ImportNode(node Node, deep bool) (Node, error)
+	// AdoptNode removes node from its parent and makes the document its owner.
+	// It returns an error wrapping ErrNotSupported if node is a Document.
+	AdoptNode(node Node) (Node, error)
+
+	// CreateTreeWalker and CreateNodeIterator are based on
+	// https://dom.spec.whatwg.org/#interface-document. filter may be nil.
+	CreateTreeWalker(root Node, whatToShow WhatToShow, filter NodeFilter) TreeWalker
+	CreateNodeIterator(root Node, whatToShow WhatToShow, filter NodeFilter) NodeIterator
+
+	// CreateRange returns a Range with both boundary points at the start of
+	// the document.
+	CreateRange() Range
+
+	Implementation() DOMImplementation
+	Doctype() DocumentType
+	DocumentElement() Element
+	GetElementByID(elementID string) Element
 
 	Head() Element
 	Body() Element
+
+	// Title and SetTitle are based on https://html.spec.whatwg.org/#document.title
+	Title() string
+	SetTitle(title string)
+
+	GetElementsByName(elementName string) NodeList[Element]
+	Forms() ElementCollection
+	Images() ElementCollection
+	Links() ElementCollection
+	Scripts() ElementCollection
+	Embeds() ElementCollection
+}
+
+// DOMImplementation is a subset of https://dom.spec.whatwg.org/#interface-domimplementation.
+type DOMImplementation interface {
+	// CreateDocumentType returns a doctype node owned by the document the
+	// DOMImplementation belongs to. It returns an error wrapping
+	// ErrInvalidCharacter if name is not a valid doctype name.
+	CreateDocumentType(name, publicID, systemID string) (DocumentType, error)
+}
+
+// DocumentType represents a doctype node. See https://dom.spec.whatwg.org/#interface-documenttype.
+type DocumentType interface {
+	ChildNode
+
+	Name() string
+	PublicID() string
+	SystemID() string
 }
 
 // ParentNode combines https://dom.spec.whatwg.org/#interface-parentnode with the
@@ -156,9 +304,13 @@ type ParentNode interface {
 	ChildNodes() NodeList[Node]
 	FirstChild() ChildNode
 	LastChild() ChildNode
-	InsertBefore(node, child ChildNode) ChildNode
-	AppendChild(node ChildNode) ChildNode
-	ReplaceChild(node, child ChildNode) ChildNode
+	ParentNodeSequences
+
+	// InsertBefore, AppendChild, and ReplaceChild accept a DocumentFragment
+	// as node and move its children, leaving it empty.
+	InsertBefore(node Node, child ChildNode) Node
+	AppendChild(node Node) Node
+	ReplaceChild(node Node, child ChildNode) ChildNode
 	RemoveChild(node ChildNode) ChildNode
 }
 
@@ -167,12 +319,16 @@ type ElementQueries interface {
 	Contains(other Node) bool
 
 	GetElementsByTagName(name string) ElementCollection
+	GetElementsByTagNameNS(namespace, localName string) ElementCollection
 	GetElementsByClassName(name string) ElementCollection
 
+	// QuerySelector, QuerySelectorAll, and QuerySelectorSequence match
+	// nothing when query is not a valid selector.
 	QuerySelector(query string) Element
 	QuerySelectorAll(query string) NodeList[Element]
 
 	QuerySelectorIterator
+	CheckedQuerySelector
 }
 
 // Element is based on https://dom.spec.whatwg.org/#interface-element.
@@ -183,10 +339,23 @@ type Element interface {
 	Node
 	ChildNode
 	ParentNode
+	Normalizer
+	EventTarget
 
 	TagName() string
 	ID() string
 	ClassName() string
+	// ClassList returns the tokens of the class attribute.
+	ClassList() DOMTokenList
+	// Dataset returns the data-* attributes.
+	Dataset() DOMStringMap
+	// Style returns the declarations in the style attribute.
+	Style() CSSStyleDeclaration
+
+	// NamespaceURI and Prefix use the empty string where the spec uses null.
+	NamespaceURI() string
+	Prefix() string
+	LocalName() string
 
 	GetAttribute(name string) string
 	SetAttribute(name, value string)
@@ -194,13 +363,51 @@ type Element interface {
 	ToggleAttribute(name string) bool
 	HasAttribute(name string) bool
 
+	GetAttributeNS(namespace, localName string) string
+	// SetAttributeNS returns an error wrapping ErrInvalidCharacter or
+	// ErrNamespace when qualifiedName is not valid in namespace.
+	SetAttributeNS(namespace, qualifiedName, value string) error
+	RemoveAttributeNS(namespace, localName string)
+	HasAttributeNS(namespace, localName string) bool
+
+	Attributes() NamedNodeMap
+	GetAttributeNames() []string
+	HasAttributes() bool
+	GetAttributeNode(name string) Attr
+	// SetAttributeNode returns the replaced attribute, if any. It returns an
+	// error wrapping ErrInUseAttribute if attr belongs to another element.
+	SetAttributeNode(attr Attr) (Attr, error)
+	// RemoveAttributeNode returns an error wrapping ErrNotFound if attr does
+	// not belong to the element.
+	RemoveAttributeNode(attr Attr) (Attr, error)
+
+	// Closest and Matches match nothing when selector is not valid.
+	// ClosestErr and MatchesErr return a *SelectorError instead.
 	Closest(selector string) Element
 	Matches(selector string) bool
-
-	SetInnerHTML(s string)
+	ClosestErr(selector string) (Element, error)
+	MatchesErr(selector string) (bool, error)
+
+	// SetInnerHTML and SetHTMLUnsafe parse s with the element as the
+	// context. SetOuterHTML parses s with the parent as the context and does
+	// nothing when the element has no parent. It returns an error wrapping
+	// ErrNoModificationAllowed when the parent is a Document.
+	SetInnerHTML(s string) error
 	InnerHTML() string
-	SetOuterHTML(s string)
+	SetOuterHTML(s string) error
 	OuterHTML() string
+	SetHTMLUnsafe(s string) error
+	GetHTML(options GetHTMLOptions) string
+
+	// InsertAdjacentHTML, InsertAdjacentElement, and InsertAdjacentText
+	// return an error wrapping ErrSyntax when position is not valid.
+	// InsertBeforeBegin and InsertAfterEnd return an error wrapping
+	// ErrNoModificationAllowed when the element has no parent, or for
+	// InsertAdjacentHTML, when the parent is a Document.
+	InsertAdjacentHTML(position InsertPosition, text string) error
+	// InsertAdjacentElement returns element.
+	InsertAdjacentElement(position InsertPosition, element Element) (Element, error)
+	InsertAdjacentText(position InsertPosition, data string) error
 }
 
 // InnerTextSetter is an optional interface for implementations that support InnerText.
@@ -224,6 +431,7 @@ type ElementCollection interface {
 // DocumentFragment represents a minimal document. See https://dom.spec.whatwg.org/#interface-documentfragment.
 type DocumentFragment interface {
 	Node
+	Normalizer
 
 	Children() ElementCollection
 	FirstElementChild() Element
@@ -234,20 +442,160 @@ type DocumentFragment interface {
 	Prepend(nodes ...Node)
 	ReplaceChildren(nodes ...Node)
 
+	HasChildNodes() bool
+	ChildNodes() NodeList[Node]
+	FirstChild() ChildNode
+	LastChild() ChildNode
+	ParentNodeSequences
+	Contains(other Node) bool
+	InsertBefore(node Node, child ChildNode) Node
+	AppendChild(node Node) Node
+	ReplaceChild(node Node, child ChildNode) ChildNode
+	RemoveChild(node ChildNode) ChildNode
+
+	// QuerySelector, QuerySelectorAll, and QuerySelectorSequence match
+	// nothing when query is not a valid selector.
 	QuerySelector(query string) Element
 	QuerySelectorAll(query string) NodeList[Element]
 	QuerySelectorIterator
+	CheckedQuerySelector
 }
 
-// Comment represents a comment node. See https://dom.spec.whatwg.org/#interface-comment.
-type Comment interface {
+// HTMLTemplateElement is based on https://html.spec.whatwg.org/#the-template-element
+type HTMLTemplateElement interface {
+	Element
+
+	// Content returns the template contents. They are owned by an inert
+	// document and are not matched by queries on the template's ancestors.
+	Content() DocumentFragment
+}
+
+// HTMLAnchorElement is based on https://html.spec.whatwg.org/#the-a-element
+type HTMLAnchorElement interface {
+	Element
+	RelList() DOMTokenList
+}
+
+// HTMLAreaElement is based on https://html.spec.whatwg.org/#the-area-element
+type HTMLAreaElement interface {
+	Element
+	RelList() DOMTokenList
+}
+
+// HTMLLinkElement is based on https://html.spec.whatwg.org/#the-link-element
+type HTMLLinkElement interface {
+	Element
+	RelList() DOMTokenList
+}
+
+// DOMTokenList is the set of space separated tokens in an attribute. It is
+// live: changes to the attribute are visible in the list and changes made
+// through the list update the attribute. See
+// https://dom.spec.whatwg.org/#interface-domtokenlist.
+//
+// Add, Remove, Toggle, ToggleForce, and Replace return an error wrapping
+// ErrSyntax for an empty token or ErrInvalidCharacter for a token containing
+// ASCII whitespace.
+type DOMTokenList interface {
+	Length() int
+	// Item returns the empty string when index is out of range.
+	Item(index int) string
+	Contains(token string) bool
+	Add(tokens ...string) error
+	Remove(tokens ...string) error
+	// Toggle removes token if it is present and adds it otherwise. It
+	// reports whether token is present afterward.
+	Toggle(token string) (bool, error)
+	// ToggleForce adds token when force is true and removes it otherwise. It
+	// reports whether token is present afterward.
+	ToggleForce(token string, force bool) (bool, error)
+	// Replace replaces token with newToken and reports whether token was
+	// present.
+	Replace(token, newToken string) (bool, error)
+	// Value returns the attribute value, which is not normalized until the
+	// list is changed.
+	Value() string
+	SetValue(value string)
+	// All yields the tokens in order.
+	All() iter.Seq[string]
+}
+
+// DOMStringMap is based on https://html.spec.whatwg.org/#domstringmap. It
+// maps camelCase names to the data-* attributes of an element, so "userId"
+// is the data-user-id attribute.
+type DOMStringMap interface {
+	Get(name string) string
+	Has(name string) bool
+	// Set returns an error wrapping ErrSyntax if name contains a hyphen
+	// followed by an ASCII lowercase letter, or ErrInvalidCharacter if the
+	// attribute name is not valid.
+	Set(name, value string) error
+	Delete(name string)
+	// All yields the names and values in attribute order.
+	All() iter.Seq2[string, string]
+}
+
+// CSSStyleDeclaration is based on https://drafts.csswg.org/cssom/#the-cssstyledeclaration-interface.
+// Changes are written back to the style attribute.
+type CSSStyleDeclaration interface {
+	Length() int
+	// Item returns the property name at index, or the empty string when
+	// index is out of range.
+	Item(index int) string
+	GetPropertyValue(property string) string
+	// GetPropertyPriority returns "important" or the empty string.
+	GetPropertyPriority(property string) string
+	// SetProperty removes property when value is empty. It does nothing when
+	// value can not be parsed or priority is not "important" or empty.
+	SetProperty(property, value, priority string)
+	// RemoveProperty returns the removed value.
+	RemoveProperty(property string) string
+	CSSText() string
+	SetCSSText(text string)
+}
+
+// Attr represents an attribute. See https://dom.spec.whatwg.org/#interface-attr.
+type Attr interface {
 	Node
 
-	Data() string
-	SetData(string)
+	Name() string
+	// NamespaceURI and Prefix use the empty string where the spec uses null.
+	NamespaceURI() string
+	Prefix() string
+	LocalName() string
+	Value() string
+	SetValue(value string)
+
+	// OwnerElement returns nil when the attribute is not attached to an element.
+	OwnerElement() Element
+	OwnerDocument() Document
+}
+
+// NamedNodeMap is a live view of an element's attributes. See
+// https://dom.spec.whatwg.org/#interface-namednodemap.
+type NamedNodeMap interface {
+	Length() int
+	Item(index int) Attr
+
+	GetNamedItem(qualifiedName string) Attr
+	SetNamedItem(attr Attr) (Attr, error)
+	RemoveNamedItem(qualifiedName string) (Attr, error)
+}
+
+// Comment represents a comment node. See https://dom.spec.whatwg.org/#interface-comment.
+type Comment interface {
+	CharacterData
 }
 
 // QuerySelectorIterator adds iterator-based query support.
 type QuerySelectorIterator interface {
 	QuerySelectorSequence(query string) iter.Seq[Element]
 }
+
+// CheckedQuerySelector adds query methods that return a *SelectorError when
+// query is not a valid selector, instead of matching nothing.
+type CheckedQuerySelector interface {
+	QuerySelectorErr(query string) (Element, error)
+	QuerySelectorAllErr(query string) (NodeList[Element], error)
+	QuerySelectorSequenceErr(query string) (iter.Seq[Element], error)
+}
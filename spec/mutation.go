@@ -0,0 +1,57 @@
+package spec
+
+// MutationObserverInit is based on https://dom.spec.whatwg.org/#dictdef-mutationobserverinit.
+// AttributeOldValue and a non-nil AttributeFilter imply Attributes, and
+// CharacterDataOldValue implies CharacterData.
+type MutationObserverInit struct {
+	ChildList             bool
+	Attributes            bool
+	CharacterData         bool
+	Subtree               bool
+	AttributeOldValue     bool
+	CharacterDataOldValue bool
+	AttributeFilter       []string
+}
+
+// MutationRecordType is the kind of change described by a MutationRecord.
+type MutationRecordType string
+
+// MutationRecordType values from https://dom.spec.whatwg.org/#dom-mutationrecord-type.
+const (
+	MutationChildList     MutationRecordType = "childList"
+	MutationAttributes    MutationRecordType = "attributes"
+	MutationCharacterData MutationRecordType = "characterData"
+)
+
+// MutationRecord is based on https://dom.spec.whatwg.org/#interface-mutationrecord.
+// Fields the spec sets to null are nil or empty.
+type MutationRecord struct {
+	Type            MutationRecordType
+	Target          Node
+	AddedNodes      []Node
+	RemovedNodes    []Node
+	PreviousSibling Node
+	NextSibling     Node
+
+	AttributeName      string
+	AttributeNamespace string
+	// OldValue is only set when the observer asked for it with
+	// AttributeOldValue or CharacterDataOldValue.
+	OldValue string
+}
+
+// MutationCallback is based on https://dom.spec.whatwg.org/#callbackdef-mutationcallback.
+type MutationCallback func(records []MutationRecord, observer MutationObserver)
+
+// MutationObserver is based on https://dom.spec.whatwg.org/#interface-mutationobserver.
+type MutationObserver interface {
+	// Observe returns an error wrapping ErrType when options do not select
+	// any of ChildList, Attributes, or CharacterData. A false field is
+	// treated like an omitted one, so AttributeOldValue and AttributeFilter
+	// imply Attributes, and CharacterDataOldValue implies CharacterData.
+	// Observing the same target again replaces its options.
+	Observe(target Node, options MutationObserverInit) error
+	Disconnect()
+	// TakeRecords returns the queued records and empties the queue.
+	TakeRecords() []MutationRecord
+}
@@ -0,0 +1,11 @@
+package spec
+
+// Namespaces from https://infra.spec.whatwg.org/#namespaces.
+const (
+	HTMLNamespace   = "http://www.w3.org/1999/xhtml"
+	MathMLNamespace = "http://www.w3.org/1998/Math/MathML"
+	SVGNamespace    = "http://www.w3.org/2000/svg"
+	XLinkNamespace  = "http://www.w3.org/1999/xlink"
+	XMLNamespace    = "http://www.w3.org/XML/1998/namespace"
+	XMLNSNamespace  = "http://www.w3.org/2000/xmlns/"
+)
@@ -0,0 +1,76 @@
+package spec
+
+// AbstractRange is based on https://dom.spec.whatwg.org/#interface-abstractrange.
+// Offsets count children, or UTF-16 code units for CharacterData containers.
+type AbstractRange interface {
+	StartContainer() Node
+	StartOffset() int
+	EndContainer() Node
+	EndOffset() int
+	Collapsed() bool
+}
+
+// StaticRange is based on https://dom.spec.whatwg.org/#interface-staticrange.
+// It is not updated when the tree changes.
+type StaticRange interface {
+	AbstractRange
+}
+
+// StaticRangeInit is based on https://dom.spec.whatwg.org/#dictdef-staticrangeinit
+type StaticRangeInit struct {
+	StartContainer Node
+	StartOffset    int
+	EndContainer   Node
+	EndOffset      int
+}
+
+// HowToCompare selects the boundary points compared by
+// Range.CompareBoundaryPoints.
+type HowToCompare int
+
+// HowToCompare values from https://dom.spec.whatwg.org/#interface-range.
+const (
+	StartToStart HowToCompare = iota
+	StartToEnd
+	EndToEnd
+	EndToStart
+)
+
+// Range is based on https://dom.spec.whatwg.org/#interface-range. A Range is
+// live: its boundary points move when the tree it selects is changed.
+//
+// The methods return errors wrapping the spec errors where the spec throws.
+// Comparisons return -1, 0 or 1.
+type Range interface {
+	AbstractRange
+	CommonAncestorContainer() Node
+
+	SetStart(node Node, offset int) error
+	SetEnd(node Node, offset int) error
+	SetStartBefore(node Node) error
+	SetStartAfter(node Node) error
+	SetEndBefore(node Node) error
+	SetEndAfter(node Node) error
+	Collapse(toStart bool)
+	SelectNode(node Node) error
+	SelectNodeContents(node Node) error
+
+	CompareBoundaryPoints(how HowToCompare, sourceRange Range) (int, error)
+
+	DeleteContents()
+	ExtractContents() (DocumentFragment, error)
+	CloneContents() (DocumentFragment, error)
+	InsertNode(node Node) error
+	SurroundContents(newParent Node) error
+
+	CloneRange() Range
+	// Detach does nothing; it is kept for parity with the spec.
+	Detach()
+
+	IsPointInRange(node Node, offset int) (bool, error)
+	ComparePoint(node Node, offset int) (int, error)
+	IntersectsNode(node Node) bool
+
+	// String returns the text selected by the range.
+	String() string
+}
@@ -0,0 +1,77 @@
+package spec
+
+// WhatToShow is a bitmask of the node types a TreeWalker or NodeIterator
+// visits. See https://dom.spec.whatwg.org/#interface-nodefilter.
+type WhatToShow uint32
+
+// WhatToShow values from https://dom.spec.whatwg.org/#interface-nodefilter.
+const (
+	ShowElement WhatToShow = 1 << iota
+	ShowAttribute
+	ShowText
+	ShowCDATASection
+	ShowEntityReference
+	ShowEntity
+	ShowProcessingInstruction
+	ShowComment
+	ShowDocument
+	ShowDocumentType
+	ShowDocumentFragment
+	ShowNotation
+
+	ShowAll WhatToShow = 0xFFFFFFFF
+)
+
+// Shows reports whether nodeType is included in w.
+func (w WhatToShow) Shows(nodeType NodeType) bool {
+	return nodeType > NodeTypeUnknown && w&(1<<(nodeType-1)) != 0
+}
+
+// FilterResult is returned by a NodeFilter.
+type FilterResult int
+
+// FilterResult values from https://dom.spec.whatwg.org/#interface-nodefilter.
+const (
+	FilterAccept FilterResult = iota + 1
+	// FilterReject skips the node and, for a TreeWalker, its descendants.
+	// A NodeIterator treats it like FilterSkip.
+	FilterReject
+	FilterSkip
+)
+
+// NodeFilter decides whether a TreeWalker or NodeIterator visits node. It is
+// only called for nodes included by WhatToShow. A nil NodeFilter accepts
+// every node.
+type NodeFilter func(node Node) FilterResult
+
+// TreeWalker is based on https://dom.spec.whatwg.org/#interface-treewalker.
+// The movement methods return nil, and leave CurrentNode unchanged, when
+// there is no matching node.
+type TreeWalker interface {
+	Root() Node
+	WhatToShow() WhatToShow
+	CurrentNode() Node
+	SetCurrentNode(node Node)
+
+	ParentNode() Node
+	FirstChild() Node
+	LastChild() Node
+	PreviousSibling() Node
+	NextSibling() Node
+	PreviousNode() Node
+	NextNode() Node
+}
+
+// NodeIterator is based on https://dom.spec.whatwg.org/#interface-nodeiterator.
+// NextNode and PreviousNode return nil at the end of the traversal.
+type NodeIterator interface {
+	Root() Node
+	ReferenceNode() Node
+	PointerBeforeReferenceNode() bool
+	WhatToShow() WhatToShow
+
+	NextNode() Node
+	PreviousNode() Node
+	// Detach does nothing; it is kept for parity with the spec.
+	Detach()
+}
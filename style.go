@@ -0,0 +1,409 @@
+package dom
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// CSSStyleDeclaration is based on https://drafts.csswg.org/cssom/#the-cssstyledeclaration-interface
+//
+// It reads and writes the style attribute of an element. Declarations are
+// parsed with the CSS syntax rules, but property values are not checked
+// against the property grammars and shorthand properties are not expanded.
+// Values are serialized as written with comments removed and whitespace
+// collapsed.
+type CSSStyleDeclaration struct {
+	node *html.Node
+}
+
+type cssDeclaration struct {
+	name      string
+	value     string
+	important bool
+}
+
+func (s *CSSStyleDeclaration) declarations() []cssDeclaration {
+	return parseCSSDeclarations(getAttribute(s.node, "style"))
+}
+
+func (s *CSSStyleDeclaration) Length() int { return len(s.declarations()) }
+
+func (s *CSSStyleDeclaration) Item(index int) string {
+	declarations := s.declarations()
+	if index < 0 || index >= len(declarations) {
+		return ""
+	}
+	return declarations[index].name
+}
+
+func (s *CSSStyleDeclaration) GetPropertyValue(property string) string {
+	if d, ok := findCSSDeclaration(s.declarations(), property); ok {
+		return d.value
+	}
+	return ""
+}
+
+func (s *CSSStyleDeclaration) GetPropertyPriority(property string) string {
+	if d, ok := findCSSDeclaration(s.declarations(), property); ok && d.important {
+		return "important"
+	}
+	return ""
+}
+
+// SetProperty is based on https://drafts.csswg.org/cssom/#dom-cssstyledeclaration-setproperty
+func (s *CSSStyleDeclaration) SetProperty(property, value, priority string) {
+	property = cssPropertyName(property)
+	if !isCSSPropertyName(property) {
+		return
+	}
+	if value == "" {
+		s.RemoveProperty(property)
+		return
+	}
+	if priority != "" && !strings.EqualFold(priority, "important") {
+		return
+	}
+	value, ok := parseCSSValue(value)
+	if !ok {
+		return
+	}
+	declarations := s.declarations()
+	d := cssDeclaration{name: property, value: value, important: priority != ""}
+	if i := indexCSSDeclaration(declarations, property); i >= 0 {
+		declarations[i] = d
+	} else {
+		declarations = append(declarations, d)
+	}
+	s.update(declarations)
+}
+
+// RemoveProperty is based on https://drafts.csswg.org/cssom/#dom-cssstyledeclaration-removeproperty
+func (s *CSSStyleDeclaration) RemoveProperty(property string) string {
+	declarations := s.declarations()
+	i := indexCSSDeclaration(declarations, property)
+	if i < 0 {
+		return ""
+	}
+	value := declarations[i].value
+	s.update(append(declarations[:i], declarations[i+1:]...))
+	return value
+}
+
+// CSSText returns the serialized declarations.
+func (s *CSSStyleDeclaration) CSSText() string { return serializeCSSDeclarations(s.declarations()) }
+
+// SetCSSText replaces the declarations with those parsed from text.
+func (s *CSSStyleDeclaration) SetCSSText(text string) {
+	s.update(parseCSSDeclarations(text))
+}
+
+func (s *CSSStyleDeclaration) update(declarations []cssDeclaration) {
+	setAttribute(s.node, "style", serializeCSSDeclarations(declarations))
+}
+
+func findCSSDeclaration(declarations []cssDeclaration, property string) (cssDeclaration, bool) {
+	if i := indexCSSDeclaration(declarations, property); i >= 0 {
+		return declarations[i], true
+	}
+	return cssDeclaration{}, false
+}
+
+func indexCSSDeclaration(declarations []cssDeclaration, property string) int {
+	property = cssPropertyName(property)
+	for i, d := range declarations {
+		if d.name == property {
+			return i
+		}
+	}
+	return -1
+}
+
+// cssPropertyName lowercases property unless it is a custom property.
+func cssPropertyName(property string) string {
+	if strings.HasPrefix(property, "--") {
+		return property
+	}
+	return strings.ToLower(property)
+}
+
+// isCSSPropertyName reports whether name is a single ident token.
+func isCSSPropertyName(name string) bool {
+	tokens := tokenizeCSS(name)
+	return len(tokens) == 1 && tokens[0].kind == cssIdent
+}
+
+// serializeCSSDeclarations is based on https://drafts.csswg.org/cssom/#serialize-a-css-declaration-block
+func serializeCSSDeclarations(declarations []cssDeclaration) string {
+	var sb strings.Builder
+	for i, d := range declarations {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(d.name)
+		sb.WriteString(": ")
+		sb.WriteString(d.value)
+		if d.important {
+			sb.WriteString(" !important")
+		}
+		sb.WriteByte(';')
+	}
+	return sb.String()
+}
+
+// parseCSSDeclarations is based on https://drafts.csswg.org/css-syntax/#consume-list-of-declarations
+// Invalid declarations are skipped and a later declaration of a property
+// replaces an earlier one.
+func parseCSSDeclarations(text string) []cssDeclaration {
+	var declarations []cssDeclaration
+	tokens := tokenizeCSS(text)
+	for len(tokens) > 0 {
+		end := indexTopLevelCSSToken(tokens, cssSemicolon)
+		if end < 0 {
+			end = len(tokens)
+		}
+		if d, ok := parseCSSDeclaration(tokens[:end]); ok {
+			if i := indexCSSDeclaration(declarations, d.name); i >= 0 {
+				declarations = append(declarations[:i], declarations[i+1:]...)
+			}
+			declarations = append(declarations, d)
+		}
+		tokens = tokens[min(end+1, len(tokens)):]
+	}
+	return declarations
+}
+
+// parseCSSDeclaration is based on https://drafts.csswg.org/css-syntax/#consume-declaration
+func parseCSSDeclaration(tokens []cssToken) (cssDeclaration, bool) {
+	tokens = trimCSSWhitespace(tokens)
+	if len(tokens) < 2 || tokens[0].kind != cssIdent {
+		return cssDeclaration{}, false
+	}
+	d := cssDeclaration{name: cssPropertyName(tokens[0].text)}
+	tokens = trimCSSWhitespace(tokens[1:])
+	if len(tokens) == 0 || tokens[0].kind != cssColon {
+		return cssDeclaration{}, false
+	}
+	tokens = trimCSSWhitespace(tokens[1:])
+	if n := len(tokens); n >= 2 && tokens[n-1].kind == cssIdent && strings.EqualFold(tokens[n-1].text, "important") {
+		if bang := trimCSSWhitespace(tokens[:n-1]); len(bang) > 0 && bang[len(bang)-1].kind == cssDelim && bang[len(bang)-1].text == "!" {
+			d.important = true
+			tokens = trimCSSWhitespace(bang[:len(bang)-1])
+		}
+	}
+	if len(tokens) == 0 || !balancedCSSTokens(tokens) {
+		return cssDeclaration{}, false
+	}
+	d.value = serializeCSSTokens(tokens)
+	return d, true
+}
+
+// parseCSSValue parses a value passed to SetProperty. It reports false if
+// value is not a single declaration value.
+func parseCSSValue(value string) (string, bool) {
+	tokens := trimCSSWhitespace(tokenizeCSS(value))
+	if len(tokens) == 0 || !balancedCSSTokens(tokens) ||
+		indexTopLevelCSSToken(tokens, cssSemicolon) >= 0 {
+		return "", false
+	}
+	for _, t := range tokens {
+		if t.kind == cssDelim && t.text == "!" {
+			return "", false
+		}
+	}
+	return serializeCSSTokens(tokens), true
+}
+
+type cssTokenKind int
+
+const (
+	cssWhitespace cssTokenKind = iota
+	cssIdent
+	cssString
+	cssColon
+	cssSemicolon
+	cssOpen
+	cssClose
+	cssDelim
+	// cssOther is any other run of characters, such as a number, dimension,
+	// hash, or function name with its opening parenthesis separated.
+	cssOther
+)
+
+type cssToken struct {
+	kind cssTokenKind
+	text string
+}
+
+// tokenizeCSS splits input into the tokens needed to find declarations. It
+// is based on https://drafts.csswg.org/css-syntax/#tokenization but keeps the
+// source text of each token instead of its value. Comments are dropped.
+func tokenizeCSS(input string) []cssToken {
+	var tokens []cssToken
+	for i := 0; i < len(input); {
+		c := input[i]
+		switch {
+		case strings.HasPrefix(input[i:], "/*"):
+			end := strings.Index(input[i+2:], "*/")
+			if end < 0 {
+				i = len(input)
+			} else {
+				i += end + 4
+			}
+			tokens = append(tokens, cssToken{kind: cssWhitespace, text: " "})
+		case isCSSWhitespace(c):
+			start := i
+			for i < len(input) && isCSSWhitespace(input[i]) {
+				i++
+			}
+			tokens = append(tokens, cssToken{kind: cssWhitespace, text: input[start:i]})
+		case c == '"' || c == '\'':
+			start := i
+			i++
+			for i < len(input) && input[i] != c && input[i] != '\n' {
+				if input[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i = min(i+1, len(input))
+			tokens = append(tokens, cssToken{kind: cssString, text: input[start:i]})
+		case c == ':':
+			tokens = append(tokens, cssToken{kind: cssColon, text: ":"})
+			i++
+		case c == ';':
+			tokens = append(tokens, cssToken{kind: cssSemicolon, text: ";"})
+			i++
+		case c == '(' || c == '[' || c == '{':
+			tokens = append(tokens, cssToken{kind: cssOpen, text: string(c)})
+			i++
+		case c == ')' || c == ']' || c == '}':
+			tokens = append(tokens, cssToken{kind: cssClose, text: string(c)})
+			i++
+		case c == '!' || c == ',' || c == '/' || c == '*' || c == '>' || c == '<' || c == '=':
+			tokens = append(tokens, cssToken{kind: cssDelim, text: string(c)})
+			i++
+		default:
+			start := i
+			for i < len(input) && !isCSSTokenBoundary(input, i) {
+				if input[i] == '\\' && i+1 < len(input) {
+					i++
+				}
+				_, size := utf8.DecodeRuneInString(input[i:])
+				i += size
+			}
+			kind := cssOther
+			if startsCSSIdent(input[start:i]) {
+				kind = cssIdent
+			}
+			tokens = append(tokens, cssToken{kind: kind, text: input[start:i]})
+		}
+	}
+	return tokens
+}
+
+func isCSSTokenBoundary(input string, i int) bool {
+	switch c := input[i]; c {
+	case '"', '\'', ':', ';', '(', ')', '[', ']', '{', '}', '!', ',', '/', '*', '>', '<', '=':
+		return true
+	default:
+		return isCSSWhitespace(c)
+	}
+}
+
+func isCSSWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// startsCSSIdent is based on https://drafts.csswg.org/css-syntax/#would-start-an-identifier
+func startsCSSIdent(s string) bool {
+	isStart := func(c byte) bool {
+		return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= 0x80
+	}
+	switch {
+	case s == "":
+		return false
+	case s[0] == '-':
+		return len(s) > 1 && (isStart(s[1]) || s[1] == '-' || s[1] == '\\')
+	case s[0] == '\\':
+		return len(s) > 1
+	default:
+		return isStart(s[0])
+	}
+}
+
+func trimCSSWhitespace(tokens []cssToken) []cssToken {
+	for len(tokens) > 0 && tokens[0].kind == cssWhitespace {
+		tokens = tokens[1:]
+	}
+	for len(tokens) > 0 && tokens[len(tokens)-1].kind == cssWhitespace {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens
+}
+
+// indexTopLevelCSSToken returns the index of the first token of kind outside
+// of any block.
+func indexTopLevelCSSToken(tokens []cssToken, kind cssTokenKind) int {
+	depth := 0
+	for i, t := range tokens {
+		switch {
+		case t.kind == cssOpen:
+			depth++
+		case t.kind == cssClose && depth > 0:
+			depth--
+		case t.kind == kind && depth == 0:
+			return i
+		}
+	}
+	return -1
+}
+
+// balancedCSSTokens reports whether every block in tokens is closed by the
+// matching bracket.
+func balancedCSSTokens(tokens []cssToken) bool {
+	var open []byte
+	for _, t := range tokens {
+		switch t.kind {
+		case cssOpen:
+			open = append(open, t.text[0])
+		case cssClose:
+			if len(open) == 0 || closingCSSBracket(open[len(open)-1]) != t.text[0] {
+				return false
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	return len(open) == 0
+}
+
+func closingCSSBracket(open byte) byte {
+	switch open {
+	case '(':
+		return ')'
+	case '[':
+		return ']'
+	default:
+		return '}'
+	}
+}
+
+// serializeCSSTokens joins tokens, collapsing each run of whitespace to a
+// single space.
+func serializeCSSTokens(tokens []cssToken) string {
+	var sb strings.Builder
+	space := false
+	for _, t := range tokens {
+		if t.kind == cssWhitespace {
+			space = true
+			continue
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		sb.WriteString(t.text)
+	}
+	return sb.String()
+}
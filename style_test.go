@@ -0,0 +1,76 @@
+package dom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestElement_Style(t *testing.T) {
+	// language=html
+	const textHTML = `<!DOCTYPE html>
+<html lang="us-en">
+<head><title>Style</title></head>
+<body><div id="box" style="color : red;  MARGIN:0 auto /* center */ ; background: url(&#34;a;b.png&#34;) no-repeat; --Main-Color:  #fff ; width: 10px !IMPORTANT; color: blue"></div><p id="plain"></p></body>
+</html>`
+
+	t.Run("parsing", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#box")
+		style := div.Style()
+		assert.Equal(t, 5, style.Length())
+		assert.Equal(t, "margin", style.Item(0))
+		assert.Equal(t, "color", style.Item(4), "a repeated property moves to the end")
+		assert.Equal(t, "", style.Item(5))
+		assert.Equal(t, "blue", style.GetPropertyValue("color"))
+		assert.Equal(t, "0 auto", style.GetPropertyValue("Margin"))
+		assert.Equal(t, `url("a;b.png") no-repeat`, style.GetPropertyValue("background"))
+		assert.Equal(t, "#fff", style.GetPropertyValue("--Main-Color"))
+		assert.Equal(t, "", style.GetPropertyValue("--main-color"), "custom properties are case-sensitive")
+		assert.Equal(t, "10px", style.GetPropertyValue("width"))
+		assert.Equal(t, "important", style.GetPropertyPriority("width"))
+		assert.Equal(t, "", style.GetPropertyPriority("color"))
+		assert.Equal(t, `margin: 0 auto; background: url("a;b.png") no-repeat; --Main-Color: #fff; width: 10px !important; color: blue;`, style.CSSText())
+	})
+	t.Run("invalid declarations", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#plain")
+		style := div.Style()
+		style.SetCSSText(`color; : red; 1px: 2; width: ; height: 1px); top: 1px; left: calc(1px + (2px); right: 0`)
+		assert.Equal(t, "top: 1px;", style.CSSText(), "an unclosed block extends to the end")
+	})
+	t.Run("SetProperty", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#box")
+		style := div.Style()
+		style.SetProperty("color", "green", "")
+		style.SetProperty("Padding", " 1px  2px ", "important")
+		assert.Equal(t, "green", style.GetPropertyValue("color"))
+		assert.Equal(t, "1px 2px", style.GetPropertyValue("padding"))
+		assert.Equal(t, "important", style.GetPropertyPriority("padding"))
+		assert.Equal(t, `margin: 0 auto; background: url("a;b.png") no-repeat; --Main-Color: #fff; width: 10px !important; color: green; padding: 1px 2px !important;`, div.GetAttribute("style"))
+
+		style.SetProperty("color", "red; display: none", "")
+		style.SetProperty("color", "red !important", "")
+		style.SetProperty("color", "red", "high")
+		style.SetProperty("not a name", "red", "")
+		assert.Equal(t, "green", style.GetPropertyValue("color"))
+		assert.Equal(t, "", style.GetPropertyValue("display"))
+
+		style.SetProperty("width", "", "")
+		assert.Equal(t, "", style.GetPropertyValue("width"))
+	})
+	t.Run("RemoveProperty", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#box")
+		style := div.Style()
+		assert.Equal(t, "0 auto", style.RemoveProperty("margin"))
+		assert.Equal(t, "", style.RemoveProperty("margin"))
+		assert.Equal(t, 4, style.Length())
+	})
+	t.Run("no attribute", func(t *testing.T) {
+		_, p := parseDocument(t, textHTML, "#plain")
+		style := p.Style()
+		assert.Equal(t, 0, style.Length())
+		assert.Equal(t, "", style.CSSText())
+		assert.Equal(t, "", style.RemoveProperty("color"))
+		style.SetProperty("display", "none", "")
+		assert.Equal(t, "display: none;", p.GetAttribute("style"))
+	})
+}
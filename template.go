@@ -0,0 +1,76 @@
+package dom
+
+import (
+	"iter"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/typelate/dom/spec"
+)
+
+// HTMLTemplateElement is based on https://html.spec.whatwg.org/#the-template-element
+//
+// x/net/html parses template contents as children of the template element.
+// They stay there, so rendering round-trips, but outside the template
+// element's own child methods they are treated as the children of Content:
+// they are not connected, belong to an inert document, and are skipped by
+// queries and collections rooted at the template's ancestors.
+type HTMLTemplateElement struct {
+	Element
+}
+
+// Content returns the template contents. The fragment shares its children
+// with the template element, so changes through either are visible in both.
+func (t *HTMLTemplateElement) Content() spec.DocumentFragment {
+	return &DocumentFragment{node: t.node}
+}
+
+func isTemplate(node *html.Node) bool {
+	return node != nil && node.Type == html.ElementNode && node.Namespace == "" && node.DataAtom == atom.Template
+}
+
+// descendants yields the descendants of node in tree order, without
+// descending into the contents of template elements below node.
+func descendants(node *html.Node) iter.Seq[*html.Node] {
+	return func(yield func(*html.Node) bool) {
+		walkDescendants(node, yield)
+	}
+}
+
+func walkDescendants(node *html.Node, yield func(*html.Node) bool) bool {
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if !yield(c) {
+			return false
+		}
+		if !isTemplate(c) && !walkDescendants(c, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	// templateDocuments maps a document to its associated inert template
+	// document.
+	templateDocuments weakMap[*html.Node]
+
+	// inertDocuments records the inert template documents. Their associated
+	// inert template document is themselves.
+	inertDocuments weakMap[struct{}]
+)
+
+// templateContentsOwner is based on https://html.spec.whatwg.org/#appropriate-template-contents-owner-document
+func templateContentsOwner(document *html.Node) *html.Node {
+	if document == nil {
+		return nil
+	}
+	if _, ok := inertDocuments.load(document); ok {
+		return document
+	}
+	return templateDocuments.loadOrStore(document, func() *html.Node {
+		inert := &html.Node{Type: html.DocumentNode}
+		inertDocuments.store(inert, struct{}{})
+		return inert
+	})
+}
@@ -0,0 +1,146 @@
+package dom
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/typelate/dom/spec"
+)
+
+func TestHTMLTemplateElement_Content(t *testing.T) {
+	// language=html
+	textHTML := `<!DOCTYPE html>
+<html lang="us-en">
+<head><title></title></head>
+<body><table><tbody><tr><td>Peach</td></tr></tbody></table><template id="row"><tr id="template-row"><td class="name">Name</td></tr></template></body>
+</html>`
+	document, body := parseDocument(t, textHTML, "body")
+
+	el := document.QuerySelector("template")
+	require.NotNil(t, el)
+	template, ok := el.(spec.HTMLTemplateElement)
+	require.True(t, ok)
+	content := template.Content()
+	require.NotNil(t, content)
+	assert.Equal(t, spec.NodeTypeDocumentFragment, content.NodeType())
+	assert.True(t, content.IsSameNode(template.Content()))
+	assert.False(t, content.IsSameNode(template))
+	assert.False(t, template.IsSameNode(content))
+
+	t.Run("queries skip contents", func(t *testing.T) {
+		assert.Equal(t, 1, document.QuerySelectorAll("tr").Length())
+		assert.Nil(t, document.QuerySelector(".name"))
+		assert.Nil(t, body.QuerySelector("#template-row"))
+		assert.Nil(t, document.GetElementByID("template-row"))
+		assert.Equal(t, 1, document.GetElementsByTagName("td").Length())
+		assert.Equal(t, 0, document.GetElementsByClassName("name").Length())
+		assert.NotContains(t, body.TextContent(), "Name")
+		assert.False(t, body.Contains(content.FirstChild()))
+	})
+	t.Run("template children", func(t *testing.T) {
+		assert.True(t, template.HasChildNodes())
+		assert.Equal(t, 1, template.ChildElementCount())
+		assert.True(t, template.FirstChild().IsSameNode(content.FirstChild()))
+		assert.Equal(t, `<tr id="template-row"><td class="name">Name</td></tr>`, template.InnerHTML())
+	})
+	t.Run("content queries", func(t *testing.T) {
+		row := content.QuerySelector("tr")
+		require.NotNil(t, row)
+		assert.Equal(t, "template-row", row.ID())
+		assert.Equal(t, 1, content.QuerySelectorAll("td").Length())
+		assert.Equal(t, "Name", content.TextContent())
+		assert.Nil(t, row.QuerySelector("td").Closest("template"))
+	})
+	t.Run("content tree", func(t *testing.T) {
+		row := content.FirstElementChild()
+		require.NotNil(t, row)
+		assert.True(t, row.ParentNode().IsSameNode(content))
+		assert.Nil(t, row.ParentElement())
+		assert.True(t, row.GetRootNode().IsSameNode(content))
+		assert.True(t, row.FirstElementChild().GetRootNode().IsSameNode(content))
+		assert.False(t, row.IsConnected())
+
+		owner := row.OwnerDocument()
+		require.NotNil(t, owner)
+		assert.False(t, owner.IsSameNode(document), "contents belong to an inert document")
+		assert.True(t, row.FirstElementChild().OwnerDocument().IsSameNode(owner))
+
+		var ancestors []spec.Node
+		for n := range row.FirstElementChild().Ancestors() {
+			ancestors = append(ancestors, n)
+		}
+		require.Len(t, ancestors, 2, "ancestors stop at the content fragment")
+		assert.True(t, ancestors[0].IsSameNode(row))
+		assert.True(t, ancestors[1].IsSameNode(content))
+
+		var descendants int
+		for range content.Descendants() {
+			descendants++
+		}
+		assert.Equal(t, 3, descendants)
+	})
+	t.Run("clone rows", func(t *testing.T) {
+		tbody := document.QuerySelector("tbody")
+		for range 2 {
+			tbody.Append(content.FirstElementChild().CloneNode(true))
+		}
+		assert.Equal(t, 3, document.QuerySelectorAll("tr").Length())
+		assert.True(t, tbody.LastElementChild().IsConnected())
+		assert.True(t, tbody.LastElementChild().OwnerDocument().IsSameNode(document))
+		assert.Equal(t, 1, content.ChildElementCount())
+	})
+	t.Run("serialization", func(t *testing.T) {
+		assert.Equal(t, `<template id="row"><tr id="template-row"><td class="name">Name</td></tr></template>`, template.OuterHTML())
+		assert.Equal(t, `<tr id="template-row"><td class="name">Name</td></tr>`, content.(*DocumentFragment).String())
+	})
+}
+
+func TestDocument_CreateElement_template(t *testing.T) {
+	// language=html
+	document, _ := parseDocument(t, `<!DOCTYPE html><html><head></head><body></body></html>`, "")
+
+	template, ok := document.CreateElement("template").(spec.HTMLTemplateElement)
+	require.True(t, ok)
+	content := template.Content()
+	content.Append(document.CreateElement("p"))
+	assert.Equal(t, "<template><p></p></template>", template.OuterHTML())
+
+	document.Body().Append(template)
+	p := content.FirstElementChild()
+	assert.False(t, p.IsConnected())
+	assert.True(t, content.IsEqualNode(template.Content()))
+	assert.False(t, content.IsEqualNode(content.CloneNode(false)))
+	assert.True(t, content.IsEqualNode(content.CloneNode(true)))
+
+	t.Run("nested", func(t *testing.T) {
+		inner := document.CreateElement("template").(spec.HTMLTemplateElement)
+		inner.Content().Append(document.CreateElement("span"))
+		content.Append(inner)
+		span := inner.Content().FirstElementChild()
+		assert.True(t, span.OwnerDocument().IsSameNode(p.OwnerDocument()), "nested contents share the inert document")
+		assert.Nil(t, content.QuerySelector("span"))
+	})
+}
+
+func TestHTMLTemplateElement_children(t *testing.T) {
+	// language=html
+	document, _ := parseDocument(t, `<!DOCTYPE html><html><head></head><body></body></html>`, "")
+
+	var template spec.Element = document.CreateElement("template")
+	text := document.CreateTextNode("a")
+	require.NotPanics(t, func() { template.Append(text) })
+	p := document.CreateElement("p")
+	template.AppendChild(p)
+	span := document.CreateElement("span")
+	template.InsertBefore(span, p)
+	assert.Equal(t, "<template>a<span></span><p></p></template>", template.OuterHTML())
+	assert.Equal(t, 3, template.ChildNodes().Length())
+	assert.True(t, text.ParentNode().IsSameNode(template.(spec.HTMLTemplateElement).Content()))
+
+	template.RemoveChild(span)
+	template.ReplaceChildren(p)
+	assert.Equal(t, "<template><p></p></template>", template.OuterHTML())
+	assert.Nil(t, text.ParentNode())
+}
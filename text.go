@@ -1,6 +1,8 @@
 package dom
 
 import (
+	"iter"
+
 	"golang.org/x/net/html"
 
 	"github.com/typelate/dom/spec"
@@ -11,30 +13,72 @@ type Text struct {
 }
 
 func (t *Text) Data() string     { return t.node.Data }
-func (t *Text) SetData(d string) { t.node.Data = d }
+func (t *Text) SetData(d string) { _ = replaceData(t.node, 0, -1, d) }
+
+func (t *Text) SubstringData(offset, count int) (string, error) {
+	return substringData(t.node, offset, count)
+}
+
+func (t *Text) AppendData(data string) { _ = replaceData(t.node, utf16Length(t.node.Data), 0, data) }
+
+func (t *Text) InsertData(offset int, data string) error {
+	return replaceData(t.node, offset, 0, data)
+}
+
+func (t *Text) DeleteData(offset, count int) error { return replaceData(t.node, offset, count, "") }
+
+func (t *Text) ReplaceData(offset, count int, data string) error {
+	return replaceData(t.node, offset, count, data)
+}
+
+func (t *Text) SplitText(offset int) (spec.Text, error) {
+	node, err := splitText(t.node, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &Text{node: node}, nil
+}
+
+func (t *Text) WholeText() string { return wholeText(t.node) }
 
 func (t *Text) NodeType() spec.NodeType         { return nodeType(t.node.Type) }
 func (t *Text) IsConnected() bool               { return isConnected(t.node) }
 func (t *Text) OwnerDocument() spec.Document    { return ownerDocument(t.node) }
-func (t *Text) Length() int                     { return len(t.node.Data) }
+func (t *Text) Length() int                     { return utf16Length(t.node.Data) }
 func (t *Text) ParentNode() spec.Node           { return parentNode(t.node) }
 func (t *Text) ParentElement() spec.Element     { return parentElement(t.node) }
 func (t *Text) PreviousSibling() spec.ChildNode { return previousSibling(t.node) }
 func (t *Text) NextSibling() spec.ChildNode     { return nextSibling(t.node) }
+func (t *Text) Before(nodes ...spec.Node)       { before(t.node, nodes) }
+func (t *Text) After(nodes ...spec.Node)        { after(t.node, nodes) }
+func (t *Text) ReplaceWith(nodes ...spec.Node)  { replaceWith(t.node, nodes) }
+func (t *Text) Remove()                         { remove(t.node) }
 func (t *Text) TextContent() string             { return t.node.Data }
-func (t *Text) CloneNode(_ bool) spec.Node {
-	return &Text{
-		node: &html.Node{
-			Type: html.TextNode,
-			Data: t.node.Data,
-		},
-	}
+func (t *Text) CloneNode(_ bool) spec.Node      { return &Text{node: cloneNode(t.node, false)} }
+
+func (t *Text) Ancestors() iter.Seq[spec.Node]              { return ancestors(t.node) }
+func (t *Text) FollowingSiblings() iter.Seq[spec.ChildNode] { return followingSiblings(t.node) }
+func (t *Text) PrecedingSiblings() iter.Seq[spec.ChildNode] { return precedingSiblings(t.node) }
+
+func (t *Text) AddEventListener(eventType string, listener spec.EventListener, options spec.AddEventListenerOptions) {
+	addEventListener(t.node, eventType, listener, options)
+}
+
+func (t *Text) RemoveEventListener(eventType string, listener spec.EventListener, options spec.EventListenerOptions) {
+	removeEventListener(t.node, eventType, listener, options)
 }
 
+func (t *Text) DispatchEvent(event spec.Event) (bool, error) { return dispatchEvent(t.node, event) }
+
+func (t *Text) LookupNamespaceURI(prefix string) string { return lookupNamespaceURI(t.node, prefix) }
+func (t *Text) LookupPrefix(namespace string) string    { return lookupPrefix(t.node, namespace) }
+
 func (t *Text) CompareDocumentPosition(other spec.Node) spec.DocumentPosition {
 	return compareDocumentPosition(t.node, other)
 }
 
-func (t *Text) IsSameNode(other spec.Node) bool { return isSameNode(t.node, other) }
+func (t *Text) IsSameNode(other spec.Node) bool  { return isSameNode(t.node, other) }
+func (t *Text) IsEqualNode(other spec.Node) bool { return isEqualNode(t.node, other) }
+func (t *Text) GetRootNode() spec.Node           { return getRootNode(t.node) }
 
 func (t *Text) String() string { return t.node.Data }
@@ -102,7 +102,10 @@ func TestText_Length(t *testing.T) {
 		},
 	}
 
-	require.Equal(t, textNode.Length(), len("Hello!"))
+	require.Equal(t, len("Hello!"), textNode.Length())
+
+	textNode.node.Data = "🍑"
+	require.Equal(t, 2, textNode.Length(), "length is in UTF-16 code units")
 }
 
 func TestText_ParentNode(t *testing.T) {
@@ -222,3 +225,76 @@ func TestText_IsSameNode(t *testing.T) {
 		node: p.node.FirstChild,
 	}))
 }
+
+func TestText_SplitText(t *testing.T) {
+	t.Run("attached", func(t *testing.T) {
+		// language=html
+		textHTML := `<!DOCTYPE html>
+<html lang="us-en">
+<head><title></title></head>
+<body><p>Hello, 🍑!<br></p></body>
+</html>`
+		_, p := parseDocument(t, textHTML, "body p")
+		textNode := p.FirstChild().(*Text)
+
+		remainder, err := textNode.SplitText(7)
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, ", textNode.Data())
+		assert.Equal(t, "🍑!", remainder.Data())
+		assert.True(t, textNode.NextSibling().IsSameNode(remainder))
+		assert.Equal(t, "BR", remainder.NextSibling().(spec.Element).TagName())
+		assert.Equal(t, "<p>Hello, 🍑!<br/></p>", p.OuterHTML())
+	})
+	t.Run("detached", func(t *testing.T) {
+		var document *Document
+		textNode := document.CreateTextNode("peach")
+
+		remainder, err := textNode.SplitText(5)
+		require.NoError(t, err)
+		assert.Equal(t, "peach", textNode.Data())
+		assert.Equal(t, "", remainder.Data())
+		assert.Nil(t, remainder.ParentNode())
+	})
+	t.Run("out of range", func(t *testing.T) {
+		var document *Document
+		textNode := document.CreateTextNode("peach")
+
+		remainder, err := textNode.SplitText(6)
+		require.ErrorIs(t, err, spec.ErrIndexSize)
+		assert.Nil(t, remainder)
+		assert.Equal(t, "peach", textNode.Data())
+	})
+}
+
+func TestText_WholeText(t *testing.T) {
+	var document *Document
+	p := document.CreateElement("p")
+	a := document.CreateTextNode("a")
+	b := document.CreateTextNode("b")
+	c := document.CreateTextNode("c")
+	d := document.CreateTextNode("d")
+	p.Append(a, b, document.CreateElement("br"), c, document.CreateComment("x"), d)
+
+	assert.Equal(t, "ab", a.WholeText())
+	assert.Equal(t, "ab", b.WholeText())
+	assert.Equal(t, "c", c.WholeText())
+	assert.Equal(t, "d", d.WholeText())
+}
+
+func TestText_IsEqualNode(t *testing.T) {
+	var document *Document
+	a := document.CreateTextNode("peach")
+
+	assert.True(t, a.IsEqualNode(document.CreateTextNode("peach")))
+	assert.False(t, a.IsEqualNode(document.CreateTextNode("pear")))
+	assert.False(t, a.IsEqualNode(document.CreateComment("peach")))
+}
+
+func TestText_GetRootNode(t *testing.T) {
+	var document *Document
+	p := document.CreateElement("p")
+	text := document.CreateTextNode("peach")
+	assert.True(t, text.GetRootNode().IsSameNode(text))
+	p.Append(text)
+	assert.True(t, text.GetRootNode().IsSameNode(p))
+}
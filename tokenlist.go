@@ -0,0 +1,163 @@
+package dom
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+// DOMTokenList is based on https://dom.spec.whatwg.org/#interface-domtokenlist
+//
+// The tokens are parsed from the attribute on every call, so the list always
+// reflects the current attribute value.
+type DOMTokenList struct {
+	node      *html.Node
+	attribute string
+}
+
+func relList(node *html.Node) spec.DOMTokenList {
+	return &DOMTokenList{node: node, attribute: "rel"}
+}
+
+func classList(node *html.Node) spec.DOMTokenList {
+	return &DOMTokenList{node: node, attribute: "class"}
+}
+
+func (list *DOMTokenList) tokens() []string {
+	return parseOrderedSet(getAttribute(list.node, list.attribute))
+}
+
+func (list *DOMTokenList) Length() int { return len(list.tokens()) }
+
+func (list *DOMTokenList) Item(index int) string {
+	tokens := list.tokens()
+	if index < 0 || index >= len(tokens) {
+		return ""
+	}
+	return tokens[index]
+}
+
+func (list *DOMTokenList) Contains(token string) bool {
+	return slices.Contains(list.tokens(), token)
+}
+
+func (list *DOMTokenList) Add(tokens ...string) error {
+	if err := validateTokens(tokens...); err != nil {
+		return err
+	}
+	set := list.tokens()
+	for _, token := range tokens {
+		if !slices.Contains(set, token) {
+			set = append(set, token)
+		}
+	}
+	list.update(set)
+	return nil
+}
+
+func (list *DOMTokenList) Remove(tokens ...string) error {
+	if err := validateTokens(tokens...); err != nil {
+		return err
+	}
+	set := slices.DeleteFunc(list.tokens(), func(token string) bool {
+		return slices.Contains(tokens, token)
+	})
+	list.update(set)
+	return nil
+}
+
+func (list *DOMTokenList) Toggle(token string) (bool, error) {
+	return list.toggle(token, false, false)
+}
+
+func (list *DOMTokenList) ToggleForce(token string, force bool) (bool, error) {
+	return list.toggle(token, true, force)
+}
+
+// toggle is based on https://dom.spec.whatwg.org/#dom-domtokenlist-toggle
+func (list *DOMTokenList) toggle(token string, hasForce, force bool) (bool, error) {
+	if err := validateTokens(token); err != nil {
+		return false, err
+	}
+	set := list.tokens()
+	if i := slices.Index(set, token); i >= 0 {
+		if hasForce && force {
+			return true, nil
+		}
+		list.update(slices.Delete(set, i, i+1))
+		return false, nil
+	}
+	if hasForce && !force {
+		return false, nil
+	}
+	list.update(append(set, token))
+	return true, nil
+}
+
+// Replace is based on https://dom.spec.whatwg.org/#dom-domtokenlist-replace
+func (list *DOMTokenList) Replace(token, newToken string) (bool, error) {
+	if err := validateTokens(token, newToken); err != nil {
+		return false, err
+	}
+	set := list.tokens()
+	if !slices.Contains(set, token) {
+		return false, nil
+	}
+	var result []string
+	replaced := false
+	for _, t := range set {
+		switch {
+		case t != token && t != newToken:
+			result = append(result, t)
+		case !replaced:
+			result = append(result, newToken)
+			replaced = true
+		}
+	}
+	list.update(result)
+	return true, nil
+}
+
+func (list *DOMTokenList) Value() string { return getAttribute(list.node, list.attribute) }
+
+func (list *DOMTokenList) SetValue(value string) { setAttribute(list.node, list.attribute, value) }
+
+func (list *DOMTokenList) All() iter.Seq[string] { return slices.Values(list.tokens()) }
+
+func (list *DOMTokenList) String() string { return list.Value() }
+
+// update is based on https://dom.spec.whatwg.org/#concept-dtl-update
+func (list *DOMTokenList) update(set []string) {
+	if !hasAttribute(list.node, list.attribute) && len(set) == 0 {
+		return
+	}
+	setAttribute(list.node, list.attribute, strings.Join(set, " "))
+}
+
+// parseOrderedSet is based on https://dom.spec.whatwg.org/#concept-ordered-set-parser
+func parseOrderedSet(input string) []string {
+	var set []string
+	for _, token := range strings.FieldsFunc(input, isASCIIWhitespace) {
+		if !slices.Contains(set, token) {
+			set = append(set, token)
+		}
+	}
+	return set
+}
+
+func validateTokens(tokens ...string) error {
+	for _, token := range tokens {
+		if token == "" {
+			return fmt.Errorf("%w: the token must not be empty", spec.ErrSyntax)
+		}
+		if strings.ContainsFunc(token, isASCIIWhitespace) {
+			return fmt.Errorf("%w: the token %q contains ASCII whitespace", spec.ErrInvalidCharacter, token)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package dom
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/typelate/dom/spec"
+)
+
+func TestElement_ClassList(t *testing.T) {
+	// language=html
+	const textHTML = `<!DOCTYPE html>
+<html lang="us-en">
+<head><title>Class List</title></head>
+<body><div id="target" class="  a b	a c  "></div><p id="empty"></p></body>
+</html>`
+
+	t.Run("parsing", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#target")
+		list := div.ClassList()
+		assert.Equal(t, 3, list.Length())
+		assert.Equal(t, []string{"a", "b", "c"}, slices.Collect(list.All()))
+		assert.Equal(t, "b", list.Item(1))
+		assert.Equal(t, "", list.Item(3))
+		assert.Equal(t, "", list.Item(-1))
+		assert.True(t, list.Contains("c"))
+		assert.False(t, list.Contains("d"))
+		assert.Equal(t, "  a b\ta c  ", list.Value(), "the value is not normalized until the list changes")
+	})
+	t.Run("Add", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#target")
+		list := div.ClassList()
+		require.NoError(t, list.Add("d", "a", "d"))
+		assert.Equal(t, "a b c d", div.ClassName())
+	})
+	t.Run("Remove", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#target")
+		list := div.ClassList()
+		require.NoError(t, list.Remove("a", "x"))
+		assert.Equal(t, "b c", div.ClassName())
+		require.NoError(t, list.Remove("b", "c"))
+		assert.True(t, div.HasAttribute("class"))
+		assert.Equal(t, "", div.ClassName())
+	})
+	t.Run("Toggle", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#target")
+		list := div.ClassList()
+		present, err := list.Toggle("b")
+		require.NoError(t, err)
+		assert.False(t, present)
+		present, err = list.Toggle("b")
+		require.NoError(t, err)
+		assert.True(t, present)
+		assert.Equal(t, "a c b", div.ClassName())
+
+		present, err = list.ToggleForce("a", true)
+		require.NoError(t, err)
+		assert.True(t, present)
+		present, err = list.ToggleForce("z", false)
+		require.NoError(t, err)
+		assert.False(t, present)
+		assert.Equal(t, "a c b", div.ClassName())
+	})
+	t.Run("Replace", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#target")
+		list := div.ClassList()
+		ok, err := list.Replace("x", "y")
+		require.NoError(t, err)
+		assert.False(t, ok)
+		ok, err = list.Replace("b", "a")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "a c", div.ClassName())
+		ok, err = list.Replace("c", "d")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "a d", div.ClassName())
+	})
+	t.Run("no attribute", func(t *testing.T) {
+		_, p := parseDocument(t, textHTML, "#empty")
+		list := p.ClassList()
+		assert.Equal(t, 0, list.Length())
+		require.NoError(t, list.Remove("a"))
+		assert.False(t, p.HasAttribute("class"), "removing from an empty list does not add the attribute")
+		present, err := list.Toggle("a")
+		require.NoError(t, err)
+		assert.True(t, present)
+		assert.Equal(t, "a", p.ClassName())
+	})
+	t.Run("live", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#target")
+		list := div.ClassList()
+		div.SetAttribute("class", "x")
+		assert.Equal(t, 1, list.Length())
+		list.SetValue("y z")
+		assert.Equal(t, "y z", div.ClassName())
+		assert.True(t, div.Matches(".z"))
+	})
+	t.Run("invalid tokens", func(t *testing.T) {
+		_, div := parseDocument(t, textHTML, "#target")
+		list := div.ClassList()
+		assert.ErrorIs(t, list.Add(""), spec.ErrSyntax)
+		assert.ErrorIs(t, list.Add("ok", "a b"), spec.ErrInvalidCharacter)
+		assert.ErrorIs(t, list.Remove("\t"), spec.ErrInvalidCharacter)
+		_, err := list.Toggle("")
+		assert.ErrorIs(t, err, spec.ErrSyntax)
+		_, err = list.Replace("a", "")
+		assert.ErrorIs(t, err, spec.ErrSyntax)
+		assert.False(t, list.Contains("ok"), "nothing is added when a token is invalid")
+		assert.Equal(t, "  a b\ta c  ", list.Value())
+	})
+}
+
+func TestHTMLAnchorElement_RelList(t *testing.T) {
+	// language=html
+	const textHTML = `<!DOCTYPE html>
+<html lang="us-en">
+<head><title>Rel List</title><link rel="stylesheet preload" href="/style.css"></head>
+<body><a href="/" rel="noopener">Home</a><map><area href="/" rel="next"></map><span></span></body>
+</html>`
+	document, _ := parseDocument(t, textHTML, "")
+
+	a, ok := document.QuerySelector("a").(spec.HTMLAnchorElement)
+	require.True(t, ok)
+	require.NoError(t, a.RelList().Add("noreferrer"))
+	assert.Equal(t, "noopener noreferrer", a.GetAttribute("rel"))
+
+	area, ok := document.QuerySelector("area").(spec.HTMLAreaElement)
+	require.True(t, ok)
+	assert.True(t, area.RelList().Contains("next"))
+
+	link, ok := document.QuerySelector("link").(spec.HTMLLinkElement)
+	require.True(t, ok)
+	assert.Equal(t, 2, link.RelList().Length())
+
+	_, ok = document.QuerySelector("span").(spec.HTMLAnchorElement)
+	assert.False(t, ok)
+	assert.True(t, a.IsSameNode(document.Links().Item(0)))
+}
@@ -0,0 +1,383 @@
+package dom
+
+import (
+	"sync"
+
+	"golang.org/x/net/html"
+
+	"github.com/typelate/dom/spec"
+)
+
+func (d *Document) CreateTreeWalker(root spec.Node, whatToShow spec.WhatToShow, filter spec.NodeFilter) spec.TreeWalker {
+	t := newTraversal(root, whatToShow, filter)
+	return &TreeWalker{traversal: t, current: t.rootNode}
+}
+
+func (d *Document) CreateNodeIterator(root spec.Node, whatToShow spec.WhatToShow, filter spec.NodeFilter) spec.NodeIterator {
+	t := newTraversal(root, whatToShow, filter)
+	it := &NodeIterator{traversal: t, reference: t.rootNode, pointerBeforeReference: true}
+	nodeIterators.add(it)
+	return it
+}
+
+// traversal holds the state shared by TreeWalker and NodeIterator. The
+// contents of template elements are only visited when root is the template
+// contents fragment.
+type traversal struct {
+	root       spec.Node
+	rootNode   *html.Node
+	contents   bool
+	whatToShow spec.WhatToShow
+	filter     spec.NodeFilter
+}
+
+func newTraversal(root spec.Node, whatToShow spec.WhatToShow, filter spec.NodeFilter) traversal {
+	_, contents := root.(*DocumentFragment)
+	rootNode := domNodeToHTMLNode(root)
+	if rootNode == nil {
+		// An Attr has no html.Node. A node without a parent or children
+		// stands in for it, so the Attr is the only node traversed.
+		rootNode = &html.Node{}
+	}
+	return traversal{
+		root:       root,
+		rootNode:   rootNode,
+		contents:   contents,
+		whatToShow: whatToShow,
+		filter:     filter,
+	}
+}
+
+func (t *traversal) Root() spec.Node             { return t.root }
+func (t *traversal) WhatToShow() spec.WhatToShow { return t.whatToShow }
+
+// node wraps n, returning the root itself for the root node.
+func (t *traversal) node(n *html.Node) spec.Node {
+	if n == nil {
+		return nil
+	}
+	if n == t.rootNode {
+		return t.root
+	}
+	return NewNode(n)
+}
+
+// filterNode is based on https://dom.spec.whatwg.org/#concept-node-filter
+func (t *traversal) filterNode(n *html.Node) spec.FilterResult {
+	kind := nodeType(n.Type)
+	if n == t.rootNode {
+		kind = t.root.NodeType()
+	}
+	if !t.whatToShow.Shows(kind) {
+		return spec.FilterSkip
+	}
+	if t.filter == nil {
+		return spec.FilterAccept
+	}
+	return t.filter(t.node(n))
+}
+
+func (t *traversal) hidesChildren(n *html.Node) bool {
+	return isTemplate(n) && !(t.contents && n == t.rootNode)
+}
+
+func (t *traversal) firstChild(n *html.Node) *html.Node {
+	if n == nil || t.hidesChildren(n) {
+		return nil
+	}
+	return n.FirstChild
+}
+
+func (t *traversal) lastChild(n *html.Node) *html.Node {
+	if n == nil || t.hidesChildren(n) {
+		return nil
+	}
+	return n.LastChild
+}
+
+func (t *traversal) parent(n *html.Node) *html.Node {
+	if n == nil || n.Parent != nil && t.hidesChildren(n.Parent) {
+		return nil
+	}
+	return n.Parent
+}
+
+// following returns the node after n in tree order within root.
+func (t *traversal) following(n *html.Node) *html.Node {
+	if c := t.firstChild(n); c != nil {
+		return c
+	}
+	return t.followingSkippingChildren(n)
+}
+
+// followingSkippingChildren returns the node after n and its descendants in
+// tree order within root.
+func (t *traversal) followingSkippingChildren(n *html.Node) *html.Node {
+	for ; n != nil && n != t.rootNode; n = t.parent(n) {
+		if n.NextSibling != nil {
+			return n.NextSibling
+		}
+	}
+	return nil
+}
+
+// preceding returns the node before n in tree order within root.
+func (t *traversal) preceding(n *html.Node) *html.Node {
+	if n == nil || n == t.rootNode {
+		return nil
+	}
+	if n.PrevSibling == nil {
+		return t.parent(n)
+	}
+	n = n.PrevSibling
+	for c := t.lastChild(n); c != nil; c = t.lastChild(n) {
+		n = c
+	}
+	return n
+}
+
+// TreeWalker is based on https://dom.spec.whatwg.org/#interface-treewalker
+type TreeWalker struct {
+	traversal
+	current *html.Node
+}
+
+func (w *TreeWalker) CurrentNode() spec.Node { return w.node(w.current) }
+
+func (w *TreeWalker) SetCurrentNode(node spec.Node) {
+	if node == w.root {
+		w.current = w.rootNode
+		return
+	}
+	w.current = domNodeToHTMLNode(node)
+}
+
+func (w *TreeWalker) accept(n *html.Node) spec.Node {
+	w.current = n
+	return w.node(n)
+}
+
+// ParentNode is based on https://dom.spec.whatwg.org/#dom-treewalker-parentnode
+func (w *TreeWalker) ParentNode() spec.Node {
+	for n := w.current; n != nil && n != w.rootNode; {
+		n = w.parent(n)
+		if n != nil && w.filterNode(n) == spec.FilterAccept {
+			return w.accept(n)
+		}
+	}
+	return nil
+}
+
+func (w *TreeWalker) FirstChild() spec.Node { return w.traverseChildren(true) }
+func (w *TreeWalker) LastChild() spec.Node  { return w.traverseChildren(false) }
+
+// traverseChildren is based on https://dom.spec.whatwg.org/#concept-traverse-children
+func (w *TreeWalker) traverseChildren(first bool) spec.Node {
+	child, sibling := w.firstChild, func(n *html.Node) *html.Node { return n.NextSibling }
+	if !first {
+		child, sibling = w.lastChild, func(n *html.Node) *html.Node { return n.PrevSibling }
+	}
+	n := child(w.current)
+	for n != nil {
+		switch w.filterNode(n) {
+		case spec.FilterAccept:
+			return w.accept(n)
+		case spec.FilterSkip:
+			if c := child(n); c != nil {
+				n = c
+				continue
+			}
+		}
+		for n != nil {
+			if s := sibling(n); s != nil {
+				n = s
+				break
+			}
+			parent := w.parent(n)
+			if parent == nil || parent == w.rootNode || parent == w.current {
+				return nil
+			}
+			n = parent
+		}
+	}
+	return nil
+}
+
+func (w *TreeWalker) NextSibling() spec.Node     { return w.traverseSiblings(true) }
+func (w *TreeWalker) PreviousSibling() spec.Node { return w.traverseSiblings(false) }
+
+// traverseSiblings is based on https://dom.spec.whatwg.org/#concept-traverse-siblings
+func (w *TreeWalker) traverseSiblings(next bool) spec.Node {
+	child, sibling := w.firstChild, func(n *html.Node) *html.Node { return n.NextSibling }
+	if !next {
+		child, sibling = w.lastChild, func(n *html.Node) *html.Node { return n.PrevSibling }
+	}
+	n := w.current
+	if n == nil || n == w.rootNode {
+		return nil
+	}
+	for {
+		s := sibling(n)
+		for s != nil {
+			n = s
+			result := w.filterNode(n)
+			if result == spec.FilterAccept {
+				return w.accept(n)
+			}
+			s = child(n)
+			if result == spec.FilterReject || s == nil {
+				s = sibling(n)
+			}
+		}
+		n = w.parent(n)
+		if n == nil || n == w.rootNode || w.filterNode(n) == spec.FilterAccept {
+			return nil
+		}
+	}
+}
+
+// PreviousNode is based on https://dom.spec.whatwg.org/#dom-treewalker-previousnode
+func (w *TreeWalker) PreviousNode() spec.Node {
+	n := w.current
+	for n != nil && n != w.rootNode {
+		for s := n.PrevSibling; s != nil; s = n.PrevSibling {
+			n = s
+			result := w.filterNode(n)
+			for result != spec.FilterReject && w.lastChild(n) != nil {
+				n = w.lastChild(n)
+				result = w.filterNode(n)
+			}
+			if result == spec.FilterAccept {
+				return w.accept(n)
+			}
+		}
+		if n == w.rootNode {
+			return nil
+		}
+		n = w.parent(n)
+		if n != nil && w.filterNode(n) == spec.FilterAccept {
+			return w.accept(n)
+		}
+	}
+	return nil
+}
+
+// NextNode is based on https://dom.spec.whatwg.org/#dom-treewalker-nextnode
+func (w *TreeWalker) NextNode() spec.Node {
+	n := w.current
+	result := spec.FilterAccept
+	for n != nil {
+		for result != spec.FilterReject && w.firstChild(n) != nil {
+			n = w.firstChild(n)
+			result = w.filterNode(n)
+			if result == spec.FilterAccept {
+				return w.accept(n)
+			}
+		}
+		n = w.followingSkippingChildren(n)
+		if n == nil {
+			return nil
+		}
+		result = w.filterNode(n)
+		if result == spec.FilterAccept {
+			return w.accept(n)
+		}
+	}
+	return nil
+}
+
+// NodeIterator is based on https://dom.spec.whatwg.org/#interface-nodeiterator
+type NodeIterator struct {
+	traversal
+
+	mu                     sync.Mutex
+	reference              *html.Node
+	pointerBeforeReference bool
+}
+
+func (it *NodeIterator) ReferenceNode() spec.Node {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return it.node(it.reference)
+}
+
+func (it *NodeIterator) PointerBeforeReferenceNode() bool {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return it.pointerBeforeReference
+}
+
+func (it *NodeIterator) NextNode() spec.Node     { return it.traverse(true) }
+func (it *NodeIterator) PreviousNode() spec.Node { return it.traverse(false) }
+
+// Detach is based on https://dom.spec.whatwg.org/#dom-nodeiterator-detach
+func (it *NodeIterator) Detach() {}
+
+// traverse is based on https://dom.spec.whatwg.org/#concept-nodeiterator-traverse
+// The filter is called without holding the lock, so it may change the tree.
+func (it *NodeIterator) traverse(next bool) spec.Node {
+	it.mu.Lock()
+	n, beforeNode := it.reference, it.pointerBeforeReference
+	it.mu.Unlock()
+	for {
+		switch {
+		case next && !beforeNode:
+			n = it.following(n)
+		case next:
+			beforeNode = false
+		case beforeNode:
+			n = it.preceding(n)
+		default:
+			beforeNode = true
+		}
+		if n == nil {
+			return nil
+		}
+		if it.filterNode(n) == spec.FilterAccept {
+			break
+		}
+	}
+	it.mu.Lock()
+	it.reference, it.pointerBeforeReference = n, beforeNode
+	it.mu.Unlock()
+	return it.node(n)
+}
+
+// preRemove is based on https://dom.spec.whatwg.org/#nodeiterator-pre-removing-steps
+// Checking that toBeRemoved is in root first keeps a removal from another
+// tree from reading the nodes of this one.
+func (it *NodeIterator) preRemove(toBeRemoved *html.Node) {
+	if toBeRemoved == it.rootNode || !isInclusiveAncestor(it.rootNode, toBeRemoved) {
+		return
+	}
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if !isInclusiveAncestor(toBeRemoved, it.reference) {
+		return
+	}
+	if it.pointerBeforeReference {
+		if next := it.followingSkippingChildren(toBeRemoved); next != nil {
+			it.reference = next
+			return
+		}
+		it.pointerBeforeReference = false
+	}
+	if toBeRemoved.PrevSibling == nil {
+		it.reference = toBeRemoved.Parent
+		return
+	}
+	n := toBeRemoved.PrevSibling
+	for c := it.lastChild(n); c != nil; c = it.lastChild(n) {
+		n = c
+	}
+	it.reference = n
+}
+
+func isInclusiveAncestor(ancestor, node *html.Node) bool {
+	for n := node; n != nil; n = n.Parent {
+		if n == ancestor {
+			return true
+		}
+	}
+	return false
+}
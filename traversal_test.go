@@ -0,0 +1,233 @@
+package dom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/typelate/dom/spec"
+)
+
+func TestDocument_CreateTreeWalker(t *testing.T) {
+	// language=html
+	const textHTML = `<!DOCTYPE html>
+<html lang="us-en">
+<head><title>Walker</title></head>
+<body><main id="root"><h1>Title</h1><script>var x = "hidden";</script><p>Hello, <em>world</em>!</p><template><p>template</p></template><!-- note --></main></body>
+</html>`
+
+	names := func(nodes ...spec.Node) []string {
+		var names []string
+		for _, n := range nodes {
+			names = append(names, nodeTagName(n))
+		}
+		return names
+	}
+
+	t.Run("text extraction skips script subtrees", func(t *testing.T) {
+		document, main := parseDocument(t, textHTML, "#root")
+		walker := document.CreateTreeWalker(main, spec.ShowElement|spec.ShowText, func(node spec.Node) spec.FilterResult {
+			if el, ok := node.(spec.Element); ok && el.TagName() == "SCRIPT" {
+				return spec.FilterReject
+			}
+			if node.NodeType() == spec.NodeTypeText {
+				return spec.FilterAccept
+			}
+			return spec.FilterSkip
+		})
+		var sb strings.Builder
+		for n := walker.NextNode(); n != nil; n = walker.NextNode() {
+			sb.WriteString(n.TextContent())
+		}
+		assert.Equal(t, "TitleHello, world!", sb.String())
+		assert.Equal(t, "!", walker.CurrentNode().TextContent())
+	})
+	t.Run("elements", func(t *testing.T) {
+		document, main := parseDocument(t, textHTML, "#root")
+		walker := document.CreateTreeWalker(main, spec.ShowElement, nil)
+		assert.True(t, walker.Root().IsSameNode(main))
+		assert.Equal(t, spec.ShowElement, walker.WhatToShow())
+		var visited []spec.Node
+		for n := walker.NextNode(); n != nil; n = walker.NextNode() {
+			visited = append(visited, n)
+		}
+		assert.Equal(t, []string{"H1", "SCRIPT", "P", "EM", "TEMPLATE"}, names(visited...), "template contents are not visited")
+
+		var reversed []spec.Node
+		for n := walker.PreviousNode(); n != nil; n = walker.PreviousNode() {
+			reversed = append(reversed, n)
+		}
+		assert.Equal(t, []string{"EM", "P", "SCRIPT", "H1", "MAIN"}, names(reversed...))
+		assert.True(t, walker.CurrentNode().IsSameNode(main))
+	})
+	t.Run("movement", func(t *testing.T) {
+		document, main := parseDocument(t, textHTML, "#root")
+		walker := document.CreateTreeWalker(main, spec.ShowElement, nil)
+		assert.Nil(t, walker.ParentNode(), "the walker does not leave root")
+		assert.Nil(t, walker.NextSibling())
+		assert.Equal(t, "H1", nodeTagName(walker.FirstChild()))
+		assert.Nil(t, walker.PreviousSibling())
+		assert.Nil(t, walker.FirstChild(), "text children are not shown")
+		assert.Equal(t, "SCRIPT", nodeTagName(walker.NextSibling()))
+		assert.Equal(t, "P", nodeTagName(walker.NextSibling()))
+		assert.Equal(t, "EM", nodeTagName(walker.LastChild()))
+		assert.Equal(t, "P", nodeTagName(walker.ParentNode()))
+		assert.Equal(t, "TEMPLATE", nodeTagName(walker.NextSibling()))
+		assert.Nil(t, walker.NextSibling())
+		assert.Equal(t, "MAIN", nodeTagName(walker.ParentNode()))
+		assert.Equal(t, "TEMPLATE", nodeTagName(walker.LastChild()))
+		assert.Nil(t, walker.FirstChild(), "template contents are not children")
+
+		walker.SetCurrentNode(document.Body())
+		assert.Equal(t, "HTML", nodeTagName(walker.ParentNode()), "the current node may be outside root")
+	})
+	t.Run("skip visits children", func(t *testing.T) {
+		document, main := parseDocument(t, textHTML, "#root")
+		walker := document.CreateTreeWalker(main, spec.ShowElement, func(node spec.Node) spec.FilterResult {
+			if nodeTagName(node) == "P" {
+				return spec.FilterSkip
+			}
+			return spec.FilterAccept
+		})
+		walker.FirstChild()
+		assert.Equal(t, "SCRIPT", nodeTagName(walker.NextSibling()))
+		assert.Equal(t, "EM", nodeTagName(walker.NextSibling()), "children of a skipped sibling are siblings")
+		assert.Equal(t, "MAIN", nodeTagName(walker.ParentNode()))
+	})
+	t.Run("template contents", func(t *testing.T) {
+		document, main := parseDocument(t, textHTML, "#root")
+		template := main.QuerySelector("template").(spec.HTMLTemplateElement)
+		walker := document.CreateTreeWalker(template.Content(), spec.ShowAll, nil)
+		assert.Equal(t, "P", nodeTagName(walker.NextNode()))
+		assert.Equal(t, "template", walker.NextNode().TextContent())
+		assert.Nil(t, walker.NextNode())
+		assert.Equal(t, "P", nodeTagName(walker.ParentNode()))
+		assert.Equal(t, spec.NodeTypeDocumentFragment, walker.ParentNode().NodeType())
+	})
+}
+
+func TestDocument_CreateNodeIterator(t *testing.T) {
+	// language=html
+	const textHTML = `<!DOCTYPE html>
+<html lang="us-en">
+<head><title>Iterator</title></head>
+<body><ul id="list"><li id="a">A</li><li id="b">B</li><li id="c">C</li></ul></body>
+</html>`
+
+	t.Run("traversal", func(t *testing.T) {
+		document, ul := parseDocument(t, textHTML, "#list")
+		iterator := document.CreateNodeIterator(ul, spec.ShowElement, func(node spec.Node) spec.FilterResult {
+			if node.(spec.Element).ID() == "b" {
+				return spec.FilterReject
+			}
+			return spec.FilterAccept
+		})
+		assert.True(t, iterator.Root().IsSameNode(ul))
+		assert.True(t, iterator.ReferenceNode().IsSameNode(ul))
+		assert.True(t, iterator.PointerBeforeReferenceNode())
+
+		assert.True(t, iterator.NextNode().IsSameNode(ul))
+		assert.Equal(t, "a", iterator.NextNode().(spec.Element).ID())
+		assert.Equal(t, "c", iterator.NextNode().(spec.Element).ID())
+		assert.Nil(t, iterator.NextNode())
+		assert.Equal(t, "c", iterator.ReferenceNode().(spec.Element).ID())
+		assert.False(t, iterator.PointerBeforeReferenceNode())
+
+		assert.Equal(t, "c", iterator.PreviousNode().(spec.Element).ID())
+		assert.True(t, iterator.PointerBeforeReferenceNode())
+		assert.Equal(t, "a", iterator.PreviousNode().(spec.Element).ID())
+		iterator.Detach()
+		assert.True(t, iterator.PreviousNode().IsSameNode(ul))
+		assert.Nil(t, iterator.PreviousNode())
+	})
+	t.Run("removing the reference node", func(t *testing.T) {
+		document, ul := parseDocument(t, textHTML, "#list")
+		iterator := document.CreateNodeIterator(ul, spec.ShowElement, nil)
+		iterator.NextNode()
+		iterator.NextNode()
+		b := iterator.NextNode()
+		require.Equal(t, "b", b.(spec.Element).ID())
+
+		b.(spec.ChildNode).Remove()
+		assert.Equal(t, "A", iterator.ReferenceNode().TextContent(), "the reference moves to the last descendant of the previous sibling")
+		assert.False(t, iterator.PointerBeforeReferenceNode())
+		assert.Equal(t, "c", iterator.NextNode().(spec.Element).ID())
+	})
+	t.Run("removing the reference node with the pointer before it", func(t *testing.T) {
+		document, ul := parseDocument(t, textHTML, "#list")
+		iterator := document.CreateNodeIterator(ul, spec.ShowElement, nil)
+		iterator.NextNode()
+		iterator.NextNode()
+		b := iterator.NextNode()
+		require.Equal(t, "b", iterator.PreviousNode().(spec.Element).ID())
+
+		b.(spec.ChildNode).Remove()
+		assert.Equal(t, "c", iterator.ReferenceNode().(spec.Element).ID(), "the reference moves to the following node")
+		assert.True(t, iterator.PointerBeforeReferenceNode())
+
+		iterator.ReferenceNode().(spec.ChildNode).Remove()
+		assert.Equal(t, "A", iterator.ReferenceNode().TextContent(), "without a following node the reference moves back")
+		assert.False(t, iterator.PointerBeforeReferenceNode())
+		assert.Nil(t, iterator.NextNode())
+	})
+	t.Run("removing an ancestor of the reference node", func(t *testing.T) {
+		document, ul := parseDocument(t, textHTML, "#list")
+		iterator := document.CreateNodeIterator(ul, spec.ShowText, nil)
+		for n := iterator.NextNode(); n != nil && n.TextContent() != "C"; n = iterator.NextNode() {
+		}
+		require.Equal(t, "C", iterator.ReferenceNode().TextContent())
+
+		ul.FirstElementChild().Remove()
+		assert.Equal(t, "C", iterator.ReferenceNode().TextContent(), "unrelated removals do not change the reference")
+		ul.LastElementChild().Remove()
+		assert.Equal(t, "B", iterator.ReferenceNode().TextContent())
+		ul.Remove()
+		assert.Equal(t, "B", iterator.ReferenceNode().TextContent(), "removing root does not change the reference")
+	})
+	t.Run("removal after adopt", func(t *testing.T) {
+		document, ul := parseDocument(t, textHTML, "#list")
+		ul.Remove()
+		iterator := document.CreateNodeIterator(ul, spec.ShowElement, nil)
+		iterator.NextNode()
+		iterator.NextNode()
+		b := iterator.NextNode()
+		require.Equal(t, "b", b.(spec.Element).ID())
+
+		other, _ := parseDocument(t, textHTML, "")
+		_, err := other.AdoptNode(ul)
+		require.NoError(t, err)
+		b.(spec.ChildNode).Remove()
+		assert.Equal(t, "A", iterator.ReferenceNode().TextContent(), "the iterator follows its root to the other document")
+	})
+	t.Run("attribute root", func(t *testing.T) {
+		document, ul := parseDocument(t, textHTML, "#list")
+		attr := ul.GetAttributeNode("id")
+		iterator := document.CreateNodeIterator(attr, spec.ShowAll, nil)
+		assert.True(t, iterator.ReferenceNode().IsSameNode(attr))
+		assert.True(t, iterator.NextNode().IsSameNode(attr))
+		assert.Nil(t, iterator.NextNode())
+		assert.True(t, iterator.PreviousNode().IsSameNode(attr))
+		assert.Nil(t, iterator.PreviousNode())
+
+		iterator = document.CreateNodeIterator(attr, spec.ShowElement, nil)
+		assert.Nil(t, iterator.NextNode(), "whatToShow applies to the attribute")
+
+		walker := document.CreateTreeWalker(attr, spec.ShowAll, nil)
+		assert.True(t, walker.CurrentNode().IsSameNode(attr))
+		assert.Nil(t, walker.NextNode())
+		assert.Nil(t, walker.FirstChild())
+		walker.SetCurrentNode(ul)
+		walker.SetCurrentNode(attr)
+		assert.True(t, walker.CurrentNode().IsSameNode(attr))
+	})
+}
+
+// nodeTagName returns the tag name of an element node and "" for other nodes.
+func nodeTagName(node spec.Node) string {
+	if el, ok := node.(spec.Element); ok {
+		return el.TagName()
+	}
+	return ""
+}